@@ -0,0 +1,107 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreateKey(ctx context.Context, key *APIKey) error
+	GetKey(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	GetKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	ListKeys(ctx context.Context) ([]APIKey, error)
+	RevokeKey(ctx context.Context, id uuid.UUID, at time.Time) (*APIKey, error)
+	// RotateKey stores replacement and lets the key it replaces expire at overlapUntil.
+	RotateKey(ctx context.Context, id uuid.UUID, replacement *APIKey, overlapUntil time.Time) (*APIKey, error)
+	TouchKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateKey(ctx context.Context, key *APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *repository) GetKey(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).First(&key, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *repository) GetKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	var key APIKey
+	if err := r.db.WithContext(ctx).First(&key, "prefix = ?", prefix).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *repository) ListKeys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *repository) RevokeKey(ctx context.Context, id uuid.UUID, at time.Time) (*APIKey, error) {
+	var key APIKey
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&key, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if !key.Active(at) {
+			return ErrKeyInactive
+		}
+		key.RevokedAt = &at
+		return tx.Model(&key).Update("revoked_at", at).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *repository) RotateKey(ctx context.Context, id uuid.UUID, replacement *APIKey, overlapUntil time.Time) (*APIKey, error) {
+	var key APIKey
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&key, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if !key.Active(time.Now().UTC()) {
+			return ErrKeyInactive
+		}
+
+		replacement.Name = key.Name
+		replacement.Permissions = key.Permissions
+		replacement.AccountIDs = key.AccountIDs
+		replacement.ExpiresAt = key.ExpiresAt
+		replacement.RotatedFromID = &key.ID
+		if err := tx.Create(replacement).Error; err != nil {
+			return err
+		}
+
+		if key.ExpiresAt == nil || overlapUntil.Before(*key.ExpiresAt) {
+			key.ExpiresAt = &overlapUntil
+		}
+		return tx.Model(&key).Update("expires_at", key.ExpiresAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *repository) TouchKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
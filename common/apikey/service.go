@@ -0,0 +1,180 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
+)
+
+const (
+	// cacheTTL bounds how long a revoked key keeps working on other services.
+	cacheTTL = 30 * time.Second
+	// touchInterval limits how often last use is written per key.
+	touchInterval = time.Minute
+)
+
+// Service manages API keys and authenticates requests presenting them.
+type Service interface {
+	server.APIKeyAuthenticator
+	// CreateKey stores key and returns the plaintext key, which is not retrievable afterwards.
+	CreateKey(ctx context.Context, key *APIKey) (string, error)
+	GetKey(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	ListKeys(ctx context.Context) ([]APIKey, error)
+	RevokeKey(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	// RotateKey issues a replacement for the key; both keys work until overlap has passed.
+	RotateKey(ctx context.Context, id uuid.UUID, overlap time.Duration, createdBy string) (*APIKey, string, error)
+}
+
+type cachedKey struct {
+	key       *APIKey
+	loadedAt  time.Time
+	touchedAt time.Time
+}
+
+type service struct {
+	repo  Repository
+	mu    sync.Mutex
+	cache map[string]*cachedKey
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo:  repo,
+		cache: make(map[string]*cachedKey),
+	}
+}
+
+func (s *service) CreateKey(ctx context.Context, key *APIKey) (string, error) {
+	if err := key.Validate(); err != nil {
+		return "", err
+	}
+	plaintext, err := s.assign(key)
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		return "", err
+	}
+	logger.Ctx(ctx).Info().Str("apiKeyId", key.ID.String()).Str("prefix", key.Prefix).Str("createdBy", key.CreatedBy).Msg("API key created")
+	return plaintext, nil
+}
+
+func (s *service) GetKey(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	return s.repo.GetKey(ctx, id)
+}
+
+func (s *service) ListKeys(ctx context.Context) ([]APIKey, error) {
+	return s.repo.ListKeys(ctx)
+}
+
+func (s *service) RevokeKey(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	key, err := s.repo.RevokeKey(ctx, id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	s.evict(key.Prefix)
+	logger.Ctx(ctx).Info().Str("apiKeyId", key.ID.String()).Str("prefix", key.Prefix).Msg("API key revoked")
+	return key, nil
+}
+
+func (s *service) RotateKey(ctx context.Context, id uuid.UUID, overlap time.Duration, createdBy string) (*APIKey, string, error) {
+	replacement := &APIKey{CreatedBy: createdBy}
+	plaintext, err := s.assign(replacement)
+	if err != nil {
+		return nil, "", err
+	}
+	old, err := s.repo.RotateKey(ctx, id, replacement, time.Now().UTC().Add(overlap))
+	if err != nil {
+		return nil, "", err
+	}
+	s.evict(old.Prefix)
+	logger.Ctx(ctx).Info().Str("apiKeyId", old.ID.String()).Str("replacementId", replacement.ID.String()).Time("expiresAt", *old.ExpiresAt).Msg("API key rotated")
+	return replacement, plaintext, nil
+}
+
+func (s *service) Authenticate(ctx context.Context, presented string) (*server.Claims, error) {
+	prefix, secret, err := parseKey(presented)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.lookup(ctx, prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(entry.key.KeyHash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+	now := time.Now().UTC()
+	if !entry.key.Active(now) {
+		return nil, ErrKeyInactive
+	}
+
+	s.touch(ctx, entry, now)
+	return entry.key.Claims(), nil
+}
+
+// assign gives key a new ID, prefix and secret and returns the plaintext key.
+func (s *service) assign(key *APIKey) (string, error) {
+	plaintext, prefix, hash, err := generateKey()
+	if err != nil {
+		return "", err
+	}
+	key.ID = uuid.New()
+	key.Prefix = prefix
+	key.KeyHash = hash
+	return plaintext, nil
+}
+
+func (s *service) lookup(ctx context.Context, prefix string) (*cachedKey, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[prefix]
+	s.mu.Unlock()
+	if ok && time.Since(entry.loadedAt) < cacheTTL {
+		return entry, nil
+	}
+
+	key, err := s.repo.GetKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry = &cachedKey{key: key, loadedAt: time.Now()}
+	if previous, ok := s.cache[prefix]; ok {
+		entry.touchedAt = previous.touchedAt
+	}
+	s.cache[prefix] = entry
+	return entry, nil
+}
+
+func (s *service) touch(ctx context.Context, entry *cachedKey, now time.Time) {
+	s.mu.Lock()
+	due := now.Sub(entry.touchedAt) >= touchInterval
+	if due {
+		entry.touchedAt = now
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+	if err := s.repo.TouchKey(ctx, entry.key.ID, now); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("prefix", entry.key.Prefix).Msg("Failed to record API key use")
+	}
+}
+
+func (s *service) evict(prefix string) {
+	s.mu.Lock()
+	delete(s.cache, prefix)
+	s.mu.Unlock()
+}
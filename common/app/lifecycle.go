@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+type stopHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle runs a service's background workers and shuts them down in order on SIGINT or
+// SIGTERM: workers are cancelled and waited for, then stop hooks run in reverse registration
+// order, all within the shutdown timeout.
+type Lifecycle struct {
+	name    string
+	timeout time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu    sync.Mutex
+	hooks []stopHook
+	err   error
+}
+
+// NewLifecycle starts listening for shutdown signals.
+func NewLifecycle(name string, timeout time.Duration) *Lifecycle {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	return &Lifecycle{name: name, timeout: timeout, ctx: ctx, cancel: cancel}
+}
+
+// Context is cancelled when shutdown starts. Workers stop taking new work when it is done but
+// should finish what they already started.
+func (l *Lifecycle) Context() context.Context {
+	return l.ctx
+}
+
+// Go runs a worker until it returns. A worker that fails shuts the service down.
+func (l *Lifecycle) Go(name string, run func(ctx context.Context) error) {
+	l.workers.Add(1)
+	go func() {
+		defer l.workers.Done()
+		logger.Log.Info().Str("worker", name).Msgf("Starting %s", name)
+		if err := run(l.ctx); err != nil && l.ctx.Err() == nil {
+			logger.Log.Error().Err(err).Str("worker", name).Msgf("%s failed, shutting down", name)
+			l.fail(fmt.Errorf("%s: %w", name, err))
+			l.cancel()
+			return
+		}
+		logger.Log.Info().Str("worker", name).Msgf("%s stopped", name)
+	}()
+}
+
+// OnStop registers a hook that runs once all workers have stopped, such as flushing a producer
+// or closing a database client. Hooks run in reverse registration order.
+func (l *Lifecycle) OnStop(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, stopHook{name: name, stop: stop})
+}
+
+// Shutdown starts shutdown without a signal.
+func (l *Lifecycle) Shutdown() {
+	l.cancel()
+}
+
+// Wait blocks until shutdown starts, then stops workers and runs the stop hooks. It returns the
+// error of a failed worker, or an error if shutdown did not complete in time.
+func (l *Lifecycle) Wait() error {
+	<-l.ctx.Done()
+	l.cancel()
+	logger.Log.Info().Dur("timeout", l.timeout).Msgf("Shutting down %s...", l.name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	drained := make(chan struct{})
+	go func() {
+		l.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		l.fail(errors.New("workers did not stop before the shutdown timeout"))
+		logger.Log.Error().Msgf("%s workers did not stop in time; in-flight work may be redelivered", l.name)
+	}
+
+	l.mu.Lock()
+	hooks := l.hooks
+	l.mu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].stop(ctx); err != nil {
+			logger.Log.Error().Err(err).Msgf("Failed to stop %s", hooks[i].name)
+			l.fail(fmt.Errorf("stop %s: %w", hooks[i].name, err))
+		}
+	}
+
+	logger.Log.Info().Msgf("%s stopped", l.name)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+func (l *Lifecycle) fail(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.err = errors.Join(l.err, err)
+}
@@ -0,0 +1,168 @@
+// Package audit keeps the append-only trail of administrative changes. Every entry carries the
+// hash of the entry before it, so rewriting or removing an entry breaks the chain from there on.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// genesisHash is the previous hash of the first entry.
+var genesisHash = strings.Repeat("0", 64)
+
+// Entry records one change made by an actor.
+type Entry struct {
+	Seq        int64             `json:"seq" gorm:"primaryKey;autoIncrement"`
+	ID         uuid.UUID         `json:"id" gorm:"type:uuid;not null;uniqueIndex"`
+	Service    string            `json:"service" gorm:"type:varchar(50);not null"`
+	Actor      string            `json:"actor,omitempty" gorm:"type:varchar(255)"`
+	Action     string            `json:"action" gorm:"type:varchar(255);not null"`
+	EntityType string            `json:"entityType,omitempty" gorm:"type:varchar(50)"`
+	EntityID   string            `json:"entityId,omitempty" gorm:"type:varchar(255)"`
+	Changes    map[string]Change `json:"changes,omitempty" gorm:"type:jsonb;serializer:json"`
+	RequestID  string            `json:"requestId,omitempty" gorm:"type:varchar(128)"`
+	Status     int               `json:"status,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt" gorm:"type:timestamp with time zone;not null"`
+	PrevHash   string            `json:"prevHash" gorm:"type:varchar(64);not null"`
+	Hash       string            `json:"hash" gorm:"type:varchar(64);not null"`
+}
+
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// Change is the value of a field before and after a change; From is nil for created entities
+// and To for deleted ones.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// computeHash hashes the entry's content and its previous hash. Seq is left out; the chain
+// itself orders the entries.
+func (e *Entry) computeHash() string {
+	content, _ := json.Marshal(struct {
+		ID         uuid.UUID         `json:"id"`
+		Service    string            `json:"service"`
+		Actor      string            `json:"actor"`
+		Action     string            `json:"action"`
+		EntityType string            `json:"entityType"`
+		EntityID   string            `json:"entityId"`
+		Changes    map[string]Change `json:"changes"`
+		RequestID  string            `json:"requestId"`
+		Status     int               `json:"status"`
+		CreatedAt  string            `json:"createdAt"`
+		PrevHash   string            `json:"prevHash"`
+	}{e.ID, e.Service, e.Actor, e.Action, e.EntityType, e.EntityID, e.Changes, e.RequestID, e.Status,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano), e.PrevHash})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff returns the top-level JSON fields that differ between before and after. Either may be
+// nil, or a nil pointer. Fields hidden from JSON, such as key hashes, never appear.
+func Diff(before, after any) map[string]Change {
+	from, to := fields(before), fields(after)
+	changes := make(map[string]Change)
+	for name, value := range to {
+		if previous, ok := from[name]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[name] = Change{From: previous, To: value}
+		}
+	}
+	for name, value := range from {
+		if _, ok := to[name]; !ok {
+			changes[name] = Change{From: value}
+		}
+	}
+	return changes
+}
+
+// fields decodes v as a JSON object so values compare and hash the same before and after they
+// are stored.
+func fields(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var object map[string]any
+	if json.Unmarshal(data, &object) != nil {
+		return nil
+	}
+	return object
+}
+
+// Subject is an entity changed by a request.
+type Subject struct {
+	EntityType string
+	EntityID   string
+	Changes    map[string]Change
+}
+
+// Subjects collects the entities a request changed. The server's audit middleware puts one in
+// the request context and records an entry per subject once the handler has run.
+type Subjects struct {
+	mu       sync.Mutex
+	subjects []Subject
+	ignored  bool
+}
+
+func (s *Subjects) List() []Subject {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Subject(nil), s.subjects...)
+}
+
+// Ignored reports whether the request changed nothing worth recording.
+func (s *Subjects) Ignored() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ignored
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx collecting the entities changed into subjects.
+func NewContext(ctx context.Context, subjects *Subjects) context.Context {
+	return context.WithValue(ctx, contextKey{}, subjects)
+}
+
+// Describe records that the request behind ctx changed an entity from before to after. It does
+// nothing outside an audited request or when no field changed.
+func Describe(ctx context.Context, entityType, entityID string, before, after any) {
+	subjects, ok := ctx.Value(contextKey{}).(*Subjects)
+	if !ok {
+		return
+	}
+	changes := Diff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	subjects.mu.Lock()
+	defer subjects.mu.Unlock()
+	subjects.subjects = append(subjects.subjects, Subject{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+	})
+}
+
+// Ignore marks the request behind ctx as changing nothing, such as a POST that only previews
+// a change.
+func Ignore(ctx context.Context) {
+	if subjects, ok := ctx.Value(contextKey{}).(*Subjects); ok {
+		subjects.mu.Lock()
+		defer subjects.mu.Unlock()
+		subjects.ignored = true
+	}
+}
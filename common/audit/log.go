@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// chainLock is the advisory lock serialising appends, so each entry links to the last one.
+	chainLock = 0x61756469
+	// verifyBatchSize bounds the entries held in memory while the chain is verified.
+	verifyBatchSize = 1000
+	maxListLimit    = 500
+)
+
+// Recorder appends entries to the audit log.
+type Recorder interface {
+	Record(ctx context.Context, entry *Entry) error
+}
+
+// Filter narrows a listing. Entries are listed newest first; BeforeSeq pages back from the
+// last entry of the previous page.
+type Filter struct {
+	EntityType string
+	EntityID   string
+	Actor      string
+	From       *time.Time
+	To         *time.Time
+	BeforeSeq  int64
+	Limit      int
+}
+
+// Verification is the result of walking the chain. BrokenAt is the first entry whose hash or
+// link to its predecessor does not match.
+type Verification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	LastHash string `json:"lastHash,omitempty"`
+}
+
+type Log interface {
+	Recorder
+	List(ctx context.Context, filter Filter) ([]Entry, error)
+	Verify(ctx context.Context) (*Verification, error)
+}
+
+type auditLog struct {
+	db      *gorm.DB
+	service string
+}
+
+// NewLog returns the audit log stored in db; entries recorded without a service are attributed
+// to service.
+func NewLog(db *gorm.DB, service string) Log {
+	return &auditLog{db: db, service: service}
+}
+
+// Record chains entry to the last entry and appends it, filling in its ID, time and hashes.
+func (l *auditLog) Record(ctx context.Context, entry *Entry) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.Service == "" {
+		entry.Service = l.service
+	}
+	return l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLock).Error; err != nil {
+			return err
+		}
+		var last Entry
+		err := tx.Order("seq DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+		entry.PrevHash = genesisHash
+		if last.Hash != "" {
+			entry.PrevHash = last.Hash
+		}
+		// Postgres keeps microseconds; the hash must match the stored time
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Hash = entry.computeHash()
+		return tx.Create(entry).Error
+	})
+}
+
+func (l *auditLog) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	query := l.db.WithContext(ctx).Order("seq DESC")
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.BeforeSeq > 0 {
+		query = query.Where("seq < ?", filter.BeforeSeq)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	var entries []Entry
+	err := query.Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Verify recomputes every hash from the first entry on. It detects entries that were changed,
+// removed or inserted; removing the newest entries is only detected against a LastHash kept
+// elsewhere.
+func (l *auditLog) Verify(ctx context.Context) (*Verification, error) {
+	result := &Verification{Valid: true}
+	prevHash := genesisHash
+	var afterSeq int64
+	for {
+		var entries []Entry
+		err := l.db.WithContext(ctx).Where("seq > ?", afterSeq).Order("seq").Limit(verifyBatchSize).Find(&entries).Error
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			entry := &entries[i]
+			if reason := check(entry, prevHash); reason != "" {
+				result.Valid = false
+				result.BrokenAt = entry.Seq
+				result.Reason = reason
+				return result, nil
+			}
+			result.Entries++
+			prevHash = entry.Hash
+			afterSeq = entry.Seq
+		}
+		if len(entries) < verifyBatchSize {
+			break
+		}
+	}
+	if result.Entries > 0 {
+		result.LastHash = prevHash
+	}
+	return result, nil
+}
+
+func check(entry *Entry, prevHash string) string {
+	if entry.PrevHash != prevHash {
+		return "previous hash does not match the preceding entry"
+	}
+	if entry.computeHash() != entry.Hash {
+		return "hash does not match the entry's content"
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const clientTimeout = 10 * time.Second
+
+// NewClient returns an HTTP client for calls to other services. It authenticates with a
+// client-credentials token when a token URL is configured, fetching and renewing tokens
+// as they expire, and falls back to the shared basic auth credentials otherwise.
+func NewClient(ctx context.Context, authConfig *config.AuthConfig, apiAuth *config.ApiAuth) *http.Client {
+	base := requestid.NewTransport(tracing.NewTransport(http.DefaultTransport))
+
+	if authConfig != nil && authConfig.TokenURL != "" {
+		credentials := clientcredentials.Config{
+			ClientID:     authConfig.ClientID,
+			ClientSecret: authConfig.ClientSecret,
+			TokenURL:     authConfig.TokenURL,
+			Scopes:       authConfig.Scopes,
+		}
+		// oauth2 uses the client from the context for token requests and as its base transport
+		client := credentials.Client(context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: base}))
+		client.Timeout = clientTimeout
+		return client
+	}
+
+	transport := &basicAuthTransport{base: base}
+	if apiAuth != nil {
+		transport.username, transport.password = apiAuth.UserName, apiAuth.Password
+	}
+	return &http.Client{Transport: transport, Timeout: clientTimeout}
+}
+
+type basicAuthTransport struct {
+	username string
+	password string
+	base     http.RoundTripper
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return t.base.RoundTrip(req)
+}
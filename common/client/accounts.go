@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// ErrAccountNotFound is returned when no account has the ID or number looked up.
+var ErrAccountNotFound = fmt.Errorf("account %w", ErrNotFound)
+
+// Accounts calls the account service.
+type Accounts struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewAccounts(baseURL string, client *http.Client) *Accounts {
+	return &Accounts{baseURL: baseURL, client: client}
+}
+
+func (a *Accounts) GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error) {
+	var account map[string]any
+	endpoint := fmt.Sprintf("%s/api/v1/accounts/%s", a.baseURL, accountID.String())
+	if err := getJSON(ctx, a.client, endpoint, ErrAccountNotFound, &account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// FindAccountByNumber looks an account up by account number or IBAN.
+func (a *Accounts) FindAccountByNumber(ctx context.Context, number string) (map[string]any, error) {
+	var accounts []map[string]any
+	endpoint := fmt.Sprintf("%s/api/v1/accounts?number=%s", a.baseURL, url.QueryEscape(number))
+	if err := getJSON(ctx, a.client, endpoint, ErrAccountNotFound, &accounts); err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, ErrAccountNotFound
+	}
+	return accounts[0], nil
+}
@@ -0,0 +1,53 @@
+// Package client calls the HTTP APIs of the services with an authenticated HTTP client, such as
+// the one from auth.NewClient.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNotFound is returned when the service answers 404.
+var ErrNotFound = errors.New("not found")
+
+// StatusError is returned for responses other than 200 and 404.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s returned %d", e.URL, e.StatusCode)
+}
+
+// Retryable reports whether the request may succeed when repeated: the service was rate limited
+// or failed. Other statuses, such as a denied permission, will not change.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// getJSON decodes the response to a GET of url into out. A 404 is returned as notFound.
+func getJSON(ctx context.Context, client *http.Client, url string, notFound error, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(out)
+	case http.StatusNotFound:
+		return notFound
+	default:
+		return &StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ledger calls the ledger service.
+type Ledger struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewLedger(baseURL string, client *http.Client) *Ledger {
+	return &Ledger{baseURL: baseURL, client: client}
+}
+
+// ledgerEntriesPage is how many entries EachEntry fetches per request.
+const ledgerEntriesPage = 500
+
+// LedgerEntry is a successful ledger entry of an account.
+type LedgerEntry struct {
+	ID              string    `json:"id"`
+	TransactionType string    `json:"transactionType"`
+	Amount          float64   `json:"amount"`
+	AcceptedAt      time.Time `json:"acceptedAt"`
+}
+
+// EachEntry calls fn with the successful ledger entries of the account, oldest first, fetching
+// them a page at a time so the history is never held in full.
+func (l *Ledger) EachEntry(ctx context.Context, accountID uuid.UUID, fn func(LedgerEntry) error) error {
+	query := url.Values{"limit": {strconv.Itoa(ledgerEntriesPage)}}
+	for {
+		var page []LedgerEntry
+		endpoint := fmt.Sprintf("%s/api/v1/ledger/accounts/%s/entries?%s", l.baseURL, accountID.String(), query.Encode())
+		if err := getJSON(ctx, l.client, endpoint, ErrNotFound, &page); err != nil {
+			return err
+		}
+		for _, entry := range page {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(page) < ledgerEntriesPage {
+			return nil
+		}
+		last := page[len(page)-1]
+		query.Set("afterAcceptedAt", last.AcceptedAt.Format(time.RFC3339Nano))
+		query.Set("afterId", last.ID)
+	}
+}
+
+// ClosingBalance returns the account's balance at the end of day (UTC), as the ledger's
+// statement for the day reports it.
+func (l *Ledger) ClosingBalance(ctx context.Context, accountID uuid.UUID, day time.Time) (float64, error) {
+	var statement struct {
+		Totals struct {
+			ClosingBalance float64 `json:"closingBalance"`
+		} `json:"totals"`
+	}
+	date := day.Format(time.DateOnly)
+	endpoint := fmt.Sprintf("%s/api/v1/ledger/accounts/%s/statement?format=json&from=%s&to=%s", l.baseURL, accountID.String(), date, date)
+	if err := getJSON(ctx, l.client, endpoint, ErrNotFound, &statement); err != nil {
+		return 0, err
+	}
+	return statement.Totals.ClosingBalance, nil
+}
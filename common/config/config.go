@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,10 +21,15 @@ const (
 
 // Config holds the overall application configuration.
 type Config struct {
-	Services map[string]*ServiceConfig
-	Database *DatabaseConfig
-	Kafka    *KafkaConfig
-	ApiAuth  *ApiAuth
+	Services  map[string]*ServiceConfig
+	Database  *DatabaseConfig
+	Kafka     *KafkaConfig
+	ApiAuth   *ApiAuth
+	Auth      *AuthConfig
+	RateLimit *RateLimitConfig
+	Tracing   *TracingConfig
+	Logging   *LoggingConfig
+	Lifecycle *LifecycleConfig
 }
 
 // ServiceConfig holds the configuration for a specific service.
@@ -48,6 +54,51 @@ type ApiAuth struct {
 	Password string `env:"API_AUTH_PASSWORD" default:""`
 }
 
+// AuthConfig holds the bearer token configuration. Incoming JWTs are verified against
+// the JWKS file or URL; outgoing service-to-service calls use the client-credentials grant
+// when a token URL is set.
+type AuthConfig struct {
+	JWKSFile      string   `env:"AUTH_JWKS_FILE"`
+	JWKSURL       string   `env:"AUTH_JWKS_URL"`
+	Issuer        string   `env:"AUTH_ISSUER"`
+	Audience      string   `env:"AUTH_AUDIENCE"`
+	BasicFallback bool     `env:"AUTH_BASIC_FALLBACK" default:"false"`
+	TokenURL      string   `env:"AUTH_TOKEN_URL"`
+	ClientID      string   `env:"AUTH_CLIENT_ID"`
+	ClientSecret  string   `env:"AUTH_CLIENT_SECRET"`
+	Scopes        []string `env:"AUTH_CLIENT_SCOPES"`
+}
+
+// RateLimitConfig holds the default per-client rate limit of a service. Each authenticated
+// client gets a token bucket per route refilled at RequestsPerSecond up to Burst requests.
+// A zero rate leaves routes without a service-specific limit unlimited. ServiceClients are the
+// OAuth client IDs or basic auth usernames of the internal services; their calls are not limited.
+type RateLimitConfig struct {
+	RequestsPerSecond float64  `env:"RATE_LIMIT_RPS" default:"50"`
+	Burst             int      `env:"RATE_LIMIT_BURST" default:"100"`
+	ServiceClients    []string `env:"RATE_LIMIT_SERVICE_CLIENTS"`
+}
+
+// TracingConfig selects where spans are exported: "otlp" sends them to the collector at
+// OTEL_EXPORTER_OTLP_ENDPOINT, "stdout" prints them for local runs and "none" disables tracing.
+type TracingConfig struct {
+	Exporter    string  `env:"OTEL_TRACES_EXPORTER" default:"none"`
+	SampleRatio float64 `env:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
+}
+
+// LoggingConfig selects the log output: "json" for containers and log shippers, "console" for
+// local runs. Version is added to every log line.
+type LoggingConfig struct {
+	Format  string `env:"LOG_FORMAT"`
+	Version string `env:"SERVICE_VERSION" default:"dev"`
+}
+
+// LifecycleConfig bounds graceful shutdown: draining HTTP requests and in-flight messages,
+// flushing producers and closing database clients.
+type LifecycleConfig struct {
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+}
+
 // LoadConfig loads the overall application configuration.
 func LoadConfig() (*Config, error) {
 	if os.Getenv("IS_LOCAL") == "true" {
@@ -69,11 +120,33 @@ func LoadConfig() (*Config, error) {
 		services[serviceName] = serviceConfig
 	}
 
+	rateLimit, err := LoadRateLimitConfig()
+	if err != nil {
+		return nil, err
+	}
+	tracing, err := LoadTracingConfig()
+	if err != nil {
+		return nil, err
+	}
+	logging, err := LoadLoggingConfig()
+	if err != nil {
+		return nil, err
+	}
+	lifecycle, err := LoadLifecycleConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
-		Services: services,
-		Database: LoadDatabaseConfig(),
-		Kafka:    LoadKafkaConfig(),
-		ApiAuth:  LoadApiAuthConfig(),
+		Services:  services,
+		Database:  LoadDatabaseConfig(),
+		Kafka:     LoadKafkaConfig(),
+		ApiAuth:   LoadApiAuthConfig(),
+		Auth:      LoadAuthConfig(),
+		RateLimit: rateLimit,
+		Tracing:   tracing,
+		Logging:   logging,
+		Lifecycle: lifecycle,
 	}, nil
 }
 
@@ -147,3 +220,100 @@ func LoadApiAuthConfig() *ApiAuth {
 		Password: pass,
 	}
 }
+
+// LoadAuthConfig loads the bearer token configuration.
+func LoadAuthConfig() *AuthConfig {
+	basicFallback, _ := strconv.ParseBool(os.Getenv("AUTH_BASIC_FALLBACK"))
+
+	var scopes []string
+	if value := os.Getenv("AUTH_CLIENT_SCOPES"); value != "" {
+		scopes = strings.Split(value, ",")
+	}
+
+	return &AuthConfig{
+		JWKSFile:      os.Getenv("AUTH_JWKS_FILE"),
+		JWKSURL:       os.Getenv("AUTH_JWKS_URL"),
+		Issuer:        os.Getenv("AUTH_ISSUER"),
+		Audience:      os.Getenv("AUTH_AUDIENCE"),
+		BasicFallback: basicFallback,
+		TokenURL:      os.Getenv("AUTH_TOKEN_URL"),
+		ClientID:      os.Getenv("AUTH_CLIENT_ID"),
+		ClientSecret:  os.Getenv("AUTH_CLIENT_SECRET"),
+		Scopes:        scopes,
+	}
+}
+
+// LoadRateLimitConfig loads the default rate limit.
+func LoadRateLimitConfig() (*RateLimitConfig, error) {
+	config := &RateLimitConfig{RequestsPerSecond: 50, Burst: 100}
+
+	if value := os.Getenv("RATE_LIMIT_RPS"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_RPS %q", value)
+		}
+		config.RequestsPerSecond = rate
+	}
+	if value := os.Getenv("RATE_LIMIT_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_BURST %q", value)
+		}
+		config.Burst = burst
+	}
+	if value := os.Getenv("RATE_LIMIT_SERVICE_CLIENTS"); value != "" {
+		config.ServiceClients = strings.Split(value, ",")
+	}
+	return config, nil
+}
+
+// LoadTracingConfig loads the trace exporter configuration.
+func LoadTracingConfig() (*TracingConfig, error) {
+	config := &TracingConfig{Exporter: "none", SampleRatio: 1}
+
+	if value := os.Getenv("OTEL_TRACES_EXPORTER"); value != "" {
+		config.Exporter = value
+	}
+	if value := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER_ARG %q", value)
+		}
+		config.SampleRatio = ratio
+	}
+	return config, nil
+}
+
+// LoadLoggingConfig loads the log output configuration. Output defaults to JSON, or to the
+// console when running locally.
+func LoadLoggingConfig() (*LoggingConfig, error) {
+	config := &LoggingConfig{Format: "json", Version: "dev"}
+	if os.Getenv("IS_LOCAL") == "true" {
+		config.Format = "console"
+	}
+
+	if value := os.Getenv("LOG_FORMAT"); value != "" {
+		if value != "json" && value != "console" {
+			return nil, fmt.Errorf("invalid LOG_FORMAT %q", value)
+		}
+		config.Format = value
+	}
+	if value := os.Getenv("SERVICE_VERSION"); value != "" {
+		config.Version = value
+	}
+	return config, nil
+}
+
+// LoadLifecycleConfig loads the shutdown timeout.
+func LoadLifecycleConfig() (*LifecycleConfig, error) {
+	config := &LifecycleConfig{ShutdownTimeout: 30 * time.Second}
+
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid SHUTDOWN_TIMEOUT %q", value)
+		}
+		config.ShutdownTimeout = timeout
+	}
+	return config, nil
+}
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS batch_items;
+DROP TABLE IF EXISTS batches;
+DROP TABLE IF EXISTS schedule_executions;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS risk_reviews;
+DROP TABLE IF EXISTS risk_activity;
+DROP TABLE IF EXISTS limit_reservations;
+DROP TABLE IF EXISTS limit_usage;
+DROP TABLE IF EXISTS transaction_limits;
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS customers;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,196 @@
+CREATE TABLE customers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) UNIQUE,
+    phone_number VARCHAR(20) UNIQUE,
+    address TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID REFERENCES customers(id) NOT NULL,
+    account_number VARCHAR(20) UNIQUE NOT NULL,
+    account_type VARCHAR(50) NOT NULL, -- Ex - "checking", "savings", "credit"
+    balance DECIMAL(19, 4) NOT NULL DEFAULT 0.0000,
+    status VARCHAR(20) NOT NULL DEFAULT 'active', -- Ex- "active", "closed", "frozen"
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE interest_accruals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID REFERENCES accounts(id) NOT NULL,
+    accrual_date DATE NOT NULL,
+    balance DECIMAL(19, 4) NOT NULL,
+    annual_rate DECIMAL(9, 6) NOT NULL,
+    day_count VARCHAR(10) NOT NULL, -- Ex - "ACT/365", "ACT/360", "ACT/ACT"
+    amount DECIMAL(19, 8) NOT NULL,
+    capitalized_at TIMESTAMP WITH TIME ZONE,
+    transaction_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, accrual_date)
+);
+
+CREATE TABLE transaction_limits (
+    scope VARCHAR(20) NOT NULL, -- Ex - "account_type", "customer", "account"
+    scope_id VARCHAR(64) NOT NULL,
+    single_transaction_max DECIMAL(19, 4),
+    daily_debit_max DECIMAL(19, 4),
+    monthly_debit_max DECIMAL(19, 4),
+    hourly_count_max BIGINT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, scope_id)
+);
+
+CREATE TABLE limit_usage (
+    account_id VARCHAR(64) NOT NULL,
+    period VARCHAR(10) NOT NULL, -- Ex - "hour", "day", "month"
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    debit_total DECIMAL(19, 4) NOT NULL DEFAULT 0.0000,
+    transaction_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (account_id, period, period_start)
+);
+
+CREATE TABLE limit_reservations (
+    transaction_id VARCHAR(64) PRIMARY KEY,
+    account_id VARCHAR(64) NOT NULL,
+    amount DECIMAL(19, 4) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE risk_activity (
+    transaction_id VARCHAR(64) PRIMARY KEY,
+    account_id VARCHAR(64) NOT NULL,
+    amount DECIMAL(19, 4) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    outcome VARCHAR(10) NOT NULL, -- Ex - "allow", "review", "deny"
+    accepted_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE risk_reviews (
+    transaction_id VARCHAR(64) PRIMARY KEY,
+    account_id VARCHAR(64) NOT NULL,
+    amount DECIMAL(19, 4) NOT NULL,
+    message_key BYTEA,
+    payload JSONB NOT NULL,
+    reasons JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL, -- Ex - "pending", "approved", "rejected"
+    decided_by VARCHAR(255),
+    decision_note TEXT,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL,
+    customer_id VARCHAR(64),
+    amount DECIMAL(19, 4) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    details TEXT,
+    counterparty VARCHAR(64),
+    frequency VARCHAR(10) NOT NULL, -- Ex - "once", "daily", "weekly", "monthly"
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITH TIME ZONE,
+    max_runs INTEGER,
+    run_count INTEGER NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL, -- Ex - "active", "completed", "cancelled"
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE schedule_executions (
+    id UUID PRIMARY KEY, -- ID of the submitted transaction
+    schedule_id UUID NOT NULL REFERENCES schedules(id),
+    run_number INTEGER NOT NULL,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL, -- Ex - "submitted", "pending_review", "success", "failed"
+    reason TEXT,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT idx_schedule_executions_run UNIQUE (schedule_id, run_number)
+);
+
+CREATE TABLE batches (
+    id UUID PRIMARY KEY,
+    mode VARCHAR(20) NOT NULL, -- Ex - "partial", "all_or_nothing"
+    status VARCHAR(20) NOT NULL, -- Ex - "accepted", "rejected"
+    item_count INTEGER NOT NULL,
+    accepted_count INTEGER NOT NULL,
+    rejected_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE batch_items (
+    batch_id UUID NOT NULL REFERENCES batches(id),
+    line INTEGER NOT NULL,
+    transaction_id UUID UNIQUE,
+    account_id UUID,
+    amount DECIMAL(19, 4),
+    status VARCHAR(20) NOT NULL, -- Ex - "rejected", "submitted", "pending_review", "success", "failed"
+    reason TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (batch_id, line)
+);
+
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL, -- SHA-256 of the key secret
+    permissions JSONB NOT NULL,
+    account_ids JSONB, -- Optional allowlist of account IDs
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    rotated_from_id UUID REFERENCES api_keys(id),
+    created_by VARCHAR(255),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Default limits per account type
+INSERT INTO transaction_limits (scope, scope_id, single_transaction_max, daily_debit_max, monthly_debit_max, hourly_count_max) VALUES
+    ('account_type', 'checking', 10000.0000, 20000.0000, 100000.0000, 60),
+    ('account_type', 'savings', 5000.0000, 5000.0000, 20000.0000, 20),
+    ('account_type', 'credit', 5000.0000, 10000.0000, 50000.0000, 30);
+
+-- Indexes for performance
+CREATE INDEX idx_accounts_customer_id ON accounts (customer_id);
+CREATE INDEX idx_accounts_account_number ON accounts (account_number);
+CREATE INDEX idx_customers_email ON customers (email);
+CREATE INDEX idx_customers_phone_number ON customers (phone_number);
+CREATE INDEX idx_interest_accruals_pending ON interest_accruals (account_id, accrual_date) WHERE capitalized_at IS NULL;
+CREATE INDEX idx_risk_activity_account_accepted_at ON risk_activity (account_id, accepted_at);
+CREATE INDEX idx_risk_reviews_account_id ON risk_reviews (account_id);
+CREATE INDEX idx_risk_reviews_status ON risk_reviews (status);
+CREATE INDEX idx_schedules_account_id ON schedules (account_id);
+CREATE INDEX idx_schedules_due ON schedules (next_run_at) WHERE status = 'active';
+
+
+-- Function to automatically update the updated_at timestamp
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger to call the function before updating accounts
+CREATE TRIGGER update_accounts_updated_at
+BEFORE UPDATE ON accounts
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at_column();
+
+-- Trigger to call the function before updating customers
+CREATE TRIGGER update_customers_updated_at
+BEFORE UPDATE ON customers
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at_column();
+
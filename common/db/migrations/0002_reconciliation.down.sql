@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_accounts_updated_at;
+DROP TABLE IF EXISTS reconciliation_breaks;
+DROP TABLE IF EXISTS reconciliation_runs;
@@ -0,0 +1,26 @@
+CREATE TABLE reconciliation_runs (
+    id UUID PRIMARY KEY,
+    mode VARCHAR(20) NOT NULL, -- Ex - "full", "incremental"
+    status VARCHAR(20) NOT NULL, -- Ex - "running", "completed", "failed"
+    watermark_from TIMESTAMP WITH TIME ZONE, -- Changes after this were checked; NULL for full runs
+    watermark_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    accounts_checked INTEGER NOT NULL DEFAULT 0,
+    breaks_found INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE reconciliation_breaks (
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+    account_id VARCHAR(64) NOT NULL,
+    account_balance DECIMAL(19, 4), -- NULL when the account only exists in the ledger
+    ledger_balance DECIMAL(19, 4) NOT NULL,
+    difference DECIMAL(19, 4) NOT NULL,
+    entry_count INTEGER NOT NULL,
+    transactions JSONB NOT NULL, -- Successful ledger entries of the account
+    PRIMARY KEY (run_id, account_id)
+);
+
+CREATE INDEX idx_reconciliation_runs_completed ON reconciliation_runs (started_at) WHERE status = 'completed';
+CREATE INDEX idx_accounts_updated_at ON accounts (updated_at);
@@ -0,0 +1,15 @@
+CREATE TABLE balance_rebuilds (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    previous_balance DECIMAL(19, 4) NOT NULL,
+    previous_version BIGINT NOT NULL,
+    rebuilt_balance DECIMAL(19, 4) NOT NULL,
+    rebuilt_version BIGINT NOT NULL,
+    entry_count INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL, -- Ex - "ledger"
+    applied_by VARCHAR(255),
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_balance_rebuilds_account_id ON balance_rebuilds (account_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bank_import_lines;
+DROP TABLE IF EXISTS bank_imports;
+ALTER TABLE accounts DROP COLUMN IF EXISTS iban;
@@ -0,0 +1,33 @@
+ALTER TABLE accounts ADD COLUMN iban VARCHAR(34) UNIQUE;
+
+CREATE TABLE bank_imports (
+    id UUID PRIMARY KEY,
+    file_name VARCHAR(255),
+    format VARCHAR(20) NOT NULL, -- Ex - "camt054", "csv"
+    line_count INTEGER NOT NULL,
+    matched_count INTEGER NOT NULL,
+    unmatched_count INTEGER NOT NULL,
+    duplicate_count INTEGER NOT NULL,
+    rejected_count INTEGER NOT NULL,
+    imported_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE bank_import_lines (
+    import_id UUID NOT NULL REFERENCES bank_imports(id),
+    line INTEGER NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    reference VARCHAR(255),
+    account_identifier VARCHAR(64),
+    account_id UUID,
+    amount DECIMAL(19, 4),
+    currency VARCHAR(3),
+    booked_at TIMESTAMP WITH TIME ZONE,
+    transaction_id UUID UNIQUE,
+    status VARCHAR(20) NOT NULL, -- Ex - "matched", "unmatched", "duplicate", "rejected", "failed"
+    reason TEXT,
+    PRIMARY KEY (import_id, line)
+);
+
+-- A file line is credited once, however often the file is imported
+CREATE UNIQUE INDEX idx_bank_import_lines_matched_key ON bank_import_lines (idempotency_key) WHERE status = 'matched';
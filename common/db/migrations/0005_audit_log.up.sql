@@ -0,0 +1,30 @@
+CREATE TABLE audit_log (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    service VARCHAR(50) NOT NULL,
+    actor VARCHAR(255),
+    action VARCHAR(255) NOT NULL, -- Ex - "POST /api/v1/accounts"
+    entity_type VARCHAR(50),
+    entity_id VARCHAR(255),
+    changes JSONB,
+    request_id VARCHAR(128),
+    status INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor);
+
+-- Entries are append-only; corrections are new entries
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,14 @@
+-- Outbox of interest capitalizations: recorded with the accruals they capitalize, posted to
+-- transactions-topic after the commit. The sub-cent remainder is carried into the next one.
+CREATE TABLE interest_capitalizations (
+    transaction_id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    period VARCHAR(7) NOT NULL, -- Ex - "2026-09"
+    amount DECIMAL(19, 4) NOT NULL,
+    remainder DECIMAL(19, 8) NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_interest_capitalizations_account ON interest_capitalizations (account_id, created_at);
+CREATE INDEX idx_interest_capitalizations_unpublished ON interest_capitalizations (created_at) WHERE published_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE risk_reviews DROP COLUMN IF EXISTS applied_at;
@@ -0,0 +1,4 @@
+-- A decision is committed before it is forwarded; applied_at is set once it has been. Decisions
+-- taken so far were forwarded in the same transaction.
+ALTER TABLE risk_reviews ADD COLUMN applied_at TIMESTAMP WITH TIME ZONE;
+UPDATE risk_reviews SET applied_at = decided_at WHERE status <> 'pending';
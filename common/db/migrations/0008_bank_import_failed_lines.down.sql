@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bank_import_lines_matched_transaction;
+ALTER TABLE bank_import_lines ADD CONSTRAINT bank_import_lines_transaction_id_key UNIQUE (transaction_id);
@@ -0,0 +1,4 @@
+-- A line whose publish failed keeps its transaction ID, which the payment gets again when the
+-- file is imported again; only the matched line of a payment holds it
+ALTER TABLE bank_import_lines DROP CONSTRAINT IF EXISTS bank_import_lines_transaction_id_key;
+CREATE UNIQUE INDEX idx_bank_import_lines_matched_transaction ON bank_import_lines (transaction_id) WHERE status = 'matched';
@@ -2,15 +2,26 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
 
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ConnectMongo establishes a connection to the MongoDB database.
 func ConnectMongo(connStr string) (*mongo.Client, error) {
-	clientOptions := options.Client().ApplyURI(connStr)
+	clientOptions := options.Client().ApplyURI(connStr).SetMonitor(commandMonitor())
 	client, err := mongo.Connect(clientOptions)
 	if err != nil {
 		return nil, err
@@ -26,47 +37,194 @@ func ConnectMongo(connStr string) (*mongo.Client, error) {
 	return client, nil
 }
 
-// func EnsureIndexes(ctx context.Context, collection *mongo.Collection, models []interface{}) error {
-
-// 	for _, model := range models {
-// 		indexes, ok := model.(Indexable)
-// 		if !ok {
-// 			continue
-// 		}
-
-// 		for _, index := range indexes.Indexes() {
-// 			opts := options.Index()
-// 			if index.Unique {
-// 				opts.SetUnique(true)
-// 			}
-// 			if index.Sparse {
-// 				opts.SetSparse(true)
-// 			}
-
-// 			keys := bson.M{}
-// 			for k, v := range index.Keys {
-// 				keys[k] = v
-// 			}
-
-// 			_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-// 				Keys:    keys,
-// 				Options: opts,
-// 			})
-// 			if err != nil {
-// 				return err
-// 			}
-// 		}
-// 	}
-
-// 	return nil
-// }
-
-// type Indexable interface {
-// 	Indexes() []Index
-// }
-
-// type Index struct {
-// 	Keys   map[string]interface{}
-// 	Unique bool
-// 	Sparse bool
-// }
+// MongoHealthCheck pings the primary of the deployment behind client.
+func MongoHealthCheck(client *mongo.Client) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
+// commandMonitor times and traces every MongoDB command by collection.
+func commandMonitor() *event.CommandMonitor {
+	type command struct {
+		collection string
+		span       trace.Span
+	}
+	var commands sync.Map
+	finished := func(evt event.CommandFinishedEvent, err error) {
+		value, ok := commands.LoadAndDelete(evt.RequestID)
+		if !ok {
+			return
+		}
+		cmd := value.(command)
+		metrics.ObserveQuery("mongo", evt.CommandName, cmd.collection, evt.Duration)
+		if err != nil {
+			cmd.span.RecordError(err)
+			cmd.span.SetStatus(codes.Error, err.Error())
+		}
+		cmd.span.End()
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			_, span := tracing.Tracer().Start(ctx, "mongo "+evt.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBNamespace(evt.DatabaseName),
+					semconv.DBOperationName(evt.CommandName),
+					semconv.DBCollectionName(collection),
+				))
+			commands.Store(evt.RequestID, command{collection: collection, span: span})
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finished(evt.CommandFinishedEvent, nil)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finished(evt.CommandFinishedEvent, evt.Failure)
+		},
+	}
+}
+
+// Index declares an index of a collection. Name identifies it: EnsureIndexes rebuilds an index
+// whose keys or options no longer match its declaration.
+type Index struct {
+	Name   string
+	Keys   bson.D
+	Unique bool
+	Sparse bool
+}
+
+// EnsureIndexes makes the declared indexes of collection match indexes: missing ones are created
+// and changed ones rebuilt. Indexes that are not declared, such as ones added by an operator,
+// are left alone.
+func EnsureIndexes(ctx context.Context, collection *mongo.Collection, indexes []Index) error {
+	existing, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+
+	declared := make(map[string]Index, len(indexes))
+	for _, index := range indexes {
+		declared[index.Name] = index
+	}
+
+	current := make(map[string]bool, len(existing))
+	for _, spec := range existing {
+		index, ok := declared[spec.Name]
+		if !ok {
+			continue
+		}
+		if indexMatches(index, spec) {
+			current[spec.Name] = true
+			continue
+		}
+		if err := collection.Indexes().DropOne(ctx, spec.Name); err != nil {
+			return fmt.Errorf("drop index %s: %w", spec.Name, err)
+		}
+		logger.Log.Info().Str("collection", collection.Name()).Str("index", spec.Name).Msg("Dropped changed index")
+	}
+
+	var models []mongo.IndexModel
+	for _, index := range indexes {
+		if current[index.Name] {
+			continue
+		}
+		opts := options.Index().SetName(index.Name)
+		if index.Unique {
+			opts.SetUnique(true)
+		}
+		if index.Sparse {
+			opts.SetSparse(true)
+		}
+		models = append(models, mongo.IndexModel{Keys: index.Keys, Options: opts})
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	names, err := collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	logger.Log.Info().Str("collection", collection.Name()).Strs("indexes", names).Msg("Created indexes")
+	return nil
+}
+
+// HasIndex reports whether collection has an index named name.
+func HasIndex(ctx context.Context, collection *mongo.Collection, name string) (bool, error) {
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, spec := range specs {
+		if spec.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func indexMatches(index Index, spec mongo.IndexSpecification) bool {
+	if index.Unique != (spec.Unique != nil && *spec.Unique) || index.Sparse != (spec.Sparse != nil && *spec.Sparse) {
+		return false
+	}
+	elements, err := spec.KeysDocument.Elements()
+	if err != nil || len(elements) != len(index.Keys) {
+		return false
+	}
+	for i, element := range elements {
+		if element.Key() != index.Keys[i].Key || !indexKeyEqual(index.Keys[i].Value, element.Value()) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexKeyEqual compares a declared key direction or type with the one the server reports, which
+// may come back as a different numeric type.
+func indexKeyEqual(declared any, actual bson.RawValue) bool {
+	if name, ok := declared.(string); ok {
+		value, ok := actual.StringValueOK()
+		return ok && value == name
+	}
+	value, ok := actual.AsInt64OK()
+	if !ok {
+		return false
+	}
+	switch d := declared.(type) {
+	case int:
+		return value == int64(d)
+	case int32:
+		return value == int64(d)
+	case int64:
+		return value == d
+	default:
+		return false
+	}
+}
+
+// EnsureValidator applies a $jsonSchema validator to a collection, creating the collection if it
+// does not exist. Documents that already break the schema stay readable; inserts and updates of
+// valid documents must keep them valid. Changing the validator of an existing collection needs
+// the collMod privilege, which the dbAdmin role grants.
+func EnsureValidator(ctx context.Context, database *mongo.Database, collection string, schema bson.M) error {
+	validator := bson.M{"$jsonSchema": schema}
+
+	names, err := database.ListCollectionNames(ctx, bson.M{"name": collection})
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return database.CreateCollection(ctx, collection, options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("moderate").
+			SetValidationAction("error"))
+	}
+
+	return database.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}).Err()
+}
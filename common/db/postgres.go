@@ -1,8 +1,16 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"log"
+	"time"
 
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -13,6 +21,9 @@ func ConnectPostgres(connStr string) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := instrument(db); err != nil {
+		return nil, err
+	}
 	// Check connection
 	if err := db.Raw("SELECT 1").Error; err != nil {
 		return nil, err
@@ -20,3 +31,79 @@ func ConnectPostgres(connStr string) (*gorm.DB, error) {
 	log.Println("Successfully connected to Postgres")
 	return db, nil
 }
+
+// PostgresHealthCheck pings the database behind db.
+func PostgresHealthCheck(db *gorm.DB) func(context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// ClosePostgres closes the connection pool behind db.
+func ClosePostgres(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+const (
+	queryStartKey = "instrumentation:query_start"
+	querySpanKey  = "instrumentation:query_span"
+)
+
+// instrument times and traces every gorm operation.
+func instrument(db *gorm.DB) error {
+	start := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx, span := tracing.Tracer().Start(tx.Statement.Context, "postgres "+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(querySpanKey, span)
+			tx.InstanceSet(queryStartKey, time.Now())
+		}
+	}
+	finish := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if started, ok := tx.InstanceGet(queryStartKey); ok {
+				metrics.ObserveQuery("postgres", operation, tx.Statement.Table, time.Since(started.(time.Time)))
+			}
+			if value, ok := tx.InstanceGet(querySpanKey); ok {
+				span := value.(trace.Span)
+				span.SetAttributes(semconv.DBCollectionName(tx.Statement.Table), semconv.DBQueryText(tx.Statement.SQL.String()))
+				if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+					span.RecordError(tx.Error)
+					span.SetStatus(codes.Error, tx.Error.Error())
+				}
+				span.End()
+			}
+		}
+	}
+
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("instrumentation:before_create", start("create")),
+		callback.Create().After("gorm:create").Register("instrumentation:after_create", finish("create")),
+		callback.Query().Before("gorm:query").Register("instrumentation:before_query", start("query")),
+		callback.Query().After("gorm:query").Register("instrumentation:after_query", finish("query")),
+		callback.Update().Before("gorm:update").Register("instrumentation:before_update", start("update")),
+		callback.Update().After("gorm:update").Register("instrumentation:after_update", finish("update")),
+		callback.Delete().Before("gorm:delete").Register("instrumentation:before_delete", start("delete")),
+		callback.Delete().After("gorm:delete").Register("instrumentation:after_delete", finish("delete")),
+		callback.Row().Before("gorm:row").Register("instrumentation:before_row", start("row")),
+		callback.Row().After("gorm:row").Register("instrumentation:after_row", finish("row")),
+		callback.Raw().Before("gorm:raw").Register("instrumentation:before_raw", start("raw")),
+		callback.Raw().After("gorm:raw").Register("instrumentation:after_raw", finish("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
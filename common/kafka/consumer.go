@@ -2,42 +2,197 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"log"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
 )
 
+// heartbeatPollInterval is how long a fetch waits before the consumer reports that its loop
+// is still alive while no messages arrive.
+const heartbeatPollInterval = 10 * time.Second
+
 type kafkaConsumer struct {
-	reader *kafka.Reader
+	reader    *kafka.Reader
+	heartbeat Heartbeat
+}
+
+// Heartbeat is told whenever the consume loop makes progress.
+type Heartbeat interface {
+	Beat()
+}
+
+// ConsumerOption configures a consumer.
+type ConsumerOption func(*kafkaConsumer)
+
+// WithHeartbeat reports each fetched message, and each idle poll, to heartbeat so health checks
+// can tell a stuck or dead consume loop from an idle one.
+func WithHeartbeat(heartbeat Heartbeat) ConsumerOption {
+	return func(kc *kafkaConsumer) {
+		kc.heartbeat = heartbeat
+	}
 }
 
 type KafkaConsumer interface {
+	// Consume calls handler for each message and commits the message once handler has returned,
+	// until ctx is cancelled. The message in progress when ctx is cancelled is finished and
+	// committed before Consume returns nil.
+	// A message whose handler fails is logged and dropped: committing the next message of its
+	// partition commits past it. Handlers that must not lose a message retry or park it
+	// themselves before returning.
 	Consume(ctx context.Context, topic string, groupID string, handler func(kafka.Message) error) error
+	// ConsumeConcurrently is Consume with messages handled by a pool of workers. The messages of
+	// a partition go to the same worker, which commits each once handler has returned, so a
+	// message is never committed before it and every earlier message of its partition were
+	// handed to handler. As with Consume, failed messages are dropped. Messages already handed
+	// to a worker when ctx is cancelled are finished and committed before it returns.
+	ConsumeConcurrently(ctx context.Context, topic string, groupID string, workers Workers, handler func(kafka.Message) error) error
+	// Close leaves the consumer group; call it once every Consume has returned.
+	Close() error
+}
+
+// Workers sizes the worker pool of ConsumeConcurrently; Pool, when set, reports how busy it is.
+type Workers struct {
+	Size int
+	Pool *metrics.WorkerPool
 }
 
-func NewKafkaConsumer(brokers []string, groupID string, groupTopics []string) KafkaConsumer {
-	return &kafkaConsumer{
+func NewKafkaConsumer(brokers []string, groupID string, groupTopics []string, opts ...ConsumerOption) KafkaConsumer {
+	kc := &kafkaConsumer{
 		reader: kafka.NewReader(kafka.ReaderConfig{
 			Brokers:     brokers,
 			GroupID:     groupID,
 			GroupTopics: groupTopics,
 		}),
 	}
+	for _, opt := range opts {
+		opt(kc)
+	}
+	return kc
 }
 
 func (kc *kafkaConsumer) Consume(ctx context.Context, topic string, groupID string, handler func(kafka.Message) error) error {
+	return kc.fetchEach(ctx, topic, groupID, func(msg kafka.Message) error {
+		return kc.process(ctx, groupID, msg, handler)
+	})
+}
+
+func (kc *kafkaConsumer) ConsumeConcurrently(ctx context.Context, topic string, groupID string, workers Workers, handler func(kafka.Message) error) error {
+	size := max(workers.Size, 1)
+	// A failed commit stops fetching; the workers still finish what they were handed
+	fetchCtx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	queues := make([]chan kafka.Message, size)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message)
+		wg.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer wg.Done()
+			for msg := range queue {
+				if workers.Pool != nil {
+					workers.Pool.Started()
+				}
+				if err := kc.process(ctx, groupID, msg, handler); err != nil {
+					stop(err)
+				}
+				if workers.Pool != nil {
+					workers.Pool.Finished()
+				}
+			}
+		}(queues[i])
+	}
+
+	err := kc.fetchEach(fetchCtx, topic, groupID, func(msg kafka.Message) error {
+		if workers.Pool != nil {
+			workers.Pool.Enqueued()
+		}
+		queues[msg.Partition%size] <- msg
+		return nil
+	})
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+
+	if cause := context.Cause(fetchCtx); ctx.Err() == nil && cause != nil && !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	return err
+}
+
+// fetchEach calls fn with each fetched message until ctx is cancelled or fn fails.
+func (kc *kafkaConsumer) fetchEach(ctx context.Context, topic string, groupID string, fn func(kafka.Message) error) error {
 	kc.reader.SetOffset(kafka.FirstOffset)
 	for {
-		msg, err := kc.reader.FetchMessage(ctx)
+		msg, err := kc.fetch(ctx)
+		if errors.Is(err, errIdle) {
+			continue
+		}
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			metrics.KafkaConsumeErrors.WithLabelValues(topic, groupID).Inc()
 			return err
 		}
-		if err := handler(msg); err != nil {
-			log.Printf("Failed to handle message: %v", err)
-			continue
-		}
-		if err := kc.reader.CommitMessages(ctx, msg); err != nil {
+		kc.beat()
+		metrics.KafkaMessagesConsumed.WithLabelValues(msg.Topic, groupID).Inc()
+		metrics.KafkaConsumerLag.WithLabelValues(msg.Topic, groupID, strconv.Itoa(msg.Partition)).Set(float64(msg.HighWaterMark - msg.Offset - 1))
+
+		if err := fn(msg); err != nil {
 			return err
 		}
 	}
 }
+
+// process calls handler for msg and commits msg once it is handled. Only a failed commit is
+// returned; a message whose handler failed is logged and not committed itself, but the commit
+// of a later message of its partition moves past it.
+func (kc *kafkaConsumer) process(ctx context.Context, groupID string, msg kafka.Message, handler func(kafka.Message) error) error {
+	if err := handler(msg); err != nil {
+		metrics.KafkaConsumeErrors.WithLabelValues(msg.Topic, groupID).Inc()
+		log.Printf("Failed to handle message: %v", err)
+		return nil
+	}
+	// Commit even when shutdown has started so the finished message is not redelivered
+	if err := kc.reader.CommitMessages(context.WithoutCancel(ctx), msg); err != nil {
+		metrics.KafkaConsumeErrors.WithLabelValues(msg.Topic, groupID).Inc()
+		return err
+	}
+	return nil
+}
+
+func (kc *kafkaConsumer) Close() error {
+	return kc.reader.Close()
+}
+
+var errIdle = errors.New("no message within the poll interval")
+
+// fetch waits for the next message. With a heartbeat it gives up after the poll interval so the
+// loop can report that it is alive.
+func (kc *kafkaConsumer) fetch(ctx context.Context) (kafka.Message, error) {
+	if kc.heartbeat == nil {
+		return kc.reader.FetchMessage(ctx)
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, heartbeatPollInterval)
+	defer cancel()
+	msg, err := kc.reader.FetchMessage(pollCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		kc.beat()
+		return msg, errIdle
+	}
+	return msg, err
+}
+
+func (kc *kafkaConsumer) beat() {
+	if kc.heartbeat != nil {
+		kc.heartbeat.Beat()
+	}
+}
@@ -2,8 +2,12 @@ package kafka
 
 import (
 	"context"
+	"errors"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type kafkaProducer struct {
@@ -12,6 +16,11 @@ type kafkaProducer struct {
 
 type KafkaProducer interface {
 	Produce(ctx context.Context, topic string, message kafka.Message) error
+	// ProduceBatch publishes messages to topic in a single write. When only some of them fail,
+	// the error is a kafka.WriteErrors holding the error of each message, in order.
+	ProduceBatch(ctx context.Context, topic string, messages []kafka.Message) error
+	// Close flushes pending writes and closes the connections.
+	Close() error
 }
 
 func NewKafkaProducer(brokers []string) KafkaProducer {
@@ -25,5 +34,50 @@ func NewKafkaProducer(brokers []string) KafkaProducer {
 
 func (kp *kafkaProducer) Produce(ctx context.Context, topic string, message kafka.Message) error {
 	message.Topic = topic
-	return kp.writer.WriteMessages(ctx, message)
+	ctx, span := startPublishSpan(ctx, topic, &message)
+	defer span.End()
+
+	if err := kp.writer.WriteMessages(ctx, message); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		metrics.KafkaProduceErrors.WithLabelValues(topic).Inc()
+		return err
+	}
+	metrics.KafkaMessagesProduced.WithLabelValues(topic).Inc()
+	return nil
+}
+
+func (kp *kafkaProducer) ProduceBatch(ctx context.Context, topic string, messages []kafka.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	messages = append([]kafka.Message(nil), messages...)
+	spans := make([]trace.Span, len(messages))
+	for i := range messages {
+		messages[i].Topic = topic
+		_, spans[i] = startPublishSpan(ctx, topic, &messages[i])
+	}
+
+	err := kp.writer.WriteMessages(ctx, messages...)
+	var writeErrors kafka.WriteErrors
+	errors.As(err, &writeErrors)
+	for i, span := range spans {
+		messageErr := err
+		if writeErrors != nil {
+			messageErr = writeErrors[i]
+		}
+		if messageErr != nil {
+			span.RecordError(messageErr)
+			span.SetStatus(codes.Error, messageErr.Error())
+			metrics.KafkaProduceErrors.WithLabelValues(topic).Inc()
+		} else {
+			metrics.KafkaMessagesProduced.WithLabelValues(topic).Inc()
+		}
+		span.End()
+	}
+	return err
+}
+
+func (kp *kafkaProducer) Close() error {
+	return kp.writer.Close()
 }
@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier carries the trace context and request ID in Kafka message headers.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (h headerCarrier) Get(key string) string {
+	for _, header := range *h.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	for i, header := range *h.headers {
+		if header.Key == key {
+			(*h.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*h.headers = append(*h.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*h.headers))
+	for _, header := range *h.headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+// startPublishSpan starts a producer span and writes its context and the request ID into the
+// message headers.
+func startPublishSpan(ctx context.Context, topic string, message *kafka.Message) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingKafkaMessageKey(string(message.Key)),
+		))
+
+	// Copy the headers so retries of the same message do not share the slice
+	message.Headers = append([]kafka.Header(nil), message.Headers...)
+	carrier := headerCarrier{headers: &message.Headers}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if id := requestid.FromContext(ctx); id != "" {
+		carrier.Set(requestid.Header, id)
+	}
+	return ctx, span
+}
+
+// StartProcessSpan continues the trace and request ID carried by msg; messages without a request
+// ID get a new one. Handlers call it before working on a consumed message and end the span when
+// done.
+func StartProcessSpan(ctx context.Context, msg kafka.Message, groupID string) (context.Context, trace.Span) {
+	carrier := headerCarrier{headers: &msg.Headers}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	ctx = requestid.NewContext(ctx, requestid.OrNew(carrier.Get(requestid.Header)))
+	return tracing.Tracer().Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+			semconv.MessagingKafkaConsumerGroup(groupID),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		))
+}
@@ -1,28 +1,74 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"io"
 	"os"
 
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+
 	"github.com/rs/zerolog"
 )
 
-//Log ...
+// Log ...
 var Log zerolog.Logger
 
-//InitLogger ...
-func InitLogger() {
+// Options configures the service logger.
+type Options struct {
+	Service string
+	Version string
+	// Level is the initial level; it can be changed at runtime with SetLevel.
+	Level string
+	// Format is "json" or "console".
+	Format string
+}
+
+// InitLogger ...
+func InitLogger(opts Options) {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
-	logLevel := zerolog.InfoLevel // Default log level
-	if levelStr := os.Getenv("LOG_LEVEL"); levelStr != "" {
-		level, err := zerolog.ParseLevel(levelStr)
-		if err != nil {
-			log.Println("Invalid LOG_LEVEL environment variable, using default")
-		} else {
-			logLevel = level
-		}
+
+	var w io.Writer = os.Stdout
+	if opts.Format == "console" {
+		w = zerolog.ConsoleWriter{Out: os.Stdout}
 	}
-	w := zerolog.ConsoleWriter{Out: os.Stdout}
 
-	Log = zerolog.New(w).Level(logLevel).With().Timestamp().Logger()
+	Log = zerolog.New(newRedactingWriter(w)).With().
+		Timestamp().
+		Str("service", opts.Service).
+		Str("version", opts.Version).
+		Logger()
+
+	if err := SetLevel(opts.Level); err != nil {
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		Log.Warn().Str("level", opts.Level).Msg("Invalid log level, using info")
+	}
+}
+
+// SetLevel changes the level of all loggers at runtime.
+func SetLevel(level string) error {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	if parsed == zerolog.NoLevel {
+		parsed = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(parsed)
+	return nil
+}
+
+// Level returns the current log level.
+func Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
+// Ctx returns Log with the request ID carried by ctx attached, so log lines from one request
+// can be joined across services. Code that has a context should log through it.
+func Ctx(ctx context.Context) *zerolog.Logger {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return &Log
+	}
+	l := Log.With().Str("requestId", id).Logger()
+	return &l
 }
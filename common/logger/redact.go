@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields are masked wherever they appear in a log line, compared lower-cased with
+// "-" and "_" removed.
+var sensitiveFields = map[string]bool{
+	"authorization":      true,
+	"proxyauthorization": true,
+	"cookie":             true,
+	"setcookie":          true,
+	"password":           true,
+	"secret":             true,
+	"clientsecret":       true,
+	"token":              true,
+	"accesstoken":        true,
+	"refreshtoken":       true,
+	"apikey":             true,
+	"email":              true,
+	"phone":              true,
+	"phonenumber":        true,
+	"address":            true,
+	"counterparty":       true,
+}
+
+var sensitivePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\b(bearer|basic|apikey)\s+[A-Za-z0-9._~+/=-]+`), "$1 " + redacted},
+	{regexp.MustCompile(`\b(blk_[0-9a-f]{12})_[A-Za-z0-9_-]+`), "${1}_" + redacted},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), redacted},
+}
+
+var separators = strings.NewReplacer("_", "", "-", "")
+
+// hints are cheap substring checks against the lower-cased line with separators removed; lines
+// containing none of them are written untouched.
+var hints = []string{"@", "blk", "bearer", "basic"}
+
+func init() {
+	for field := range sensitiveFields {
+		hints = append(hints, field)
+	}
+}
+
+// redactingWriter masks sensitive fields and credentials in the JSON lines written by zerolog
+// before they reach the output.
+type redactingWriter struct {
+	out io.Writer
+}
+
+func newRedactingWriter(out io.Writer) io.Writer {
+	return &redactingWriter{out: out}
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if !mayBeSensitive(p) {
+		return w.out.Write(p)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	var line map[string]any
+	if err := decoder.Decode(&line); err != nil {
+		return w.out.Write(p)
+	}
+	redactValue(line)
+
+	out, err := json.Marshal(line)
+	if err != nil {
+		return w.out.Write(p)
+	}
+	if _, err := w.out.Write(append(out, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func mayBeSensitive(p []byte) bool {
+	line := separators.Replace(strings.ToLower(string(p)))
+	for _, hint := range hints {
+		if strings.Contains(line, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+		return v
+	case string:
+		for _, p := range sensitivePatterns {
+			v = p.pattern.ReplaceAllString(v, p.replacement)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveField(key string) bool {
+	return sensitiveFields[separators.Replace(strings.ToLower(key))]
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "banking"
+
+// Transaction outcomes counted by TransactionsTotal.
+const (
+	TransactionAccepted      = "accepted"
+	TransactionSucceeded     = "succeeded"
+	TransactionFailed        = "failed"
+	TransactionPendingReview = "pending_review"
+)
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	KafkaMessagesProduced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_messages_produced_total",
+		Help:      "Messages written to Kafka.",
+	}, []string{"topic"})
+
+	KafkaProduceErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_produce_errors_total",
+		Help:      "Failed writes to Kafka.",
+	}, []string{"topic"})
+
+	KafkaMessagesConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_messages_consumed_total",
+		Help:      "Messages fetched from Kafka.",
+	}, []string{"topic", "group"})
+
+	KafkaConsumeErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_consume_errors_total",
+		Help:      "Failed fetches, handler errors and failed commits.",
+	}, []string{"topic", "group"})
+
+	KafkaConsumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kafka_consumer_lag",
+		Help:      "Messages behind the high water mark, as of the last fetched message.",
+	}, []string{"topic", "group", "partition"})
+
+	WorkerPoolQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_pool_queue_depth",
+		Help:      "Messages fetched and waiting for a free worker.",
+	}, []string{"pool"})
+
+	WorkerPoolActiveWorkers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_pool_active_workers",
+		Help:      "Workers currently handling a message.",
+	}, []string{"pool"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database operations.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"db", "operation", "table"})
+
+	TransactionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_total",
+		Help:      "Transactions by outcome and type.",
+	}, []string{"status", "type"})
+
+	ReconciliationRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_runs_total",
+		Help:      "Reconciliation runs by mode and status.",
+	}, []string{"mode", "status"})
+
+	ReconciliationAccountsChecked = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_accounts_checked_total",
+		Help:      "Accounts whose balance was compared with the ledger.",
+	})
+
+	ReconciliationBreaks = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_breaks",
+		Help:      "Accounts whose balance disagreed with the ledger in the last completed run.",
+	})
+
+	ReconciliationBreakAmount = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_break_amount",
+		Help:      "Sum of the absolute differences found in the last completed run.",
+	})
+
+	ReconciliationLastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_last_success_timestamp_seconds",
+		Help:      "Time the last reconciliation run completed.",
+	})
+
+	AuditWriteErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_write_errors_total",
+		Help:      "Audit entries that could not be appended to the audit log.",
+	})
+)
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveQuery records the duration of a database operation.
+func ObserveQuery(db string, operation string, table string, duration time.Duration) {
+	DBQueryDuration.WithLabelValues(db, operation, table).Observe(duration.Seconds())
+}
+
+// RecordTransaction counts a transaction reaching status.
+func RecordTransaction(status string, transactionType string) {
+	TransactionsTotal.WithLabelValues(status, transactionType).Inc()
+}
+
+// RecordReconciliation records a finished reconciliation run. breaks and amount are only
+// meaningful for completed runs.
+func RecordReconciliation(mode string, status string, accountsChecked int, breaks int, amount float64) {
+	ReconciliationRuns.WithLabelValues(mode, status).Inc()
+	ReconciliationAccountsChecked.Add(float64(accountsChecked))
+	if status != "completed" {
+		return
+	}
+	ReconciliationBreaks.Set(float64(breaks))
+	ReconciliationBreakAmount.Set(amount)
+	ReconciliationLastSuccess.SetToCurrentTime()
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// WorkerPool tracks how saturated a pool of message workers is.
+type WorkerPool struct {
+	queued prometheus.Gauge
+	active prometheus.Gauge
+}
+
+func NewWorkerPool(name string) *WorkerPool {
+	return &WorkerPool{
+		queued: WorkerPoolQueueDepth.WithLabelValues(name),
+		active: WorkerPoolActiveWorkers.WithLabelValues(name),
+	}
+}
+
+// Enqueued is called before a message is handed to the pool.
+func (p *WorkerPool) Enqueued() {
+	p.queued.Inc()
+}
+
+// Started is called when a worker picks up a message.
+func (p *WorkerPool) Started() {
+	p.queued.Dec()
+	p.active.Inc()
+}
+
+// Finished is called when a worker is done with a message.
+func (p *WorkerPool) Finished() {
+	p.active.Dec()
+}
@@ -0,0 +1,76 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Header carries the request ID between clients, services and Kafka messages.
+const Header = "X-Request-ID"
+
+// maxLength bounds IDs accepted from callers so they cannot bloat every log line.
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a fresh request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether id may be accepted from a caller: non-empty, bounded, and limited to
+// characters that are safe to log and echo in headers.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// OrNew returns id when it is valid and a fresh ID otherwise.
+func OrNew(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport wraps base so that outgoing requests carry the request ID from their context.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := FromContext(req.Context()); id != "" && req.Header.Get(Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(Header, id)
+	}
+	return t.base.RoundTrip(req)
+}
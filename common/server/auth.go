@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/base64"
 	"net/http"
 	"strings"
@@ -10,7 +12,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func basicAuthMiddleware(config Config) gin.HandlerFunc {
+// APIKeyAuthenticator verifies API keys presented with the ApiKey scheme.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*Claims, error)
+}
+
+// authMiddleware accepts bearer JWTs when a validator is configured, API keys when an
+// authenticator is configured and basic auth against the shared API credentials when the
+// basic fallback is enabled.
+func authMiddleware(config Config, validator *tokenValidator) gin.HandlerFunc {
+	basicFallback := config.Auth != nil && config.Auth.BasicFallback
+
 	return func(c *gin.Context) {
 		auth := c.Request.Header.Get("Authorization")
 
@@ -19,9 +31,37 @@ func basicAuthMiddleware(config Config) gin.HandlerFunc {
 			return
 		}
 
-		providedUsername, providedPassword, ok := parseBasicAuth(auth)
-		if !ok || providedUsername != config.ApiAuth.UserName || providedPassword != config.ApiAuth.Password {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		scheme, credentials, _ := strings.Cut(auth, " ")
+		switch {
+		case strings.EqualFold(scheme, "Bearer") && validator != nil:
+			claims, err := validator.Validate(strings.TrimSpace(credentials))
+			if err != nil {
+				logger.Ctx(c.Request.Context()).Warn().Err(err).Str("path", c.Request.URL.Path).Msg("Rejected bearer token")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				return
+			}
+			SetClaims(c, claims)
+
+		case strings.EqualFold(scheme, "ApiKey") && config.APIKeys != nil:
+			claims, err := config.APIKeys.Authenticate(c.Request.Context(), strings.TrimSpace(credentials))
+			if err != nil {
+				logger.Ctx(c.Request.Context()).Warn().Err(err).Str("path", c.Request.URL.Path).Msg("Rejected API key")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				return
+			}
+			SetClaims(c, claims)
+
+		case strings.EqualFold(scheme, "Basic") && basicFallback:
+			providedUsername, providedPassword, ok := parseBasicAuth(auth)
+			if !ok || !validBasicCredentials(config, providedUsername, providedPassword) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+				return
+			}
+			// The shared dev credentials act as an admin
+			SetClaims(c, &Claims{Method: AuthMethodBasic, ClientID: providedUsername, Roles: []string{RoleAdmin}})
+
+		default:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unsupported authorization scheme"})
 			return
 		}
 
@@ -29,6 +69,15 @@ func basicAuthMiddleware(config Config) gin.HandlerFunc {
 	}
 }
 
+func validBasicCredentials(config Config, username string, password string) bool {
+	if config.ApiAuth == nil || config.ApiAuth.UserName == "" {
+		return false
+	}
+	usernameMatch := subtle.ConstantTimeCompare([]byte(username), []byte(config.ApiAuth.UserName))
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(config.ApiAuth.Password))
+	return usernameMatch&passwordMatch == 1
+}
+
 func parseBasicAuth(auth string) (string, string, bool) {
 	hparts := strings.Split(auth, " ")
 	if len(hparts) == 2 {
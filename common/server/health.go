@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout bounds each check so a hung dependency cannot hang the probe.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency is usable; a nil error means healthy.
+type HealthCheck func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// Health is the registry behind /health/live and /health/ready. Liveness checks detect a
+// process that needs restarting, such as a stuck consumer loop; readiness checks cover the
+// dependencies a service needs to handle traffic. Ready also requires every liveness check.
+type Health struct {
+	mu        sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// NewHealth returns an empty registry.
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// AddLivenessCheck registers a check that fails /health/live and /health/ready.
+func (h *Health) AddLivenessCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck registers a check that fails /health/ready only.
+func (h *Health) AddReadinessCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, namedCheck{name: name, check: check})
+}
+
+// Heartbeat registers a liveness check for a loop that must call Beat at least every maxAge.
+func (h *Health) Heartbeat(name string, maxAge time.Duration) *Heartbeat {
+	heartbeat := &Heartbeat{maxAge: maxAge}
+	// Count from registration so the loop gets maxAge to start
+	heartbeat.Beat()
+	h.AddLivenessCheck(name, heartbeat.check)
+	return heartbeat
+}
+
+// Heartbeat tracks when a background loop last made progress.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   atomic.Int64
+}
+
+// Beat records that the loop is making progress.
+func (b *Heartbeat) Beat() {
+	b.last.Store(time.Now().UnixNano())
+}
+
+func (b *Heartbeat) check(ctx context.Context) error {
+	age := time.Since(time.Unix(0, b.last.Load()))
+	if age > b.maxAge {
+		return fmt.Errorf("no heartbeat for %s", age.Round(time.Second))
+	}
+	return nil
+}
+
+// CheckResult is the outcome of one check in a health response.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthResponse is the body of /health/live and /health/ready.
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (h *Health) run(ctx context.Context, checks []namedCheck) (HealthResponse, bool) {
+	response := HealthResponse{Status: "UP", Checks: make(map[string]CheckResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	healthy := true
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(checkCtx)
+			result := CheckResult{Status: "UP", Duration: time.Since(start).Round(time.Microsecond).String()}
+			if err != nil {
+				result.Status = "DOWN"
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[c.name] = result
+			if err != nil {
+				healthy = false
+			}
+		}(c)
+	}
+	wg.Wait()
+	if !healthy {
+		response.Status = "DOWN"
+	}
+	return response, healthy
+}
+
+func (h *Health) handler(ready bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.mu.RLock()
+		checks := append([]namedCheck(nil), h.liveness...)
+		if ready {
+			checks = append(checks, h.readiness...)
+		}
+		h.mu.RUnlock()
+
+		response, healthy := h.run(c.Request.Context(), checks)
+		status := http.StatusOK
+		if !healthy {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, response)
+	}
+}
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+const (
+	claimsContextKey = "claims"
+
+	// jwksMinRefreshInterval limits refetching the JWKS URL when tokens carry unknown key IDs.
+	jwksMinRefreshInterval = time.Minute
+	// jwksMaxAge is how long keys fetched from the JWKS URL are used before refetching.
+	jwksMaxAge = time.Hour
+)
+
+// Authentication methods recorded on Claims.
+const (
+	AuthMethodBearer = "bearer"
+	AuthMethodBasic  = "basic"
+	AuthMethodAPIKey = "apikey"
+)
+
+// Claims are the verified claims of the caller, available to handlers through ClaimsFromContext.
+type Claims struct {
+	jwt.RegisteredClaims
+	Scope      string   `json:"scope,omitempty"`
+	ClientID   string   `json:"client_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	CustomerID string   `json:"customer_id,omitempty"`
+
+	Method string `json:"-"`
+	// Permissions and AccountIDs are granted directly to API keys rather than through roles.
+	Permissions []Permission `json:"-"`
+	AccountIDs  []string     `json:"-"`
+}
+
+// Principal identifies the caller: the token subject, or the client for service tokens and basic auth.
+func (c *Claims) Principal() string {
+	if c.Subject != "" {
+		return c.Subject
+	}
+	return c.ClientID
+}
+
+// HasScope reports whether the token was granted scope.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// SetClaims records the authenticated caller on the request context.
+func SetClaims(c *gin.Context, claims *Claims) {
+	c.Set(claimsContextKey, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller.
+func ClaimsFromContext(c *gin.Context) (*Claims, bool) {
+	value, ok := c.Get(claimsContextKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*Claims)
+	return claims, ok
+}
+
+type tokenValidator struct {
+	keys   *jwksKeySet
+	parser *jwt.Parser
+}
+
+// newTokenValidator verifies RS256 and ES256 tokens against the configured JWKS.
+func newTokenValidator(authConfig *config.AuthConfig) (*tokenValidator, error) {
+	keys, err := newJWKSKeySet(authConfig.JWKSFile, authConfig.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if authConfig.Issuer != "" {
+		options = append(options, jwt.WithIssuer(authConfig.Issuer))
+	}
+	if authConfig.Audience != "" {
+		options = append(options, jwt.WithAudience(authConfig.Audience))
+	}
+
+	return &tokenValidator{keys: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+func (v *tokenValidator) Validate(tokenString string) (*Claims, error) {
+	claims := &Claims{Method: AuthMethodBearer}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keys.keyFunc); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// jwksKeySet holds the verification keys by key ID, loaded from a file once or from a URL
+// and refreshed when it goes stale or a token names an unknown key.
+type jwksKeySet struct {
+	mu        sync.RWMutex
+	keys      map[string]interface{}
+	url       string
+	fetchedAt time.Time
+	client    *http.Client
+}
+
+func newJWKSKeySet(file string, url string) (*jwksKeySet, error) {
+	set := &jwksKeySet{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := parseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		set.keys = keys
+		set.url = ""
+		return set, nil
+	}
+
+	if err := set.refresh(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *jwksKeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if s.url != "" {
+		s.mu.RLock()
+		_, known := s.keys[kid]
+		age := time.Since(s.fetchedAt)
+		s.mu.RUnlock()
+
+		if age > jwksMaxAge || (!known && age > jwksMinRefreshInterval) {
+			if err := s.refresh(); err != nil {
+				logger.Log.Error().Err(err).Msg("Failed to refresh JWKS")
+			}
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	// Tokens without a key ID are accepted when the set holds a single key
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (s *jwksKeySet) refresh() error {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching JWKS: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS reads the RSA and EC signing keys of a JSON Web Key Set.
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key interface{}
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no signing keys")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() {
+		return nil, fmt.Errorf("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on curve %s", jwk.Crv)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
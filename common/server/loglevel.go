@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// logLevelRoute changes the service's log level at runtime. It has no entry in the route
+// permissions, so only admins can use it.
+const logLevelRoute = "/admin/log-level"
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+func getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logger.Level()})
+}
+
+func setLogLevel(c *gin.Context) {
+	var request logLevelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := logger.Level()
+	if err := logger.SetLevel(request.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log level"})
+		return
+	}
+
+	// Logged without a level so the change is recorded whatever the new level is
+	event := logger.Ctx(c.Request.Context()).Log().
+		Str("previous", previous).
+		Str("level", logger.Level())
+	if claims, ok := ClaimsFromContext(c); ok {
+		event = event.Str("principal", claims.Principal())
+	}
+	event.Msg("Log level changed")
+	audit.Describe(c.Request.Context(), "log-level", "", gin.H{"level": previous}, gin.H{"level": logger.Level()})
+	c.JSON(http.StatusOK, gin.H{"level": logger.Level()})
+}
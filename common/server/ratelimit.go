@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitSweepInterval is how often the in-memory store drops buckets that have refilled.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit is a token bucket refilled at Rate tokens per second up to Burst tokens.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RouteRateLimits overrides the default rate limit for "METHOD /api/v1/path" route patterns.
+type RouteRateLimits map[string]RateLimit
+
+// RateLimitResult is the state of a bucket after taking a token.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until a token is available when the request was not allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// RateLimitStore holds the token buckets. The in-memory store limits each instance on its own;
+// a shared store limits clients across all instances of a service.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   RateLimit
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore returns a RateLimitStore that keeps buckets in process memory.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= rateLimitSweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	} else {
+		b.tokens = refill(b.tokens, now.Sub(b.updated), limit)
+		b.updated = now
+	}
+	b.limit = limit
+
+	var result RateLimitResult
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return result, nil
+}
+
+// sweep drops buckets that would be full by now; they are recreated full on the next request.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if refill(b.tokens, now.Sub(b.updated), b.limit) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func refill(tokens float64, elapsed time.Duration, limit RateLimit) float64 {
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// rateLimitMiddleware limits each authenticated caller per route. It runs after authentication
+// so that buckets are keyed by principal rather than by client address. Internal services are
+// not limited: they call on behalf of every client of the calling service, so a shared bucket
+// would turn one client's traffic into failures for the others.
+func rateLimitMiddleware(limit RateLimit, routes RouteRateLimits, serviceClients map[string]bool, store RateLimitStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := ClaimsFromContext(c); ok && isServiceClient(claims, serviceClients) {
+			c.Next()
+			return
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+		routeLimit, ok := routes[route]
+		if !ok {
+			routeLimit = limit
+		}
+		if routeLimit.Rate <= 0 {
+			c.Next()
+			return
+		}
+
+		principal := c.ClientIP()
+		if claims, ok := ClaimsFromContext(c); ok {
+			principal = claims.Principal()
+		}
+
+		result, err := store.Take(c.Request.Context(), principal+"|"+route, routeLimit, time.Now())
+		if err != nil {
+			// Fail open: an unavailable store must not take the API down
+			logger.Ctx(c.Request.Context()).Error().Err(err).Str("route", route).Msg("Rate limit store failed")
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(routeLimit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			logger.Ctx(c.Request.Context()).Warn().
+				Str("principal", principal).
+				Str("route", route).
+				Str("clientIp", c.ClientIP()).
+				Msg("Rate limit exceeded")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// isServiceClient reports whether the caller is one of serviceClients: a client-credentials
+// token of one of them, or basic credentials with one of them as the username. Other basic
+// callers are limited like any other client.
+func isServiceClient(claims *Claims, serviceClients map[string]bool) bool {
+	switch claims.Method {
+	case AuthMethodBasic, AuthMethodBearer:
+		return claims.ClientID != "" && serviceClients[claims.ClientID]
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles carried in the token's roles claim.
+const (
+	RoleCustomer = "customer"
+	RoleTeller   = "teller"
+	RoleOps      = "ops"
+	RoleAuditor  = "auditor"
+	RoleAdmin    = "admin"
+)
+
+// Permission is required by an API route.
+type Permission string
+
+const (
+	PermAccountsRead       Permission = "accounts:read"
+	PermAccountsWrite      Permission = "accounts:write"
+	PermTransactionsCreate Permission = "transactions:create"
+	PermTransactionsRead   Permission = "transactions:read"
+	PermTransactionsBatch  Permission = "transactions:batch"
+	PermTransactionsImport Permission = "transactions:import"
+	PermLedgerRead         Permission = "ledger:read"
+	PermLimitsRead         Permission = "limits:read"
+	PermLimitsWrite        Permission = "limits:write"
+	PermReviewsRead        Permission = "reviews:read"
+	PermReviewsDecide      Permission = "reviews:decide"
+	PermAPIKeysManage      Permission = "apikeys:manage"
+	PermReconciliationRead Permission = "reconciliation:read"
+	PermReconciliationRun  Permission = "reconciliation:run"
+	PermAuditRead          Permission = "audit:read"
+)
+
+// Permissions lists every permission that can be granted to an API key.
+var Permissions = []Permission{
+	PermAccountsRead, PermAccountsWrite,
+	PermTransactionsCreate, PermTransactionsRead, PermTransactionsBatch, PermTransactionsImport,
+	PermLedgerRead, PermLimitsRead, PermLimitsWrite,
+	PermReviewsRead, PermReviewsDecide,
+	PermReconciliationRead, PermReconciliationRun,
+	PermAuditRead,
+}
+
+// RoutePermissions maps "METHOD /api/v1/path" route patterns to the permission they require.
+// Routes missing from the map are reserved for admins.
+type RoutePermissions map[string]Permission
+
+var rolePermissions = map[string][]Permission{
+	RoleCustomer: {PermAccountsRead, PermTransactionsCreate, PermTransactionsRead, PermLedgerRead, PermLimitsRead},
+	RoleTeller:   {PermAccountsRead, PermAccountsWrite, PermTransactionsCreate, PermTransactionsRead, PermTransactionsBatch, PermLedgerRead, PermLimitsRead},
+	RoleOps:      {PermAccountsRead, PermTransactionsRead, PermTransactionsBatch, PermTransactionsImport, PermLedgerRead, PermLimitsRead, PermLimitsWrite, PermReviewsRead, PermReviewsDecide, PermReconciliationRead, PermReconciliationRun},
+	RoleAuditor:  {PermAccountsRead, PermTransactionsRead, PermLedgerRead, PermLimitsRead, PermReviewsRead, PermReconciliationRead, PermAuditRead},
+}
+
+// HasRole reports whether the caller was granted role.
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether permission was granted to the caller directly or through any of its roles.
+func (c *Claims) Can(permission Permission) bool {
+	if c.HasRole(RoleAdmin) {
+		return true
+	}
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	for _, role := range c.Roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CustomerScope returns the customer the caller is restricted to. Callers holding only the
+// customer role may access their own accounts and ledger entries only.
+func CustomerScope(c *gin.Context) (string, bool) {
+	claims, ok := ClaimsFromContext(c)
+	if !ok {
+		return "", false
+	}
+	for _, role := range claims.Roles {
+		if role != RoleCustomer {
+			return "", false
+		}
+	}
+	if !claims.HasRole(RoleCustomer) {
+		return "", false
+	}
+	return claims.CustomerID, true
+}
+
+// CheckCustomerAccess aborts with 403 when the caller is restricted to its own data and the
+// resource belongs to another customer. It reports whether the request may proceed.
+func CheckCustomerAccess(c *gin.Context, ownerCustomerID string) bool {
+	customerID, restricted := CustomerScope(c)
+	if !restricted || (customerID != "" && customerID == ownerCustomerID) {
+		return true
+	}
+	logDenied(c, "resource belongs to another customer")
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	return false
+}
+
+// AccountScope returns the accounts the caller is restricted to. API keys may be limited to an
+// allowlist of accounts.
+func AccountScope(c *gin.Context) ([]string, bool) {
+	claims, ok := ClaimsFromContext(c)
+	if !ok || len(claims.AccountIDs) == 0 {
+		return nil, false
+	}
+	return claims.AccountIDs, true
+}
+
+// AccountAllowed reports whether the caller may access accountID.
+func AccountAllowed(c *gin.Context, accountID string) bool {
+	accountIDs, restricted := AccountScope(c)
+	if !restricted {
+		return true
+	}
+	for _, id := range accountIDs {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAccountAllowed aborts with 403 when the caller is restricted to an allowlist of accounts
+// that does not include accountID. It reports whether the request may proceed.
+func CheckAccountAllowed(c *gin.Context, accountID string) bool {
+	if AccountAllowed(c, accountID) {
+		return true
+	}
+	logDenied(c, "account is not in the caller's allowlist")
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	return false
+}
+
+// authorizeMiddleware checks the caller's roles against the permission of the matched route.
+func authorizeMiddleware(permissions RoutePermissions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := ClaimsFromContext(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthenticated"})
+			return
+		}
+
+		permission, ok := permissions[c.Request.Method+" "+c.FullPath()]
+		if ok && claims.Can(permission) || !ok && claims.HasRole(RoleAdmin) {
+			c.Next()
+			return
+		}
+
+		reason := "missing permission " + string(permission)
+		if !ok {
+			reason = "route is restricted to admins"
+		}
+		logDenied(c, reason)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	}
+}
+
+func logDenied(c *gin.Context, reason string) {
+	event := logger.Ctx(c.Request.Context()).Warn().
+		Str("method", c.Request.Method).
+		Str("path", c.Request.URL.Path).
+		Str("route", c.FullPath()).
+		Str("clientIp", c.ClientIP()).
+		Str("reason", reason)
+	if claims, ok := ClaimsFromContext(c); ok {
+		event = event.Str("principal", claims.Principal()).Strs("roles", claims.Roles).Str("customerId", claims.CustomerID)
+	}
+	event.Msg("Access denied")
+}
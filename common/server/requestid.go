@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDKey is the gin context key holding the request ID.
+const requestIDKey = "requestId"
+
+// RequestID accepts the caller's X-Request-ID or generates one, returns it in the response
+// header and carries it in the request context for logging and propagation. JSON error
+// bodies also get a "requestId" field so support can find the matching log lines.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestid.OrNew(c.GetHeader(requestid.Header))
+		c.Set(requestIDKey, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+		c.Writer = &errorBodyWriter{ResponseWriter: c.Writer, requestID: id}
+		c.Next()
+	}
+}
+
+// RequestIDFromContext returns the ID assigned to the request by RequestID.
+func RequestIDFromContext(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// errorBodyWriter adds the request ID to the JSON object written for error responses.
+type errorBodyWriter struct {
+	gin.ResponseWriter
+	requestID string
+	written   bool
+}
+
+func (w *errorBodyWriter) Write(data []byte) (int, error) {
+	if w.written || w.Status() < http.StatusBadRequest || !isJSON(w.Header().Get("Content-Type")) {
+		w.written = true
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+
+	body, ok := withRequestID(data, w.requestID)
+	if !ok {
+		return w.ResponseWriter.Write(data)
+	}
+	if _, err := w.ResponseWriter.Write(body); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *errorBodyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func isJSON(contentType string) bool {
+	return len(contentType) >= len(gin.MIMEJSON) && contentType[:len(gin.MIMEJSON)] == gin.MIMEJSON
+}
+
+// withRequestID prepends a requestId field to a JSON object unless it already has one.
+func withRequestID(data []byte, id string) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) < 2 || trimmed[0] != '{' || trimmed[len(trimmed)-1] != '}' {
+		return nil, false
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, false
+	}
+	if _, ok := object[requestIDKey]; ok {
+		return nil, false
+	}
+
+	field, _ := json.Marshal(id)
+	var body bytes.Buffer
+	body.WriteString(`{"` + requestIDKey + `":`)
+	body.Write(field)
+	if len(object) > 0 {
+		body.WriteByte(',')
+		body.Write(trimmed[1:])
+	} else {
+		body.WriteByte('}')
+	}
+	return body.Bytes(), true
+}
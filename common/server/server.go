@@ -3,13 +3,14 @@ package server
 import (
 	"context"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/shrishyam02/banking-ledger/common/audit"
 	"github.com/shrishyam02/banking-ledger/common/config"
 	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,26 +20,88 @@ type Config struct {
 	Port        string
 	ServiceName string
 	ApiAuth     *config.ApiAuth
+	Auth        *config.AuthConfig
+	Permissions RoutePermissions
+	APIKeys     APIKeyAuthenticator
+	RateLimit   *config.RateLimitConfig
+	// RouteRateLimits overrides RateLimit for individual routes.
+	RouteRateLimits RouteRateLimits
+	// RateLimitStore defaults to an in-memory store.
+	RateLimitStore RateLimitStore
+	// Health holds the checks behind /health/live and /health/ready.
+	Health *Health
+	// ShutdownTimeout bounds draining in-flight requests; it defaults to 5 seconds.
+	ShutdownTimeout time.Duration
+	// Audit records the mutating API calls of everyone but customers acting for themselves when set.
+	Audit audit.Recorder
 }
 
 // HandlerRegistrationFunc ...
 type HandlerRegistrationFunc func(router *gin.RouterGroup)
 
-// RunServer ...
+// RunServer serves the API until ctx is cancelled, then drains in-flight requests.
 func RunServer(ctx context.Context, config Config, registerHandlers HandlerRegistrationFunc) {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
+	// Handlers pass the gin context on as a context.Context; let it expose the request's trace
+	router.ContextWithFallback = true
 
 	router.Use(gin.Recovery())
 
-	router.Use(RequestLogger())
+	router.Use(RequestID(), RequestLogger(), requestMetrics())
 
-	router.GET("/health", healthCheckHandler)
+	health := config.Health
+	if health == nil {
+		health = NewHealth()
+	}
+	router.GET("/health", health.handler(false))
+	router.GET("/health/live", health.handler(false))
+	router.GET("/health/ready", health.handler(true))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	if registerHandlers != nil {
+		var validator *tokenValidator
+		if config.Auth != nil && (config.Auth.JWKSFile != "" || config.Auth.JWKSURL != "") {
+			var err error
+			if validator, err = newTokenValidator(config.Auth); err != nil {
+				logger.Log.Fatal().Err(err).Msgf("Failed to load JWKS for %s", config.ServiceName)
+			}
+		} else if config.APIKeys == nil && (config.Auth == nil || !config.Auth.BasicFallback) {
+			logger.Log.Fatal().Msgf("No authentication configured for %s: set AUTH_JWKS_FILE, AUTH_JWKS_URL or AUTH_BASIC_FALLBACK", config.ServiceName)
+		} else {
+			logger.Log.Warn().Msgf("%s has no JWKS configured; bearer tokens are rejected", config.ServiceName)
+		}
+
+		var defaultLimit RateLimit
+		serviceClients := make(map[string]bool)
+		if config.RateLimit != nil {
+			defaultLimit = RateLimit{Rate: config.RateLimit.RequestsPerSecond, Burst: config.RateLimit.Burst}
+			for _, clientID := range config.RateLimit.ServiceClients {
+				serviceClients[strings.TrimSpace(clientID)] = true
+			}
+		}
+		// The services call each other with the client they were configured with
+		if config.Auth != nil && config.Auth.ClientID != "" {
+			serviceClients[config.Auth.ClientID] = true
+		}
+		store := config.RateLimitStore
+		if store == nil {
+			store = NewMemoryRateLimitStore()
+		}
+
 		apiGroup := router.Group("/api/v1")
-		apiGroup.Use(basicAuthMiddleware(config))
+		apiGroup.Use(
+			traceRequests(),
+			authMiddleware(config, validator),
+			rateLimitMiddleware(defaultLimit, config.RouteRateLimits, serviceClients, store),
+			authorizeMiddleware(config.Permissions),
+		)
+		if config.Audit != nil {
+			apiGroup.Use(auditMiddleware(config.Audit))
+		}
+		apiGroup.GET(logLevelRoute, getLogLevel)
+		apiGroup.PUT(logLevelRoute, setLogLevel)
 		registerHandlers(apiGroup)
 		logger.Log.Info().Msgf("Registering api group: %v", config.ServiceName)
 	}
@@ -56,12 +119,14 @@ func RunServer(ctx context.Context, config Config, registerHandlers HandlerRegis
 	}()
 	logger.Log.Info().Msgf("Server started: %v", config.ServiceName)
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	<-ctx.Done()
 	logger.Log.Info().Msgf("Shutting down %s server...", config.ServiceName)
 
-	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeout := config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
@@ -83,7 +148,7 @@ func RequestLogger() gin.HandlerFunc {
 		method := c.Request.Method
 		path := c.Request.URL.Path
 
-		logger.Log.Info().
+		logger.Ctx(c.Request.Context()).Info().
 			Int("status", statusCode).
 			Str("method", method).
 			Str("path", path).
@@ -92,8 +157,19 @@ func RequestLogger() gin.HandlerFunc {
 	}
 }
 
-func healthCheckHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "UP",
-	})
+// requestMetrics records request durations by route pattern, keeping label cardinality bounded.
+func requestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
 }
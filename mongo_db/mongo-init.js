@@ -4,10 +4,16 @@ db = db.getSiblingDB("banking_ledger_db");
 db.createUser({
   user: "ledger",
   pwd: "ledger",
+  // dbAdmin lets the ledger service apply its schema validator (collMod) and indexes at startup.
+  // Existing deployments need: db.grantRolesToUser("ledger", [{ role: "dbAdmin", db: "banking_ledger_db" }])
   roles: [
     {
       role: "readWrite",
       db: "banking_ledger_db"
+    },
+    {
+      role: "dbAdmin",
+      db: "banking_ledger_db"
     }
   ]
 });
@@ -37,10 +43,4 @@ db.transactions.insertMany([
   }
 ], { ordered: false });
 
-db.transactions.createIndex( { id: 1 }, { unique: true, sparse: true} );
-db.transactions.createIndex( { accountId: 1 } );
-db.transactions.createIndex( { acceptedAt: 1 } );
-
-// Optionally create a capped collection for transaction logs that automatically removes older entries after a certain size or time limit is reached.
-// Useful if you only need to keep a limited history of transactions.
-db.createCollection("transactions", { capped: true, size: 104857600, max: 100000 }); // 100MB size limit, 100000 documents max. Adjust as needed.
\ No newline at end of file
+// Indexes and the schema validator of the transactions collection are applied by the ledger service at startup.
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"account/model"
@@ -8,6 +9,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
 )
 
 type accountHandler struct {
@@ -33,15 +37,19 @@ func (h *accountHandler) CreateAccount(c *gin.Context) {
 	if account.CustomerID == uuid.Nil {
 		account.CustomerID = uuid.New()
 	}
-	if err := h.service.CreateOrUpdateCustomer(&account.Customer); err != nil {
+	previous, err := h.service.CreateOrUpdateCustomer(&account.Customer)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	ctx := c.Request.Context()
+	audit.Describe(ctx, "customer", account.Customer.ID.String(), previous, account.Customer)
 	account.Status = "active"
 	if err := h.service.CreateAccount(&account); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit.Describe(ctx, "account", account.ID.String(), nil, account)
 	c.JSON(http.StatusOK, account)
 }
 
@@ -56,14 +64,71 @@ func (h *accountHandler) GetAccount(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
 		return
 	}
+	if !server.CheckCustomerAccess(c, account.CustomerID.String()) || !server.CheckAccountAllowed(c, account.ID.String()) {
+		return
+	}
 	c.JSON(http.StatusOK, account)
 }
 
+// ListAccounts lists the caller's accounts; the number query parameter narrows the list to
+// the account with that account number or IBAN.
 func (h *accountHandler) ListAccounts(c *gin.Context) {
+	if number := c.Query("number"); number != "" {
+		h.findAccount(c, number)
+		return
+	}
+
+	// Customers only see their own accounts
+	if customerID, restricted := server.CustomerScope(c); restricted {
+		id, err := uuid.Parse(customerID)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		accounts, err := h.service.ListAccountsByCustomer(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, allowedAccounts(c, accounts))
+		return
+	}
+
 	accounts, err := h.service.ListAccounts()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, accounts)
+	c.JSON(http.StatusOK, allowedAccounts(c, accounts))
+}
+
+func (h *accountHandler) findAccount(c *gin.Context, number string) {
+	account, err := h.service.FindAccountByNumber(number)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusOK, []model.Account{})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if customerID, restricted := server.CustomerScope(c); restricted && customerID != account.CustomerID.String() {
+		c.JSON(http.StatusOK, []model.Account{})
+		return
+	}
+	c.JSON(http.StatusOK, allowedAccounts(c, []model.Account{*account}))
+}
+
+// allowedAccounts drops the accounts outside the allowlist of the caller's API key.
+func allowedAccounts(c *gin.Context, accounts []model.Account) []model.Account {
+	if _, restricted := server.AccountScope(c); !restricted {
+		return accounts
+	}
+	allowed := make([]model.Account, 0, len(accounts))
+	for _, account := range accounts {
+		if server.AccountAllowed(c, account.ID.String()) {
+			allowed = append(allowed, account)
+		}
+	}
+	return allowed
 }
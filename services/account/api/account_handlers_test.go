@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,8 +13,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockAccountService struct {
@@ -35,9 +39,25 @@ func (m *MockAccountService) ListAccounts() ([]model.Account, error) {
 	return args.Get(0).([]model.Account), args.Error(1)
 }
 
-func (m *MockAccountService) CreateOrUpdateCustomer(customer *model.Customer) error {
+func (m *MockAccountService) ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error) {
+	args := m.Called(customerID)
+	return args.Get(0).([]model.Account), args.Error(1)
+}
+
+func (m *MockAccountService) FindAccountByNumber(number string) (*model.Account, error) {
+	args := m.Called(number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Account), args.Error(1)
+}
+
+func (m *MockAccountService) CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error) {
 	args := m.Called(customer)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
 func (m *MockAccountService) UpdateAccountBalance(ctx context.Context, id string, balance float64, currency string) error {
@@ -67,6 +87,36 @@ func TestCreateAccount(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestCreateAccount_DescribesCustomerWhenAccountFails(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/accounts", handler.CreateAccount)
+
+	customerID := uuid.New()
+	previous := &model.Customer{ID: customerID, Name: "Old Name"}
+	mockService.On("CreateOrUpdateCustomer", mock.Anything).Return(previous, nil)
+	mockService.On("CreateAccount", mock.Anything).Return(errors.New("duplicate account number"))
+
+	body, _ := json.Marshal(model.Account{AccountNumber: "12345", CustomerID: customerID, Customer: model.Customer{ID: customerID, Name: "New Name"}})
+	subjects := &audit.Subjects{}
+	req, _ := http.NewRequest(http.MethodPost, "/accounts", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(audit.NewContext(req.Context(), subjects))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// The customer was saved before the account failed, so the change must still be audited
+	described := subjects.List()
+	assert.Len(t, described, 1)
+	assert.Equal(t, "customer", described[0].EntityType)
+	assert.Equal(t, customerID.String(), described[0].EntityID)
+}
+
 func TestGetAccount(t *testing.T) {
 	mockService := new(MockAccountService)
 	handler := NewAccountHandler(mockService)
@@ -109,3 +159,91 @@ func TestListAccounts(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 	mockService.AssertExpectations(t)
 }
+
+func asCustomer(customerID uuid.UUID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		server.SetClaims(c, &server.Claims{Roles: []string{server.RoleCustomer}, CustomerID: customerID.String()})
+		c.Next()
+	}
+}
+
+func TestFindAccountByNumber(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/accounts", handler.ListAccounts)
+
+	iban := "DE89370400440532013000"
+	mockService.On("FindAccountByNumber", iban).Return(&model.Account{ID: uuid.New(), AccountNumber: "123456", IBAN: &iban}, nil)
+	mockService.On("FindAccountByNumber", "999").Return(nil, gorm.ErrRecordNotFound)
+
+	tests := []struct {
+		name   string
+		number string
+		count  int
+	}{
+		{"found", iban, 1},
+		{"not found", "999", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/accounts?number="+tt.number, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var accounts []model.Account
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &accounts))
+			assert.Len(t, accounts, tt.count)
+		})
+	}
+	mockService.AssertNotCalled(t, "ListAccounts")
+}
+
+func TestCustomerAccountAccess(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+	customerID := uuid.New()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(asCustomer(customerID))
+	router.GET("/accounts", handler.ListAccounts)
+	router.GET("/accounts/:id", handler.GetAccount)
+
+	t.Run("lists only the customer's accounts", func(t *testing.T) {
+		mockService.On("ListAccountsByCustomer", customerID).Return([]model.Account{{ID: uuid.New(), CustomerID: customerID}}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/accounts", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockService.AssertNotCalled(t, "ListAccounts")
+	})
+
+	t.Run("reads its own account", func(t *testing.T) {
+		account := model.Account{ID: uuid.New(), CustomerID: customerID}
+		mockService.On("GetAccountByID", account.ID).Return(&account, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/accounts/"+account.ID.String(), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("is denied another customer's account", func(t *testing.T) {
+		account := model.Account{ID: uuid.New(), CustomerID: uuid.New()}
+		mockService.On("GetAccountByID", account.ID).Return(&account, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/accounts/"+account.ID.String(), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+}
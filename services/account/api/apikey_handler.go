@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/apikey"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
+)
+
+// defaultRotationOverlap keeps the replaced key working while clients roll out the new one.
+const defaultRotationOverlap = 24 * time.Hour
+
+type apiKeyHandler struct {
+	service apikey.Service
+}
+
+type APIKeyHandler interface {
+	CreateKey(c *gin.Context)
+	ListKeys(c *gin.Context)
+	GetKey(c *gin.Context)
+	RotateKey(c *gin.Context)
+	RevokeKey(c *gin.Context)
+}
+
+type apiKeyRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Permissions []server.Permission `json:"permissions" binding:"required"`
+	AccountIDs  []string            `json:"accountIds"`
+	ExpiresAt   *time.Time          `json:"expiresAt"`
+}
+
+type rotateRequest struct {
+	Overlap string `json:"overlap"`
+}
+
+func NewAPIKeyHandler(service apikey.Service) APIKeyHandler {
+	return &apiKeyHandler{service: service}
+}
+
+func (h *apiKeyHandler) CreateKey(c *gin.Context) {
+	var request apiKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresAt must be in the future"})
+		return
+	}
+
+	key := apikey.APIKey{
+		Name:        request.Name,
+		Permissions: request.Permissions,
+		AccountIDs:  request.AccountIDs,
+		ExpiresAt:   request.ExpiresAt,
+		CreatedBy:   principal(c),
+	}
+	if err := key.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	plaintext, err := h.service.CreateKey(c.Request.Context(), &key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit.Describe(c.Request.Context(), "apikey", key.ID.String(), nil, key)
+	c.JSON(http.StatusCreated, gin.H{"apiKey": key, "key": plaintext})
+}
+
+func (h *apiKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.service.ListKeys(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *apiKeyHandler) GetKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+	key, err := h.service.GetKey(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *apiKeyHandler) RotateKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+	var request rotateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	overlap := defaultRotationOverlap
+	if request.Overlap != "" {
+		if overlap, err = time.ParseDuration(request.Overlap); err != nil || overlap < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid overlap"})
+			return
+		}
+	}
+
+	key, plaintext, err := h.service.RotateKey(c.Request.Context(), id, overlap, principal(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	audit.Describe(c.Request.Context(), "apikey", key.ID.String(), nil, key)
+	c.JSON(http.StatusCreated, gin.H{"apiKey": key, "key": plaintext})
+}
+
+func (h *apiKeyHandler) RevokeKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+	key, err := h.service.RevokeKey(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	active := *key
+	active.RevokedAt = nil
+	audit.Describe(c.Request.Context(), "apikey", key.ID.String(), &active, key)
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *apiKeyHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, apikey.ErrKeyInactive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func principal(c *gin.Context) string {
+	if claims, ok := server.ClaimsFromContext(c); ok {
+		return claims.Principal()
+	}
+	return ""
+}
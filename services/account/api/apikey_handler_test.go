@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"account/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/apikey"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, key string) (*server.Claims, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*server.Claims), args.Error(1)
+}
+
+func (m *MockAPIKeyService) CreateKey(ctx context.Context, key *apikey.APIKey) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetKey(ctx context.Context, id uuid.UUID) (*apikey.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) ListKeys(ctx context.Context) ([]apikey.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]apikey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeKey(ctx context.Context, id uuid.UUID) (*apikey.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RotateKey(ctx context.Context, id uuid.UUID, overlap time.Duration, createdBy string) (*apikey.APIKey, string, error) {
+	args := m.Called(ctx, id, overlap, createdBy)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*apikey.APIKey), args.String(1), args.Error(2)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAPIKeyService)
+	handler := NewAPIKeyHandler(mockService)
+	router := gin.Default()
+	router.POST("/apikeys", handler.CreateKey)
+
+	mockService.On("CreateKey", mock.Anything, mock.MatchedBy(func(key *apikey.APIKey) bool {
+		return key.Name == "partner" && len(key.Permissions) == 1
+	})).Return("blk_0123456789ab_secret", nil)
+
+	tests := []struct {
+		name string
+		body map[string]any
+		code int
+	}{
+		{"created", map[string]any{"name": "partner", "permissions": []string{"ledger:read"}}, http.StatusCreated},
+		{"unknown permission", map[string]any{"name": "partner", "permissions": []string{"apikeys:manage"}}, http.StatusBadRequest},
+		{"allowlist with unscoped permission", map[string]any{"name": "partner", "permissions": []string{"reviews:decide"}, "accountIds": []string{uuid.New().String()}}, http.StatusBadRequest},
+		{"expired", map[string]any{"name": "partner", "permissions": []string{"ledger:read"}, "expiresAt": time.Now().Add(-time.Hour)}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/apikeys", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+			if tt.code == http.StatusCreated {
+				var response map[string]any
+				json.Unmarshal(resp.Body.Bytes(), &response)
+				assert.Equal(t, "blk_0123456789ab_secret", response["key"])
+			}
+		})
+	}
+	mockService.AssertNumberOfCalls(t, "CreateKey", 1)
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAPIKeyService)
+	handler := NewAPIKeyHandler(mockService)
+	router := gin.Default()
+	router.POST("/apikeys/:id/rotate", handler.RotateKey)
+
+	id := uuid.New()
+	revokedID := uuid.New()
+	mockService.On("RotateKey", mock.Anything, id, time.Hour, "").Return(&apikey.APIKey{ID: uuid.New()}, "blk_new_secret", nil)
+	mockService.On("RotateKey", mock.Anything, revokedID, defaultRotationOverlap, "").Return(nil, "", apikey.ErrKeyInactive)
+
+	req, _ := http.NewRequest(http.MethodPost, "/apikeys/"+id.String()+"/rotate", bytes.NewBufferString(`{"overlap":"1h"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/apikeys/"+revokedID.String()+"/rotate", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAPIKeyService)
+	handler := NewAPIKeyHandler(mockService)
+	router := gin.Default()
+	router.DELETE("/apikeys/:id", handler.RevokeKey)
+
+	id := uuid.New()
+	mockService.On("RevokeKey", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/apikeys/"+id.String(), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestRevokeAPIKey_DescribesChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAPIKeyService)
+	handler := NewAPIKeyHandler(mockService)
+	router := gin.Default()
+	router.DELETE("/apikeys/:id", handler.RevokeKey)
+
+	revokedAt := time.Now().UTC()
+	key := &apikey.APIKey{ID: uuid.New(), Name: "batch uploads", RevokedAt: &revokedAt}
+	mockService.On("RevokeKey", mock.Anything, key.ID).Return(key, nil)
+
+	subjects := &audit.Subjects{}
+	req, _ := http.NewRequest(http.MethodDelete, "/apikeys/"+key.ID.String(), nil)
+	req = req.WithContext(audit.NewContext(req.Context(), subjects))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	described := subjects.List()
+	assert.Len(t, described, 1)
+	assert.Equal(t, key.ID.String(), described[0].EntityID)
+	assert.Len(t, described[0].Changes, 1)
+	assert.Nil(t, described[0].Changes["revokedAt"].From)
+}
+
+func TestListAccounts_APIKeyAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+
+	allowed := model.Account{ID: uuid.New()}
+	other := model.Account{ID: uuid.New()}
+	mockService.On("ListAccounts").Return([]model.Account{allowed, other}, nil)
+	mockService.On("GetAccountByID", other.ID).Return(&other, nil)
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		server.SetClaims(c, &server.Claims{Method: server.AuthMethodAPIKey, Permissions: []server.Permission{server.PermAccountsRead}, AccountIDs: []string{allowed.ID.String()}})
+		c.Next()
+	})
+	router.GET("/accounts", handler.ListAccounts)
+	router.GET("/accounts/:id", handler.GetAccount)
+
+	req, _ := http.NewRequest(http.MethodGet, "/accounts", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var accounts []model.Account
+	json.Unmarshal(resp.Body.Bytes(), &accounts)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, allowed.ID, accounts[0].ID)
+
+	req, _ = http.NewRequest(http.MethodGet, "/accounts/"+other.ID.String(), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+)
+
+type auditHandler struct {
+	log audit.Log
+}
+
+type AuditHandler interface {
+	ListEntries(c *gin.Context)
+	VerifyChain(c *gin.Context)
+}
+
+func NewAuditHandler(log audit.Log) AuditHandler {
+	return &auditHandler{log: log}
+}
+
+// ListEntries lists audit entries newest first, narrowed by entityType, entityId, actor and a
+// from/to time range. Pages continue with before set to the seq of the last entry returned.
+func (h *auditHandler) ListEntries(c *gin.Context) {
+	filter := audit.Filter{
+		EntityType: c.Query("entityType"),
+		EntityID:   c.Query("entityId"),
+		Actor:      c.Query("actor"),
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " time"})
+				return
+			}
+			*target = &parsed
+		}
+	}
+	if value := c.Query("before"); value != "" {
+		before, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || before <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before"})
+			return
+		}
+		filter.BeforeSeq = before
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.log.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// VerifyChain recomputes the hash chain and reports the first entry that does not match.
+func (h *auditHandler) VerifyChain(c *gin.Context) {
+	result, err := h.log.Verify(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditLog struct {
+	mock.Mock
+}
+
+func (m *MockAuditLog) Record(ctx context.Context, entry *audit.Entry) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *MockAuditLog) List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditLog) Verify(ctx context.Context) (*audit.Verification, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*audit.Verification), args.Error(1)
+}
+
+func TestListAuditEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLog := new(MockAuditLog)
+	handler := NewAuditHandler(mockLog)
+	router := gin.Default()
+	router.GET("/audit", handler.ListEntries)
+
+	t.Run("should pass the filter on", func(t *testing.T) {
+		mockLog.On("List", mock.Anything, mock.MatchedBy(func(filter audit.Filter) bool {
+			return filter.EntityType == "customer" && filter.EntityID == "c-1" && filter.Actor == "alice" &&
+				filter.From != nil && filter.To == nil && filter.BeforeSeq == 40 && filter.Limit == 10
+		})).Return([]audit.Entry{{Seq: 39, Action: "POST /api/v1/accounts"}}, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/audit?entityType=customer&entityId=c-1&actor=alice&from=2026-10-01T00:00:00Z&before=40&limit=10", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"seq":39`)
+		mockLog.AssertExpectations(t)
+	})
+
+	t.Run("should return 400 for an invalid time or page", func(t *testing.T) {
+		for _, query := range []string{"from=yesterday", "before=-1", "limit=x"} {
+			req, _ := http.NewRequest(http.MethodGet, "/audit?"+query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+		}
+	})
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLog := new(MockAuditLog)
+	handler := NewAuditHandler(mockLog)
+	router := gin.Default()
+	router.GET("/audit/verify", handler.VerifyChain)
+
+	mockLog.On("Verify", mock.Anything).Return(&audit.Verification{Entries: 12, BrokenAt: 7, Reason: "hash does not match the entry's content"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/audit/verify", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"valid":false`)
+	assert.Contains(t, resp.Body.String(), `"brokenAt":7`)
+}
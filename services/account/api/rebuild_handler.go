@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"account/model"
+	"account/repository"
+	"account/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"gorm.io/gorm"
+)
+
+type rebuildHandler struct {
+	service service.RebuildService
+}
+
+type RebuildHandler interface {
+	RebuildBalance(c *gin.Context)
+	ListRebuilds(c *gin.Context)
+}
+
+// rebuildRequest previews the rebuild unless Apply is set. ExpectedVersion is the current
+// version shown by the preview, so a rebuild is only applied to the state that was reviewed.
+type rebuildRequest struct {
+	Apply           bool   `json:"apply"`
+	ExpectedVersion *int64 `json:"expectedVersion"`
+	Reason          string `json:"reason"`
+}
+
+func NewRebuildHandler(service service.RebuildService) RebuildHandler {
+	return &rebuildHandler{service: service}
+}
+
+// RebuildBalance recomputes an account's balance and version from its ledger events.
+func (h *rebuildHandler) RebuildBalance(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+	var request rebuildRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var result *model.BalanceRebuildResult
+	if request.Apply {
+		if request.ExpectedVersion == nil || request.Reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expectedVersion and reason are required to apply a rebuild"})
+			return
+		}
+		result, err = h.service.Apply(c.Request.Context(), id, *request.ExpectedVersion, principal(c), request.Reason)
+	} else {
+		audit.Ignore(c.Request.Context())
+		result, err = h.service.Preview(c.Request.Context(), id)
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	case errors.Is(err, repository.ErrAccountChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		if result.Applied != nil {
+			audit.Describe(c.Request.Context(), "account", id.String(),
+				gin.H{"Balance": result.CurrentBalance, "Version": result.CurrentVersion},
+				gin.H{"Balance": result.RebuiltBalance, "Version": result.RebuiltVersion})
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// ListRebuilds returns the audit records of the rebuilds applied to an account.
+func (h *rebuildHandler) ListRebuilds(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+	rebuilds, err := h.service.ListRebuilds(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rebuilds)
+}
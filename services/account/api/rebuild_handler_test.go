@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"account/model"
+	"account/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockRebuildService struct {
+	mock.Mock
+}
+
+func (m *MockRebuildService) Preview(ctx context.Context, accountID uuid.UUID) (*model.BalanceRebuildResult, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BalanceRebuildResult), args.Error(1)
+}
+
+func (m *MockRebuildService) Apply(ctx context.Context, accountID uuid.UUID, expectedVersion int64, appliedBy string, reason string) (*model.BalanceRebuildResult, error) {
+	args := m.Called(ctx, accountID, expectedVersion, appliedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BalanceRebuildResult), args.Error(1)
+}
+
+func (m *MockRebuildService) ListRebuilds(ctx context.Context, accountID uuid.UUID) ([]model.BalanceRebuild, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.BalanceRebuild), args.Error(1)
+}
+
+func TestRebuildBalance(t *testing.T) {
+	mockService := new(MockRebuildService)
+	handler := NewRebuildHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/accounts/:id/rebuild-balance", handler.RebuildBalance)
+
+	accountID, changedID, missingID := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("Preview", mock.Anything, accountID).Return(&model.BalanceRebuildResult{AccountID: accountID}, nil)
+	mockService.On("Preview", mock.Anything, missingID).Return(nil, gorm.ErrRecordNotFound)
+	mockService.On("Apply", mock.Anything, accountID, int64(3), "", "fix").Return(&model.BalanceRebuildResult{AccountID: accountID}, nil)
+	mockService.On("Apply", mock.Anything, changedID, int64(3), "", "fix").Return(nil, repository.ErrAccountChanged)
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+		code int
+	}{
+		{"dry run", accountID.String(), "", http.StatusOK},
+		{"apply", accountID.String(), `{"apply": true, "expectedVersion": 3, "reason": "fix"}`, http.StatusOK},
+		{"apply without expected version", accountID.String(), `{"apply": true, "reason": "fix"}`, http.StatusBadRequest},
+		{"account changed", changedID.String(), `{"apply": true, "expectedVersion": 3, "reason": "fix"}`, http.StatusConflict},
+		{"account not found", missingID.String(), "", http.StatusNotFound},
+		{"invalid id", "abc", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/accounts/"+tt.id+"/rebuild-balance", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
@@ -4,32 +4,60 @@ import (
 	"account/api"
 	"account/processor"
 	"account/repository"
+	"account/scheduler"
 	"account/service"
 	"context"
 	"log"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/shrishyam02/banking-ledger/common/apikey"
+	"github.com/shrishyam02/banking-ledger/common/app"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/auth"
 	"github.com/shrishyam02/banking-ledger/common/config"
 	"github.com/shrishyam02/banking-ledger/common/db"
 	"github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/shrishyam02/banking-ledger/common/logger"
 	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
 )
 
 func main() {
-	logger.InitLogger()
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	logger.InitLogger(logger.Options{
+		Service: config.AccountService,
+		Version: cfg.Logging.Version,
+		Level:   cfg.Services[config.AccountService].LogLevel,
+		Format:  cfg.Logging.Format,
+	})
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), cfg, os.Args[2:]); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Migration failed")
+		}
+		return
+	}
 	logger.Log.Info().Msg("Intialized logger for service: " + config.AccountService)
 
+	shutdownTracing, err := tracing.Init(context.Background(), config.AccountService, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	lifecycle := app.NewLifecycle(config.AccountService, cfg.Lifecycle.ShutdownTimeout)
+	lifecycle.OnStop("tracing", shutdownTracing)
+
 	brokers := strings.Split(cfg.Kafka.Brokers, ",")
 	consumerTopics := []string{"account-balance-updates-topic"}
 	consumerGroup := "account-group"
 	producerTopics := []string{"transactions-status-topic"}
+	interestTopic := "transactions-topic"
 
 	for key, topic := range consumerTopics {
 		logger.Log.Info().Msgf("Key: %d Topic: %s kafka broker %s", key, topic, brokers[0])
@@ -49,42 +77,130 @@ func main() {
 		logger.Log.Info().Msgf("kafka broker string slice %s", brokers)
 	}
 
-	consumer := kafka.NewKafkaConsumer(brokers, consumerGroup, consumerTopics)
+	if kerr := kafka.CreateKafkaTopic(brokers[0], interestTopic); kerr != nil {
+		logger.Log.Fatal().AnErr("Failed to create Kafka topic", kerr).Msg(interestTopic)
+	}
+
+	health := server.NewHealth()
+	consumer := kafka.NewKafkaConsumer(brokers, consumerGroup, consumerTopics,
+		kafka.WithHeartbeat(health.Heartbeat("account-balance-updates-consumer", time.Minute)))
 	producer := kafka.NewKafkaProducer(brokers)
+	lifecycle.OnStop("kafka consumer", func(context.Context) error { return consumer.Close() })
+	lifecycle.OnStop("kafka producer", func(context.Context) error { return producer.Close() })
 
 	pgDb, err := db.ConnectPostgres(cfg.Database.PostgresConnectionString)
 	if err != nil {
 		logger.Log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
 	logger.Log.Info().Msg("Connected to postgres: " + config.AccountService)
+	if err := db.CheckSchemaVersion(context.Background(), pgDb); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Database schema is older than this build; run `account migrate up`")
+	}
+	lifecycle.OnStop("postgres", func(context.Context) error { return db.ClosePostgres(pgDb) })
+	health.AddReadinessCheck("postgres", db.PostgresHealthCheck(pgDb))
+	health.AddReadinessCheck("kafka", kafka.BrokerHealthCheck(brokers))
 
 	accountRepo := repository.NewAccountRepository(pgDb)
 	accountService := service.NewService(accountRepo)
 	accountHandler := api.NewAccountHandler(accountService)
+	apiKeyService := apikey.NewService(apikey.NewRepository(pgDb))
+	apiKeyHandler := api.NewAPIKeyHandler(apiKeyService)
+	auditLog := audit.NewLog(pgDb, config.AccountService)
+	auditHandler := api.NewAuditHandler(auditLog)
 	accProcessor := processor.NewProcessor(consumer, producer, consumerTopics, producerTopics, consumerGroup, accountService)
 
+	rateTables, err := service.LoadInterestRateTables(os.Getenv("INTEREST_RATES_FILE"))
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to load interest rate tables")
+	}
+	interestRunAt, err := time.ParseDuration(getEnv("INTEREST_ACCRUAL_RUN_AT", "15m"))
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Invalid INTEREST_ACCRUAL_RUN_AT")
+	}
+	// Balances are rebuilt from, and interest accrued on, the entries recorded by the ledger service
+	var ledgerService service.LedgerService
+	var rebuildHandler api.RebuildHandler
+	if ledgerServiceURL := os.Getenv("LEDGER_SERVICE_URL"); ledgerServiceURL != "" {
+		ledgerService = service.NewLedgerService(ledgerServiceURL, auth.NewClient(context.Background(), cfg.Auth, cfg.ApiAuth))
+		rebuildService := service.NewRebuildService(accountRepo, repository.NewRebuildRepository(pgDb), ledgerService)
+		rebuildHandler = api.NewRebuildHandler(rebuildService)
+	}
+
+	interestRepo := repository.NewInterestRepository(pgDb)
+	interestService := service.NewInterestService(interestRepo, ledgerService, producer, interestTopic, rateTables)
+	interestScheduler := scheduler.NewInterestScheduler(interestService, interestRunAt)
+
 	registerHandlers := func(apiGroup *gin.RouterGroup) {
 		accounts := apiGroup.Group("/accounts")
 		{
 			accounts.POST("", accountHandler.CreateAccount)
 			accounts.GET("", accountHandler.ListAccounts)
 			accounts.GET("/:id", accountHandler.GetAccount)
+			// Admins only: the routes are not listed in Permissions
+			if rebuildHandler != nil {
+				accounts.POST("/:id/rebuild-balance", rebuildHandler.RebuildBalance)
+				accounts.GET("/:id/rebuilds", rebuildHandler.ListRebuilds)
+			}
+		}
+		apiKeys := apiGroup.Group("/apikeys")
+		{
+			apiKeys.POST("", apiKeyHandler.CreateKey)
+			apiKeys.GET("", apiKeyHandler.ListKeys)
+			apiKeys.GET("/:id", apiKeyHandler.GetKey)
+			apiKeys.POST("/:id/rotate", apiKeyHandler.RotateKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeKey)
+		}
+		auditEntries := apiGroup.Group("/audit")
+		{
+			auditEntries.GET("", auditHandler.ListEntries)
+			auditEntries.GET("/verify", auditHandler.VerifyChain)
 		}
 	}
 	logger.Log.Info().Msg("Handlers for: " + config.AccountService)
 
 	serverConfig := server.Config{
-		Port:        cfg.Services[config.AccountService].Port,
-		ServiceName: config.AccountService,
-		ApiAuth:     cfg.ApiAuth,
+		Port:            cfg.Services[config.AccountService].Port,
+		ServiceName:     config.AccountService,
+		ApiAuth:         cfg.ApiAuth,
+		Auth:            cfg.Auth,
+		RateLimit:       cfg.RateLimit,
+		Health:          health,
+		ShutdownTimeout: cfg.Lifecycle.ShutdownTimeout,
+		Permissions: server.RoutePermissions{
+			"POST /api/v1/accounts":    server.PermAccountsWrite,
+			"GET /api/v1/accounts":     server.PermAccountsRead,
+			"GET /api/v1/accounts/:id": server.PermAccountsRead,
+
+			"POST /api/v1/apikeys":            server.PermAPIKeysManage,
+			"GET /api/v1/apikeys":             server.PermAPIKeysManage,
+			"GET /api/v1/apikeys/:id":         server.PermAPIKeysManage,
+			"POST /api/v1/apikeys/:id/rotate": server.PermAPIKeysManage,
+			"DELETE /api/v1/apikeys/:id":      server.PermAPIKeysManage,
+
+			"GET /api/v1/audit":        server.PermAuditRead,
+			"GET /api/v1/audit/verify": server.PermAuditRead,
+		},
+		APIKeys: apiKeyService,
+		Audit:   auditLog,
 	}
 
-	ctx := context.Background()
-	go func() {
-		logger.Log.Info().Msg("Starting to process Account Balance")
-		if err := accProcessor.ProcessAccountBalanceUpdates(ctx); err != nil {
-			log.Fatalf("Failed to process account balance updates: %v", err)
-		}
-	}()
-	server.RunServer(ctx, serverConfig, registerHandlers)
+	lifecycle.Go("account balance consumer", accProcessor.ProcessAccountBalanceUpdates)
+	lifecycle.Go("interest accrual scheduler", func(ctx context.Context) error {
+		interestScheduler.Run(ctx)
+		return nil
+	})
+	lifecycle.Go("http server", func(ctx context.Context) error {
+		server.RunServer(ctx, serverConfig, registerHandlers)
+		return nil
+	})
+	if err := lifecycle.Wait(); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Unclean shutdown")
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/db"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: account migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [steps]  revert the last steps migrations (default 1)
+  version       print the current and latest schema versions
+  force VERSION record the schema as being at VERSION without running migrations`
+
+// runMigrate manages the shared Postgres schema. The account service owns the schema; the other
+// services only check that it is at the version they were built for.
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	pgDb, err := db.ConnectPostgres(cfg.Database.PostgresConnectionString)
+	if err != nil {
+		return err
+	}
+	defer db.ClosePostgres(pgDb)
+
+	migrator, err := db.NewSchemaMigrator(pgDb)
+	if err != nil {
+		return err
+	}
+
+	previous, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Log.Info().Int("applied", applied).Int64("version", migrator.Latest()).Msg("Schema is up to date")
+		if applied > 0 {
+			auditSchemaChange(ctx, pgDb, "migrate up", previous, migrator.Latest())
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		logger.Log.Info().Int("reverted", reverted).Msg("Reverted migrations")
+		if version, err := migrator.Version(ctx); err == nil && reverted > 0 {
+			auditSchemaChange(ctx, pgDb, "migrate down", previous, version)
+		}
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("current: %d\nlatest:  %d\n", version, migrator.Latest())
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		logger.Log.Info().Int64("version", version).Msg("Forced schema version")
+		auditSchemaChange(ctx, pgDb, "migrate force", previous, version)
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+// auditSchemaChange records a schema change made from the command line, attributed to the OS
+// user running it. Reverting the audit log's own migration leaves nowhere to record it.
+func auditSchemaChange(ctx context.Context, pgDb *gorm.DB, action string, from, to int64) {
+	if !pgDb.Migrator().HasTable(&audit.Entry{}) {
+		return
+	}
+	entry := &audit.Entry{
+		Actor:      "os:" + os.Getenv("USER"),
+		Action:     action,
+		EntityType: "schema",
+		Changes:    audit.Diff(gin.H{"version": from}, gin.H{"version": to}),
+	}
+	if err := audit.NewLog(pgDb, config.AccountService).Record(ctx, entry); err != nil {
+		logger.Log.Error().Err(err).Str("action", action).Msg("Failed to record audit entry")
+	}
+}
@@ -7,11 +7,12 @@ import (
 )
 
 type Account struct {
-	ID            uuid.UUID `gorm:"uuid;default:uuid_generate_v4();primaryKey"`
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	AccountNumber string    `gorm:"type:varchar(20);not null;uniqueIndex"`
-	AccountType   string    `gorm:"type:varchar(20);not null"`
-	Status        string    `gorm:"type:varchar(20);not null"`
-	Balance       float64   `gorm:"type:decimal(10,2)"`
+	IBAN          *string   `gorm:"type:varchar(34);uniqueIndex"`
+	AccountType   string    `gorm:"type:varchar(50);not null"`
+	Status        string    `gorm:"type:varchar(20);not null;default:active"`
+	Balance       float64   `gorm:"type:decimal(19,4);not null;default:0"`
 	CreatedAt     time.Time `gorm:"type:timestamp with time zone"`
 	UpdatedAt     time.Time `gorm:"type:timestamp with time zone"`
 	CustomerID    uuid.UUID `gorm:"type:uuid;not null"`
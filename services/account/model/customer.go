@@ -7,7 +7,7 @@ import (
 )
 
 type Customer struct {
-	ID          uuid.UUID `gorm:"uuid;default:uuid_generate_v4();primaryKey"`
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	Name        string    `gorm:"type:varchar(255);not null"`
 	Email       string    `gorm:"type:varchar(255);unique"`
 	PhoneNumber string    `gorm:"type:varchar(20);unique"`
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Day-count conventions supported for interest accrual.
+const (
+	DayCountAct365 = "ACT/365"
+	DayCountAct360 = "ACT/360"
+	DayCountActAct = "ACT/ACT"
+)
+
+// InterestAccrual is the interest earned by an account for a single day.
+type InterestAccrual struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	AccountID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_interest_accruals_account_date"`
+	AccrualDate   time.Time  `gorm:"type:date;not null;uniqueIndex:idx_interest_accruals_account_date"`
+	Balance       float64    `gorm:"type:decimal(19,4);not null"`
+	AnnualRate    float64    `gorm:"type:decimal(9,6);not null"`
+	DayCount      string     `gorm:"type:varchar(10);not null"`
+	Amount        float64    `gorm:"type:decimal(19,8);not null"`
+	CapitalizedAt *time.Time `gorm:"type:timestamp with time zone"`
+	TransactionID *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt     time.Time  `gorm:"type:timestamp with time zone"`
+}
+
+// InterestCapitalization is the month-end credit of an account's accrued interest. It is
+// recorded with the accruals it capitalizes and published to Kafka after the commit; PublishedAt
+// is unset until then. Remainder is the part below a cent, carried into the next capitalization.
+type InterestCapitalization struct {
+	TransactionID uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	AccountID     uuid.UUID  `gorm:"type:uuid;not null"`
+	Period        string     `gorm:"type:varchar(7);not null"`
+	Amount        float64    `gorm:"type:decimal(19,4);not null"`
+	Remainder     float64    `gorm:"type:decimal(19,8);not null"`
+	PublishedAt   *time.Time `gorm:"type:timestamp with time zone"`
+	CreatedAt     time.Time  `gorm:"type:timestamp with time zone"`
+}
+
+// InterestRateTable holds the rate tiers applied to one account type.
+type InterestRateTable struct {
+	AccountType string             `json:"accountType"`
+	DayCount    string             `json:"dayCount"`
+	Tiers       []InterestRateTier `json:"tiers"`
+}
+
+// InterestRateTier is the annual rate paid on balances of at least MinBalance.
+type InterestRateTier struct {
+	MinBalance float64 `json:"minBalance"`
+	AnnualRate float64 `json:"annualRate"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RebuildSourceLedger replays the successful entries the ledger service recorded for an account.
+const RebuildSourceLedger = "ledger"
+
+// BalanceRebuild is the audit record of a balance and version replaced by a rebuild.
+type BalanceRebuild struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	AccountID       uuid.UUID `json:"accountId" gorm:"type:uuid;not null"`
+	PreviousBalance float64   `json:"previousBalance" gorm:"type:decimal(19,4);not null"`
+	PreviousVersion int64     `json:"previousVersion" gorm:"type:bigint;not null"`
+	RebuiltBalance  float64   `json:"rebuiltBalance" gorm:"type:decimal(19,4);not null"`
+	RebuiltVersion  int64     `json:"rebuiltVersion" gorm:"type:bigint;not null"`
+	EntryCount      int       `json:"entryCount" gorm:"not null"`
+	Source          string    `json:"source" gorm:"type:varchar(20);not null"`
+	AppliedBy       string    `json:"appliedBy,omitempty" gorm:"type:varchar(255)"`
+	Reason          string    `json:"reason,omitempty" gorm:"type:text"`
+	CreatedAt       time.Time `json:"createdAt" gorm:"type:timestamp with time zone"`
+}
+
+// LedgerEvent is a successful balance change replayed by a rebuild.
+type LedgerEvent struct {
+	TransactionID   string  `json:"transactionId"`
+	TransactionType string  `json:"transactionType"`
+	Amount          float64 `json:"amount"`
+	AcceptedAt      string  `json:"acceptedAt"`
+	Balance         float64 `json:"balance"`
+}
+
+// BalanceRebuildResult compares an account with the state replayed from its ledger events.
+// Events holds the latest events replayed, RebuiltVersion counts them all. Applied is set once
+// the rebuilt balance and version were written.
+type BalanceRebuildResult struct {
+	AccountID      uuid.UUID       `json:"accountId"`
+	CurrentBalance float64         `json:"currentBalance"`
+	CurrentVersion int64           `json:"currentVersion"`
+	RebuiltBalance float64         `json:"rebuiltBalance"`
+	RebuiltVersion int64           `json:"rebuiltVersion"`
+	BalanceDiff    float64         `json:"balanceDiff"`
+	VersionDiff    int64           `json:"versionDiff"`
+	Events         []LedgerEvent   `json:"events"`
+	Applied        *BalanceRebuild `json:"applied,omitempty"`
+}
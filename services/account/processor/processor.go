@@ -3,17 +3,20 @@ package processor
 import (
 	"context"
 	"encoding/json"
-	"sync"
 	"time"
 
 	"account/service"
 
 	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
 
 	"github.com/segmentio/kafka-go"
 )
 
+// balanceUpdateWorkers is the number of balance updates handled at once.
+const balanceUpdateWorkers = 5
+
 type Processor interface {
 	ProcessAccountBalanceUpdates(ctx context.Context) error
 }
@@ -40,41 +43,21 @@ func NewProcessor(consumer ckafka.KafkaConsumer, producer ckafka.KafkaProducer,
 
 func (p *processor) ProcessAccountBalanceUpdates(ctx context.Context) error {
 	logger.Log.Info().Msg("ProcessAccountBalanceUpdates")
-	msgChan := make(chan kafka.Message)
-	var wg sync.WaitGroup
-
-	// Start worker pool
-	for i := 0; i < 5; i++ { // 5 workers
-		wg.Add(1)
-		go p.worker(ctx, msgChan, &wg)
-	}
-
-	// Consume messages and send them to the worker pool
-	go func() {
-		defer close(msgChan)
-		if err := p.consumer.Consume(ctx, p.consumerTopics[0], p.consumerGroup, func(msg kafka.Message) error {
-			msgChan <- msg
-			return nil
-		}); err != nil {
-			logger.Log.Fatal().Err(err).Msg("Failed to consume messages")
+	workers := ckafka.Workers{Size: balanceUpdateWorkers, Pool: metrics.NewWorkerPool("account-balance-updates")}
+	return p.consumer.ConsumeConcurrently(ctx, p.consumerTopics[0], p.consumerGroup, workers, func(msg kafka.Message) error {
+		// Shutdown must not cancel a message halfway through
+		msgCtx, span := ckafka.StartProcessSpan(context.WithoutCancel(ctx), msg, p.consumerGroup)
+		defer span.End()
+		if err := p.handleAccountBalanceUpdate(msgCtx, msg); err != nil {
+			span.RecordError(err)
+			logger.Ctx(msgCtx).Error().Msgf("Failed to handle message: %v", err)
 		}
-	}()
-
-	wg.Wait()
-	return nil
-}
-
-func (p *processor) worker(ctx context.Context, msgChan <-chan kafka.Message, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for msg := range msgChan {
-		if err := p.handleAccountBalanceUpdate(ctx, msg); err != nil {
-			logger.Log.Error().Msgf("Failed to handle message: %v", err)
-		}
-	}
+		return nil
+	})
 }
 
 func (p *processor) handleAccountBalanceUpdate(ctx context.Context, msg kafka.Message) error {
-	logger.Log.Info().Msg("handleAccountBalanceUpdate")
+	logger.Ctx(ctx).Info().Msg("handleAccountBalanceUpdate")
 
 	var transaction map[string]interface{}
 	if err := json.Unmarshal(msg.Value, &transaction); err != nil {
@@ -84,7 +67,7 @@ func (p *processor) handleAccountBalanceUpdate(ctx context.Context, msg kafka.Me
 	accountID := transaction["accountId"].(string)
 	amount := transaction["amount"].(float64)
 	transactionType := transaction["transactionType"].(string)
-	logger.Log.Info().Msgf("handleAccountBalanceUpdate account balance pre update. account:(%v %v %v)", accountID, amount, transactionType)
+	logger.Ctx(ctx).Info().Msgf("handleAccountBalanceUpdate account balance pre update. account:(%v %v %v)", accountID, amount, transactionType)
 
 	//TODO: retry logic on optimistic lock failure error
 	err := p.accountService.UpdateAccountBalance(ctx, accountID, amount, transactionType)
@@ -92,11 +75,11 @@ func (p *processor) handleAccountBalanceUpdate(ctx context.Context, msg kafka.Me
 	if err != nil {
 		transaction["status"] = "failed"
 		transaction["error"] = err.Error()
-		logger.Log.Error().Msgf("handleAccountBalanceUpdate error while updating account balance account:(%v) err:%v", transaction, err.Error())
+		logger.Ctx(ctx).Error().Msgf("handleAccountBalanceUpdate error while updating account balance account:(%v) err:%v", transaction, err.Error())
 	} else {
 		transaction["status"] = "success"
 		transaction["error"] = ""
-		logger.Log.Info().Msgf("handleAccountBalanceUpdate account balance update sucesss. account:(%v)", transaction)
+		logger.Ctx(ctx).Info().Msgf("handleAccountBalanceUpdate account balance update sucesss. account:(%v)", transaction)
 	}
 	transaction["processedAt"] = time.Now().UTC()
 
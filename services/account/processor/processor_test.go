@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,6 +22,15 @@ func (m *MockKafkaConsumer) Consume(ctx context.Context, topic, group string, ha
 	return args.Error(0)
 }
 
+func (m *MockKafkaConsumer) ConsumeConcurrently(ctx context.Context, topic, group string, workers ckafka.Workers, handler func(kafka.Message) error) error {
+	args := m.Called(ctx, topic, group, handler)
+	return args.Error(0)
+}
+
+func (m *MockKafkaConsumer) Close() error {
+	return nil
+}
+
 type MockKafkaProducer struct {
 	mock.Mock
 }
@@ -30,6 +40,15 @@ func (m *MockKafkaProducer) Produce(ctx context.Context, topic string, message k
 	return args.Error(0)
 }
 
+func (m *MockKafkaProducer) ProduceBatch(ctx context.Context, topic string, messages []kafka.Message) error {
+	args := m.Called(ctx, topic, messages)
+	return args.Error(0)
+}
+
+func (m *MockKafkaProducer) Close() error {
+	return nil
+}
+
 type MockAccountService struct {
 	mock.Mock
 }
@@ -44,9 +63,12 @@ func (m *MockAccountService) CreateAccount(account *model.Account) error {
 	return args.Error(0)
 }
 
-func (m *MockAccountService) CreateOrUpdateCustomer(customer *model.Customer) error {
+func (m *MockAccountService) CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error) {
 	args := m.Called(customer)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
 func (m *MockAccountService) GetAccountByID(accountID uuid.UUID) (*model.Account, error) {
@@ -59,6 +81,19 @@ func (m *MockAccountService) ListAccounts() ([]model.Account, error) {
 	return args.Get(0).([]model.Account), args.Error(1)
 }
 
+func (m *MockAccountService) ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error) {
+	args := m.Called(customerID)
+	return args.Get(0).([]model.Account), args.Error(1)
+}
+
+func (m *MockAccountService) FindAccountByNumber(number string) (*model.Account, error) {
+	args := m.Called(number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Account), args.Error(1)
+}
+
 func TestProcessAccountBalanceUpdates(t *testing.T) {
 	mockConsumer := new(MockKafkaConsumer)
 	mockProducer := new(MockKafkaProducer)
@@ -68,7 +103,7 @@ func TestProcessAccountBalanceUpdates(t *testing.T) {
 
 	ctx := context.Background()
 
-	mockConsumer.On("Consume", ctx, "test-topic", "test-group", mock.AnythingOfType("func(kafka.Message) error")).Return(nil).Run(func(args mock.Arguments) {
+	mockConsumer.On("ConsumeConcurrently", ctx, "test-topic", "test-group", mock.AnythingOfType("func(kafka.Message) error")).Return(nil).Run(func(args mock.Arguments) {
 		handler := args.Get(3).(func(kafka.Message) error)
 		message := kafka.Message{
 			Key:   []byte("key"),
@@ -77,8 +112,9 @@ func TestProcessAccountBalanceUpdates(t *testing.T) {
 		handler(message)
 	})
 
-	mockAccountService.On("UpdateAccountBalance", ctx, "123", 100.0, "credit").Return(nil)
-	mockProducer.On("Produce", ctx, "status-topic", mock.AnythingOfType("kafka.Message")).Return(nil)
+	// Workers hand the message's trace context to the handler
+	mockAccountService.On("UpdateAccountBalance", mock.Anything, "123", 100.0, "credit").Return(nil)
+	mockProducer.On("Produce", mock.Anything, "status-topic", mock.AnythingOfType("kafka.Message")).Return(nil)
 
 	err := processor.ProcessAccountBalanceUpdates(ctx)
 	assert.NoError(t, err)
@@ -88,6 +124,51 @@ func TestProcessAccountBalanceUpdates(t *testing.T) {
 	mockAccountService.AssertExpectations(t)
 }
 
+func TestProcessAccountBalanceUpdates_CommitsAfterHandlingOnShutdown(t *testing.T) {
+	mockConsumer := new(MockKafkaConsumer)
+	mockProducer := new(MockKafkaProducer)
+	mockAccountService := new(MockAccountService)
+
+	processor := NewProcessor(mockConsumer, mockProducer, []string{"test-topic"}, []string{"status-topic"}, "test-group", mockAccountService)
+
+	var events []string
+	ctx, cancel := context.WithCancel(context.Background())
+	mockConsumer.On("ConsumeConcurrently", ctx, "test-topic", "test-group", mock.AnythingOfType("func(kafka.Message) error")).Return(nil).Run(func(args mock.Arguments) {
+		handler := args.Get(3).(func(kafka.Message) error)
+		// The consumer commits a message once its handler has returned
+		if err := handler(kafka.Message{Value: []byte(`{"accountId":"123", "amount":100.0, "transactionType":"credit"}`)}); err == nil {
+			events = append(events, "commit")
+		}
+	})
+
+	// Shutdown starts while the message is being handled; the balance update and status
+	// publish must not see the cancellation
+	notCancelled := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+	mockAccountService.On("UpdateAccountBalance", notCancelled, "123", 100.0, "credit").Return(nil).Run(func(mock.Arguments) {
+		cancel()
+		events = append(events, "update")
+	})
+	mockProducer.On("Produce", notCancelled, "status-topic", mock.AnythingOfType("kafka.Message")).Return(nil).Run(func(mock.Arguments) {
+		events = append(events, "publish")
+	})
+
+	err := processor.ProcessAccountBalanceUpdates(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update", "publish", "commit"}, events)
+	mockAccountService.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestProcessAccountBalanceUpdates_ConsumeError(t *testing.T) {
+	mockConsumer := new(MockKafkaConsumer)
+	processor := NewProcessor(mockConsumer, new(MockKafkaProducer), []string{"test-topic"}, []string{"status-topic"}, "test-group", new(MockAccountService))
+
+	mockConsumer.On("ConsumeConcurrently", mock.Anything, "test-topic", "test-group", mock.Anything).Return(errors.New("broker unavailable"))
+
+	err := processor.ProcessAccountBalanceUpdates(context.Background())
+	assert.EqualError(t, err, "broker unavailable")
+}
+
 func TestHandleAccountBalanceUpdate(t *testing.T) {
 	mockProducer := new(MockKafkaProducer)
 	mockAccountService := new(MockAccountService)
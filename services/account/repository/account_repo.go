@@ -9,13 +9,18 @@ import (
 	"github.com/google/uuid"
 	"github.com/shrishyam02/banking-ledger/common/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AccountRepository interface {
 	CreateAccount(account *model.Account) error
 	GetAccountByID(id uuid.UUID) (*model.Account, error)
 	ListAccounts() ([]model.Account, error)
-	CreateOrUpdateCustomer(customer *model.Customer) error
+	ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error)
+	FindAccountByNumber(number string) (*model.Account, error)
+	// CreateOrUpdateCustomer saves customer and returns the customer it replaced, or nil when
+	// the customer is new.
+	CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error)
 	UpdateAccountBalance(ctx context.Context, accountID string, amount float64, transactionType string) error
 }
 
@@ -43,8 +48,34 @@ func (r *accountRepository) ListAccounts() ([]model.Account, error) {
 	return accounts, err
 }
 
-func (r *accountRepository) CreateOrUpdateCustomer(customer *model.Customer) error {
-	return r.db.Save(customer).Error
+func (r *accountRepository) ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error) {
+	var accounts []model.Account
+	err := r.db.Preload("Customer").Where("customer_id = ?", customerID).Find(&accounts).Error
+	return accounts, err
+}
+
+// FindAccountByNumber returns the account with number as its account number or IBAN.
+func (r *accountRepository) FindAccountByNumber(number string) (*model.Account, error) {
+	var account model.Account
+	err := r.db.Preload("Customer").Where("account_number = ? OR iban = ?", number, number).First(&account).Error
+	return &account, err
+}
+
+func (r *accountRepository) CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error) {
+	var previous *model.Customer
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if customer.ID != uuid.Nil {
+			var existing model.Customer
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&existing, "id = ?", customer.ID).Error
+			if err == nil {
+				previous = &existing
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		return tx.Save(customer).Error
+	})
+	return previous, err
 }
 
 func (r *accountRepository) UpdateAccountBalance(ctx context.Context, accountID string, amount float64, transactionType string) error {
@@ -53,7 +84,7 @@ func (r *accountRepository) UpdateAccountBalance(ctx context.Context, accountID
 		if err := tx.First(&account, "ID = ?", accountID).Error; err != nil {
 			return err
 		}
-		logger.Log.Info().Msgf("handleAccountBalanceUpdate account balance check update. %v account:(%v %v %v)", account, accountID, amount, transactionType)
+		logger.Ctx(ctx).Info().Msgf("handleAccountBalanceUpdate account balance check update. balance:%v version:%v account:(%v %v %v)", account.Balance, account.Version, accountID, amount, transactionType)
 
 		currentVersion := account.Version
 
@@ -64,7 +95,7 @@ func (r *accountRepository) UpdateAccountBalance(ctx context.Context, accountID
 		} else {
 			return fmt.Errorf("unknown transaction type %s", transactionType)
 		}
-		logger.Log.Info().Msgf("handleAccountBalanceUpdate account balance pre update. %v account:(%v %v %v)", account, accountID, amount, transactionType)
+		logger.Ctx(ctx).Info().Msgf("handleAccountBalanceUpdate account balance pre update. balance:%v account:(%v %v %v)", account.Balance, accountID, amount, transactionType)
 
 		account.Version++
 		var acc model.Account
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"account/model"
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InterestRepository interface {
+	ListActiveAccountsByType(ctx context.Context, accountType string) ([]model.Account, error)
+	CreateAccrual(ctx context.Context, accrual *model.InterestAccrual) error
+	LatestAccrualDate(ctx context.Context) (*time.Time, error)
+	ListAccountsWithPendingAccruals(ctx context.Context, periodEnd time.Time) ([]uuid.UUID, error)
+	CapitalizeAccruals(ctx context.Context, accountID uuid.UUID, periodEnd time.Time, transactionID uuid.UUID, period string) (*model.InterestCapitalization, error)
+	ListUnpublishedCapitalizations(ctx context.Context) ([]model.InterestCapitalization, error)
+	MarkCapitalizationPublished(ctx context.Context, transactionID uuid.UUID) error
+}
+
+type interestRepository struct {
+	db *gorm.DB
+}
+
+func NewInterestRepository(db *gorm.DB) InterestRepository {
+	return &interestRepository{db: db}
+}
+
+func (r *interestRepository) ListActiveAccountsByType(ctx context.Context, accountType string) ([]model.Account, error) {
+	var accounts []model.Account
+	err := r.db.WithContext(ctx).Where("account_type = ? AND status = ?", accountType, "active").Find(&accounts).Error
+	return accounts, err
+}
+
+// CreateAccrual stores the accrual unless one already exists for the account and day,
+// so re-running the job for the same day is a no-op.
+func (r *interestRepository) CreateAccrual(ctx context.Context, accrual *model.InterestAccrual) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "accrual_date"}},
+		DoNothing: true,
+	}).Create(accrual).Error
+}
+
+// LatestAccrualDate returns the last day interest was accrued for, or nil before the first accrual.
+func (r *interestRepository) LatestAccrualDate(ctx context.Context) (*time.Time, error) {
+	var accruals []model.InterestAccrual
+	err := r.db.WithContext(ctx).Order("accrual_date DESC").Limit(1).Find(&accruals).Error
+	if err != nil || len(accruals) == 0 {
+		return nil, err
+	}
+	return &accruals[0].AccrualDate, nil
+}
+
+func (r *interestRepository) ListAccountsWithPendingAccruals(ctx context.Context, periodEnd time.Time) ([]uuid.UUID, error) {
+	var accountIDs []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&model.InterestAccrual{}).
+		Where("capitalized_at IS NULL AND accrual_date <= ?", periodEnd).
+		Distinct().Pluck("account_id", &accountIDs).Error
+	return accountIDs, err
+}
+
+// CapitalizeAccruals locks the account's uncapitalized accruals up to periodEnd, marks them
+// capitalized and records their total, rounded to the cent, as a capitalization to publish. The
+// remainder of the account's previous capitalization is added first, so no fraction of a cent is
+// lost. It returns nil when there is nothing to capitalize or the transaction is already recorded;
+// accruals left over in that case go into the next period.
+func (r *interestRepository) CapitalizeAccruals(ctx context.Context, accountID uuid.UUID, periodEnd time.Time, transactionID uuid.UUID, period string) (*model.InterestCapitalization, error) {
+	var capitalization *model.InterestCapitalization
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var accruals []model.InterestAccrual
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("account_id = ? AND capitalized_at IS NULL AND accrual_date <= ?", accountID, periodEnd).
+			Find(&accruals).Error; err != nil {
+			return err
+		}
+		if len(accruals) == 0 {
+			return nil
+		}
+
+		var previous []model.InterestCapitalization
+		if err := tx.Where("account_id = ?", accountID).Order("created_at DESC").Limit(1).Find(&previous).Error; err != nil {
+			return err
+		}
+		if len(previous) > 0 && previous[0].TransactionID == transactionID {
+			return nil
+		}
+
+		total := 0.0
+		if len(previous) > 0 {
+			total = previous[0].Remainder
+		}
+		ids := make([]uuid.UUID, 0, len(accruals))
+		for _, accrual := range accruals {
+			total += accrual.Amount
+			ids = append(ids, accrual.ID)
+		}
+		amount := math.Round(total*100) / 100
+
+		now := time.Now().UTC()
+		capitalization = &model.InterestCapitalization{
+			TransactionID: transactionID,
+			AccountID:     accountID,
+			Period:        period,
+			Amount:        amount,
+			Remainder:     total - amount,
+			CreatedAt:     now,
+		}
+		if amount <= 0 {
+			// Nothing to post; the remainder waits for the next capitalization
+			capitalization.PublishedAt = &now
+		}
+		if err := tx.Model(&model.InterestAccrual{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+			"capitalized_at": now,
+			"transaction_id": transactionID,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(capitalization).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return capitalization, nil
+}
+
+// ListUnpublishedCapitalizations returns the capitalizations not yet posted, oldest first.
+func (r *interestRepository) ListUnpublishedCapitalizations(ctx context.Context) ([]model.InterestCapitalization, error) {
+	var capitalizations []model.InterestCapitalization
+	err := r.db.WithContext(ctx).Where("published_at IS NULL").Order("created_at").Find(&capitalizations).Error
+	return capitalizations, err
+}
+
+func (r *interestRepository) MarkCapitalizationPublished(ctx context.Context, transactionID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.InterestCapitalization{}).
+		Where("transaction_id = ? AND published_at IS NULL", transactionID).
+		UpdateColumn("published_at", time.Now().UTC()).Error
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"account/model"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAccountChanged is returned when the account moved on since the rebuild was previewed.
+var ErrAccountChanged = errors.New("account changed since the rebuild was computed")
+
+type RebuildRepository interface {
+	ApplyRebuild(ctx context.Context, rebuild *model.BalanceRebuild) error
+	ListRebuilds(ctx context.Context, accountID uuid.UUID) ([]model.BalanceRebuild, error)
+}
+
+type rebuildRepository struct {
+	db *gorm.DB
+}
+
+func NewRebuildRepository(db *gorm.DB) RebuildRepository {
+	return &rebuildRepository{db: db}
+}
+
+// ApplyRebuild writes the rebuilt balance and version and records the rebuild, holding the
+// account row lock so no balance update interleaves. It fails with ErrAccountChanged unless the
+// account is still at the previous balance and version of the rebuild.
+func (r *rebuildRepository) ApplyRebuild(ctx context.Context, rebuild *model.BalanceRebuild) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var account model.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, "id = ?", rebuild.AccountID).Error; err != nil {
+			return err
+		}
+		if account.Version != rebuild.PreviousVersion || account.Balance != rebuild.PreviousBalance {
+			return ErrAccountChanged
+		}
+
+		err := tx.Model(&model.Account{}).Where("id = ?", rebuild.AccountID).UpdateColumns(map[string]interface{}{
+			"balance": rebuild.RebuiltBalance,
+			"version": rebuild.RebuiltVersion,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(rebuild).Error
+	})
+}
+
+func (r *rebuildRepository) ListRebuilds(ctx context.Context, accountID uuid.UUID) ([]model.BalanceRebuild, error) {
+	var rebuilds []model.BalanceRebuild
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at DESC").Find(&rebuilds).Error
+	return rebuilds, err
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"account/service"
+	"context"
+	"time"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+)
+
+type InterestScheduler interface {
+	Run(ctx context.Context)
+}
+
+type interestScheduler struct {
+	interestService service.InterestService
+	runAt           time.Duration
+}
+
+// NewInterestScheduler runs the accrual job daily at runAt past midnight UTC.
+func NewInterestScheduler(interestService service.InterestService, runAt time.Duration) InterestScheduler {
+	return &interestScheduler{
+		interestService: interestService,
+		runAt:           runAt,
+	}
+}
+
+func (s *interestScheduler) Run(ctx context.Context) {
+	s.catchUp(ctx, time.Now().UTC().AddDate(0, 0, -1))
+
+	for {
+		next := nextRun(time.Now().UTC(), s.runAt)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.runFor(ctx, next.AddDate(0, 0, -1))
+		}
+	}
+}
+
+// catchUp runs every day from the last recorded accrual up to yesterday, in case the service was
+// down at the scheduled times. The last recorded day is run again in case it was cut short;
+// accruals are idempotent per account and day.
+func (s *interestScheduler) catchUp(ctx context.Context, yesterday time.Time) {
+	yesterday = time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, time.UTC)
+	day := yesterday
+	last, err := s.interestService.LastAccrualDate(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Failed to find the last interest accrual; catching up on yesterday only")
+	} else if last != nil && last.Before(day) {
+		day = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	for ; !day.After(yesterday) && ctx.Err() == nil; day = day.AddDate(0, 0, 1) {
+		s.runFor(ctx, day)
+	}
+}
+
+func (s *interestScheduler) runFor(ctx context.Context, day time.Time) {
+	ctx = requestid.NewContext(ctx, requestid.New())
+	if err := s.interestService.AccrueDaily(ctx, day); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msgf("Interest accrual failed for %s", day.Format(time.DateOnly))
+	}
+
+	if isMonthEnd(day) {
+		if err := s.interestService.CapitalizeMonth(ctx, day); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Msgf("Interest capitalization failed for %s", day.Format("2006-01"))
+		}
+		return
+	}
+	// Retry capitalizations recorded at month-end whose publishing failed
+	if err := s.interestService.PublishCapitalizations(ctx); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Publishing interest capitalizations failed")
+	}
+}
+
+func nextRun(now time.Time, runAt time.Duration) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(runAt)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func isMonthEnd(day time.Time) bool {
+	return day.AddDate(0, 0, 1).Day() == 1
+}
@@ -4,6 +4,7 @@ import (
 	"account/model"
 	"account/repository"
 	"context"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -12,7 +13,9 @@ type AccountService interface {
 	CreateAccount(account *model.Account) error
 	GetAccountByID(id uuid.UUID) (*model.Account, error)
 	ListAccounts() ([]model.Account, error)
-	CreateOrUpdateCustomer(customer *model.Customer) error
+	ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error)
+	FindAccountByNumber(number string) (*model.Account, error)
+	CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error)
 	UpdateAccountBalance(ctx context.Context, accountID string, amount float64, transactionType string) error
 }
 
@@ -36,7 +39,17 @@ func (s *accountService) ListAccounts() ([]model.Account, error) {
 	return s.repo.ListAccounts()
 }
 
-func (s *accountService) CreateOrUpdateCustomer(customer *model.Customer) error {
+func (s *accountService) ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error) {
+	return s.repo.ListAccountsByCustomer(customerID)
+}
+
+// FindAccountByNumber looks an account up by account number or IBAN, ignoring the spaces and
+// case IBANs are often printed with.
+func (s *accountService) FindAccountByNumber(number string) (*model.Account, error) {
+	return s.repo.FindAccountByNumber(strings.ToUpper(strings.ReplaceAll(number, " ", "")))
+}
+
+func (s *accountService) CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error) {
 	return s.repo.CreateOrUpdateCustomer(customer)
 }
 
@@ -31,6 +31,19 @@ func (m *MockAccountRepository) ListAccounts() ([]model.Account, error) {
 	return args.Get(0).([]model.Account), args.Error(1)
 }
 
+func (m *MockAccountRepository) ListAccountsByCustomer(customerID uuid.UUID) ([]model.Account, error) {
+	args := m.Called(customerID)
+	return args.Get(0).([]model.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) FindAccountByNumber(number string) (*model.Account, error) {
+	args := m.Called(number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Account), args.Error(1)
+}
+
 func TestCreateAccount(t *testing.T) {
 	mockRepo := new(MockAccountRepository)
 	service := NewService(mockRepo)
@@ -73,9 +86,12 @@ func TestListAccounts(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func (m *MockAccountRepository) CreateOrUpdateCustomer(customer *model.Customer) error {
+func (m *MockAccountRepository) CreateOrUpdateCustomer(customer *model.Customer) (*model.Customer, error) {
 	args := m.Called(customer)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
 func (m *MockAccountRepository) UpdateAccountBalance(ctx context.Context, id string, balance float64, currency string) error {
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"account/model"
+	"account/repository"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+//go:embed interest_rates.json
+var defaultInterestRates []byte
+
+// interestNamespace seeds the deterministic IDs of capitalization transactions,
+// so a retried month-end run posts under the same transaction ID.
+var interestNamespace = uuid.MustParse("5b0a3c2e-7a51-4d1e-9a0f-6f1d2f0c8e11")
+
+type InterestService interface {
+	AccrueDaily(ctx context.Context, day time.Time) error
+	// LastAccrualDate returns the last day interest was accrued for, or nil before the first accrual.
+	LastAccrualDate(ctx context.Context) (*time.Time, error)
+	CapitalizeMonth(ctx context.Context, periodEnd time.Time) error
+	PublishCapitalizations(ctx context.Context) error
+}
+
+type interestService struct {
+	repo     repository.InterestRepository
+	ledger   LedgerService
+	producer ckafka.KafkaProducer
+	topic    string
+	tables   []model.InterestRateTable
+}
+
+// NewInterestService accrues on the end-of-day balances the ledger reports. Without a ledger the
+// balance at the time of the run stands in for it, which only holds for the day just ended.
+func NewInterestService(repo repository.InterestRepository, ledger LedgerService, producer ckafka.KafkaProducer, topic string, tables []model.InterestRateTable) InterestService {
+	return &interestService{
+		repo:     repo,
+		ledger:   ledger,
+		producer: producer,
+		topic:    topic,
+		tables:   tables,
+	}
+}
+
+// LoadInterestRateTables reads rate tables from a JSON file, falling back to the
+// embedded defaults when path is empty.
+func LoadInterestRateTables(path string) ([]model.InterestRateTable, error) {
+	data := defaultInterestRates
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var tables []model.InterestRateTable
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		if _, err := dayCountFraction(tables[i].DayCount, time.Now()); err != nil {
+			return nil, fmt.Errorf("rate table %s: %w", tables[i].AccountType, err)
+		}
+		sort.Slice(tables[i].Tiers, func(a, b int) bool {
+			return tables[i].Tiers[a].MinBalance < tables[i].Tiers[b].MinBalance
+		})
+	}
+	return tables, nil
+}
+
+// AccrueDaily records one day of interest for every active account covered by a rate table,
+// on the account's balance at the end of the day.
+func (s *interestService) AccrueDaily(ctx context.Context, day time.Time) error {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	dayEnd := day.AddDate(0, 0, 1)
+	var errs []error
+
+	for _, table := range s.tables {
+		fraction, err := dayCountFraction(table.DayCount, day)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		accounts, err := s.repo.ListActiveAccountsByType(ctx, table.AccountType)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, account := range accounts {
+			if !account.CreatedAt.Before(dayEnd) {
+				continue
+			}
+			balance, err := s.closingBalance(ctx, account, day)
+			if err != nil {
+				logger.Ctx(ctx).Error().Err(err).Msgf("Failed to get the balance of account %s on %s", account.ID, day.Format(time.DateOnly))
+				errs = append(errs, err)
+				continue
+			}
+			rate := tierRate(table.Tiers, balance)
+			if balance <= 0 || rate <= 0 {
+				continue
+			}
+
+			accrual := &model.InterestAccrual{
+				AccountID:   account.ID,
+				AccrualDate: day,
+				Balance:     balance,
+				AnnualRate:  rate,
+				DayCount:    table.DayCount,
+				Amount:      balance * rate * fraction,
+			}
+			if err := s.repo.CreateAccrual(ctx, accrual); err != nil {
+				logger.Ctx(ctx).Error().Err(err).Msgf("Failed to accrue interest for account %s on %s", account.ID, day.Format(time.DateOnly))
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	logger.Ctx(ctx).Info().Msgf("Interest accrual completed for %s", day.Format(time.DateOnly))
+	return errors.Join(errs...)
+}
+
+// closingBalance returns the account's balance at the end of day.
+func (s *interestService) closingBalance(ctx context.Context, account model.Account, day time.Time) (float64, error) {
+	if s.ledger == nil {
+		return account.Balance, nil
+	}
+	return s.ledger.ClosingBalance(ctx, account.ID, day)
+}
+
+func (s *interestService) LastAccrualDate(ctx context.Context) (*time.Time, error) {
+	return s.repo.LatestAccrualDate(ctx)
+}
+
+// CapitalizeMonth records the interest accrued up to periodEnd as a capitalization for each
+// account, then posts the capitalizations as credit transactions on transactions-topic, so they
+// follow the normal processing pipeline.
+func (s *interestService) CapitalizeMonth(ctx context.Context, periodEnd time.Time) error {
+	period := periodEnd.Format("2006-01")
+	accountIDs, err := s.repo.ListAccountsWithPendingAccruals(ctx, periodEnd)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, accountID := range accountIDs {
+		transactionID := uuid.NewSHA1(interestNamespace, []byte(accountID.String()+"/"+period))
+
+		capitalization, err := s.repo.CapitalizeAccruals(ctx, accountID, periodEnd, transactionID, period)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Msgf("Failed to capitalize interest for account %s period %s", accountID, period)
+			errs = append(errs, err)
+			continue
+		}
+		if capitalization != nil {
+			logger.Ctx(ctx).Info().Msgf("Capitalized interest %.2f for account %s period %s", capitalization.Amount, accountID, period)
+		}
+	}
+	errs = append(errs, s.PublishCapitalizations(ctx))
+	return errors.Join(errs...)
+}
+
+// PublishCapitalizations posts the capitalizations recorded but not yet published, including
+// those whose publishing failed on an earlier run.
+func (s *interestService) PublishCapitalizations(ctx context.Context) error {
+	capitalizations, err := s.repo.ListUnpublishedCapitalizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, capitalization := range capitalizations {
+		if err := s.publishCapitalization(ctx, capitalization); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Msgf("Failed to publish interest capitalization %s", capitalization.TransactionID)
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.repo.MarkCapitalizationPublished(ctx, capitalization.TransactionID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *interestService) publishCapitalization(ctx context.Context, capitalization model.InterestCapitalization) error {
+	transaction := map[string]interface{}{
+		"id":              capitalization.TransactionID.String(),
+		"accountId":       capitalization.AccountID.String(),
+		"amount":          capitalization.Amount,
+		"transactionType": "credit",
+		"category":        "interest",
+		"details":         "Interest capitalization for " + capitalization.Period,
+		"acceptedAt":      time.Now().UTC(),
+	}
+	transactionBytes, err := json.Marshal(transaction)
+	if err != nil {
+		return err
+	}
+
+	return s.producer.Produce(ctx, s.topic, kafka.Message{
+		Key:   []byte(capitalization.TransactionID.String()),
+		Value: transactionBytes,
+	})
+}
+
+// tierRate returns the rate of the highest tier the balance qualifies for; it applies to the whole balance.
+func tierRate(tiers []model.InterestRateTier, balance float64) float64 {
+	rate := 0.0
+	for _, tier := range tiers {
+		if balance >= tier.MinBalance {
+			rate = tier.AnnualRate
+		}
+	}
+	return rate
+}
+
+// dayCountFraction returns the fraction of a year that one day represents under the convention.
+func dayCountFraction(convention string, day time.Time) (float64, error) {
+	switch convention {
+	case model.DayCountAct365:
+		return 1.0 / 365, nil
+	case model.DayCountAct360:
+		return 1.0 / 360, nil
+	case model.DayCountActAct:
+		yearStart := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		days := yearStart.AddDate(1, 0, 0).Sub(yearStart).Hours() / 24
+		return 1.0 / days, nil
+	default:
+		return 0, fmt.Errorf("unsupported day count convention %q", convention)
+	}
+}
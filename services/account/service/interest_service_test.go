@@ -0,0 +1,277 @@
+package service
+
+import (
+	"account/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInterestRepository struct {
+	mock.Mock
+}
+
+func (m *MockInterestRepository) ListActiveAccountsByType(ctx context.Context, accountType string) ([]model.Account, error) {
+	args := m.Called(ctx, accountType)
+	return args.Get(0).([]model.Account), args.Error(1)
+}
+
+func (m *MockInterestRepository) CreateAccrual(ctx context.Context, accrual *model.InterestAccrual) error {
+	args := m.Called(ctx, accrual)
+	return args.Error(0)
+}
+
+func (m *MockInterestRepository) LatestAccrualDate(ctx context.Context) (*time.Time, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockInterestRepository) ListAccountsWithPendingAccruals(ctx context.Context, periodEnd time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, periodEnd)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockInterestRepository) CapitalizeAccruals(ctx context.Context, accountID uuid.UUID, periodEnd time.Time, transactionID uuid.UUID, period string) (*model.InterestCapitalization, error) {
+	args := m.Called(ctx, accountID, periodEnd, transactionID, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.InterestCapitalization), args.Error(1)
+}
+
+func (m *MockInterestRepository) ListUnpublishedCapitalizations(ctx context.Context) ([]model.InterestCapitalization, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.InterestCapitalization), args.Error(1)
+}
+
+func (m *MockInterestRepository) MarkCapitalizationPublished(ctx context.Context, transactionID uuid.UUID) error {
+	args := m.Called(ctx, transactionID)
+	return args.Error(0)
+}
+
+type MockKafkaProducer struct {
+	mock.Mock
+}
+
+func (m *MockKafkaProducer) Produce(ctx context.Context, topic string, message kafka.Message) error {
+	args := m.Called(ctx, topic, message)
+	return args.Error(0)
+}
+
+func (m *MockKafkaProducer) ProduceBatch(ctx context.Context, topic string, messages []kafka.Message) error {
+	args := m.Called(ctx, topic, messages)
+	return args.Error(0)
+}
+
+func (m *MockKafkaProducer) Close() error {
+	return nil
+}
+
+var savingsTable = model.InterestRateTable{
+	AccountType: "savings",
+	DayCount:    model.DayCountAct365,
+	Tiers: []model.InterestRateTier{
+		{MinBalance: 0, AnnualRate: 0.01},
+		{MinBalance: 10000, AnnualRate: 0.02},
+	},
+}
+
+func TestLoadInterestRateTables_Default(t *testing.T) {
+	tables, err := LoadInterestRateTables("")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tables)
+	assert.Equal(t, "savings", tables[0].AccountType)
+}
+
+func TestTierRate(t *testing.T) {
+	assert.Equal(t, 0.01, tierRate(savingsTable.Tiers, 500))
+	assert.Equal(t, 0.02, tierRate(savingsTable.Tiers, 10000))
+	assert.Equal(t, 0.0, tierRate([]model.InterestRateTier{{MinBalance: 100, AnnualRate: 0.01}}, 50))
+}
+
+func TestDayCountFraction(t *testing.T) {
+	leapDay := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	fraction, err := dayCountFraction(model.DayCountAct365, leapDay)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0/365, fraction)
+
+	fraction, err = dayCountFraction(model.DayCountAct360, leapDay)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0/360, fraction)
+
+	fraction, err = dayCountFraction(model.DayCountActAct, leapDay)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0/366, fraction)
+
+	_, err = dayCountFraction("30/360", leapDay)
+	assert.Error(t, err)
+}
+
+func TestAccrueDaily(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	service := NewInterestService(mockRepo, nil, nil, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	day := time.Date(2026, time.October, 18, 23, 59, 0, 0, time.UTC)
+	funded := model.Account{ID: uuid.New(), Balance: 36500}
+	empty := model.Account{ID: uuid.New(), Balance: 0}
+
+	mockRepo.On("ListActiveAccountsByType", ctx, "savings").Return([]model.Account{funded, empty}, nil)
+	mockRepo.On("CreateAccrual", ctx, mock.MatchedBy(func(accrual *model.InterestAccrual) bool {
+		return accrual.AccountID == funded.ID &&
+			accrual.AccrualDate.Equal(time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)) &&
+			accrual.AnnualRate == 0.02 &&
+			math.Abs(accrual.Amount-2.0) < 1e-9
+	})).Return(nil).Once()
+
+	err := service.AccrueDaily(ctx, day)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAccrueDaily_OnLedgerClosingBalance(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	mockLedger := new(MockLedgerService)
+	service := NewInterestService(mockRepo, mockLedger, nil, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	day := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+	// Emptied since the day being caught up on
+	account := model.Account{ID: uuid.New(), Balance: 0, CreatedAt: day.AddDate(0, -1, 0)}
+	opened := model.Account{ID: uuid.New(), Balance: 36500, CreatedAt: day.AddDate(0, 0, 3)}
+	unavailable := model.Account{ID: uuid.New(), Balance: 36500}
+
+	mockRepo.On("ListActiveAccountsByType", ctx, "savings").Return([]model.Account{account, opened, unavailable}, nil)
+	mockLedger.On("ClosingBalance", ctx, account.ID, day).Return(3650.0, nil)
+	mockLedger.On("ClosingBalance", ctx, unavailable.ID, day).Return(0.0, errors.New("ledger unavailable"))
+	mockRepo.On("CreateAccrual", ctx, mock.MatchedBy(func(accrual *model.InterestAccrual) bool {
+		return accrual.AccountID == account.ID && accrual.Balance == 3650 && math.Abs(accrual.Amount-0.1) < 1e-9
+	})).Return(nil).Once()
+
+	err := service.AccrueDaily(ctx, day)
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+	mockLedger.AssertNotCalled(t, "ClosingBalance", ctx, opened.ID, day)
+}
+
+func TestAccrueDaily_Error(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	service := NewInterestService(mockRepo, nil, nil, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	mockRepo.On("ListActiveAccountsByType", ctx, "savings").Return([]model.Account(nil), errors.New("db error"))
+
+	err := service.AccrueDaily(ctx, time.Now())
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCapitalizeMonth(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	mockProducer := new(MockKafkaProducer)
+	service := NewInterestService(mockRepo, nil, mockProducer, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	periodEnd := time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC)
+	accountID := uuid.New()
+	transactionID := uuid.NewSHA1(interestNamespace, []byte(accountID.String()+"/2026-09"))
+	capitalization := model.InterestCapitalization{TransactionID: transactionID, AccountID: accountID, Period: "2026-09", Amount: 12.34, Remainder: 0.004}
+
+	mockRepo.On("ListAccountsWithPendingAccruals", ctx, periodEnd).Return([]uuid.UUID{accountID}, nil)
+	mockRepo.On("CapitalizeAccruals", ctx, accountID, periodEnd, transactionID, "2026-09").Return(&capitalization, nil)
+	mockRepo.On("ListUnpublishedCapitalizations", ctx).Return([]model.InterestCapitalization{capitalization}, nil)
+	mockProducer.On("Produce", ctx, "transactions-topic", mock.MatchedBy(func(msg kafka.Message) bool {
+		var transaction map[string]interface{}
+		if err := json.Unmarshal(msg.Value, &transaction); err != nil {
+			return false
+		}
+		return string(msg.Key) == transactionID.String() &&
+			transaction["accountId"] == accountID.String() &&
+			transaction["amount"] == 12.34 &&
+			transaction["transactionType"] == "credit" &&
+			transaction["category"] == "interest"
+	})).Return(nil)
+	mockRepo.On("MarkCapitalizationPublished", ctx, transactionID).Return(nil)
+
+	err := service.CapitalizeMonth(ctx, periodEnd)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestCapitalizeMonth_PublishError(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	mockProducer := new(MockKafkaProducer)
+	service := NewInterestService(mockRepo, nil, mockProducer, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	periodEnd := time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC)
+	accountID := uuid.New()
+	capitalization := model.InterestCapitalization{TransactionID: uuid.New(), AccountID: accountID, Period: "2026-09", Amount: 5}
+
+	mockRepo.On("ListAccountsWithPendingAccruals", ctx, periodEnd).Return([]uuid.UUID{accountID}, nil)
+	mockRepo.On("CapitalizeAccruals", ctx, accountID, periodEnd, mock.Anything, "2026-09").Return(&capitalization, nil)
+	mockRepo.On("ListUnpublishedCapitalizations", ctx).Return([]model.InterestCapitalization{capitalization}, nil)
+	mockProducer.On("Produce", ctx, "transactions-topic", mock.Anything).Return(errors.New("kafka error"))
+
+	err := service.CapitalizeMonth(ctx, periodEnd)
+	assert.Error(t, err)
+	// The capitalization stays in the outbox for the next run
+	mockRepo.AssertNotCalled(t, "MarkCapitalizationPublished", mock.Anything, mock.Anything)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestCapitalizeMonth_RecordError(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	mockProducer := new(MockKafkaProducer)
+	service := NewInterestService(mockRepo, nil, mockProducer, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	periodEnd := time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC)
+	accountID := uuid.New()
+
+	mockRepo.On("ListAccountsWithPendingAccruals", ctx, periodEnd).Return([]uuid.UUID{accountID}, nil)
+	mockRepo.On("CapitalizeAccruals", ctx, accountID, periodEnd, mock.Anything, "2026-09").Return(nil, errors.New("db error"))
+	mockRepo.On("ListUnpublishedCapitalizations", ctx).Return([]model.InterestCapitalization(nil), nil)
+
+	err := service.CapitalizeMonth(ctx, periodEnd)
+	assert.Error(t, err)
+	// Nothing is published for a capitalization that was not recorded
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPublishCapitalizations_RetriesOutbox(t *testing.T) {
+	mockRepo := new(MockInterestRepository)
+	mockProducer := new(MockKafkaProducer)
+	service := NewInterestService(mockRepo, nil, mockProducer, "transactions-topic", []model.InterestRateTable{savingsTable})
+
+	ctx := context.Background()
+	first := model.InterestCapitalization{TransactionID: uuid.New(), AccountID: uuid.New(), Period: "2026-08", Amount: 1.5}
+	second := model.InterestCapitalization{TransactionID: uuid.New(), AccountID: uuid.New(), Period: "2026-08", Amount: 2.5}
+
+	mockRepo.On("ListUnpublishedCapitalizations", ctx).Return([]model.InterestCapitalization{first, second}, nil)
+	mockProducer.On("Produce", ctx, "transactions-topic", mock.MatchedBy(func(msg kafka.Message) bool {
+		return string(msg.Key) == first.TransactionID.String()
+	})).Return(errors.New("kafka error"))
+	mockProducer.On("Produce", ctx, "transactions-topic", mock.MatchedBy(func(msg kafka.Message) bool {
+		return string(msg.Key) == second.TransactionID.String()
+	})).Return(nil)
+	mockRepo.On("MarkCapitalizationPublished", ctx, second.TransactionID).Return(nil)
+
+	err := service.PublishCapitalizations(ctx)
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "MarkCapitalizationPublished", ctx, first.TransactionID)
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+)
+
+type LedgerService interface {
+	// EachEntry calls fn with the successful ledger entries of the account, oldest first.
+	EachEntry(ctx context.Context, accountID uuid.UUID, fn func(client.LedgerEntry) error) error
+	// ClosingBalance returns the account's balance at the end of day (UTC).
+	ClosingBalance(ctx context.Context, accountID uuid.UUID, day time.Time) (float64, error)
+}
+
+// NewLedgerService calls the ledger service with httpClient, which authenticates the requests.
+func NewLedgerService(ledgerServiceURL string, httpClient *http.Client) LedgerService {
+	return client.NewLedger(ledgerServiceURL, httpClient)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEachEntry_Pages(t *testing.T) {
+	accountID := uuid.New()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var firstPage []client.LedgerEntry
+	for i := range 500 {
+		firstPage = append(firstPage, client.LedgerEntry{ID: fmt.Sprintf("t%d", i), TransactionType: "credit", Amount: 1, AcceptedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+	last := firstPage[len(firstPage)-1]
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/ledger/accounts/"+accountID.String()+"/entries", r.URL.Path)
+		assert.Equal(t, "500", r.URL.Query().Get("limit"))
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("afterId") == "" {
+			json.NewEncoder(w).Encode(firstPage)
+			return
+		}
+		assert.Equal(t, last.ID, r.URL.Query().Get("afterId"))
+		assert.Equal(t, last.AcceptedAt.Format(time.RFC3339Nano), r.URL.Query().Get("afterAcceptedAt"))
+		json.NewEncoder(w).Encode([]client.LedgerEntry{{ID: "t500", TransactionType: "debit", Amount: 1, AcceptedAt: last.AcceptedAt.Add(time.Second)}})
+	}))
+	defer server.Close()
+
+	service := NewLedgerService(server.URL, http.DefaultClient)
+
+	var ids []string
+	err := service.EachEntry(context.Background(), accountID, func(entry client.LedgerEntry) error {
+		ids = append(ids, entry.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, ids, 501) {
+		assert.Equal(t, "t0", ids[0])
+		assert.Equal(t, "t500", ids[500])
+	}
+}
+
+func TestEachEntry_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	service := NewLedgerService(server.URL, http.DefaultClient)
+
+	err := service.EachEntry(context.Background(), uuid.New(), func(client.LedgerEntry) error {
+		t.Fatal("no entry expected")
+		return nil
+	})
+	var statusErr *client.StatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+		assert.False(t, statusErr.Retryable())
+	}
+}
+
+func TestEachEntry_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	service := NewLedgerService(server.URL, http.DefaultClient)
+
+	err := service.EachEntry(context.Background(), uuid.New(), func(client.LedgerEntry) error { return nil })
+	var statusErr *client.StatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.True(t, statusErr.Retryable())
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"account/model"
+	"account/repository"
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+// maxRebuildEvents bounds the events a rebuild reports for review.
+const maxRebuildEvents = 100
+
+type RebuildService interface {
+	Preview(ctx context.Context, accountID uuid.UUID) (*model.BalanceRebuildResult, error)
+	Apply(ctx context.Context, accountID uuid.UUID, expectedVersion int64, appliedBy string, reason string) (*model.BalanceRebuildResult, error)
+	ListRebuilds(ctx context.Context, accountID uuid.UUID) ([]model.BalanceRebuild, error)
+}
+
+type rebuildService struct {
+	accounts repository.AccountRepository
+	repo     repository.RebuildRepository
+	ledger   LedgerService
+}
+
+func NewRebuildService(accounts repository.AccountRepository, repo repository.RebuildRepository, ledger LedgerService) RebuildService {
+	return &rebuildService{accounts: accounts, repo: repo, ledger: ledger}
+}
+
+// Preview replays the account's ledger events and compares the result with the stored account
+// without changing anything.
+func (s *rebuildService) Preview(ctx context.Context, accountID uuid.UUID) (*model.BalanceRebuildResult, error) {
+	account, err := s.accounts.GetAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.BalanceRebuildResult{
+		AccountID:      account.ID,
+		CurrentBalance: account.Balance,
+		CurrentVersion: account.Version,
+	}
+	if err := s.ledger.EachEntry(ctx, accountID, replay(result)); err != nil {
+		return nil, err
+	}
+	result.BalanceDiff = round4(result.RebuiltBalance - result.CurrentBalance)
+	result.VersionDiff = result.RebuiltVersion - result.CurrentVersion
+	return result, nil
+}
+
+// Apply writes the rebuilt balance and version with an audit record. expectedVersion is the
+// account version the caller reviewed in the preview; the rebuild is refused if the account
+// has changed since.
+func (s *rebuildService) Apply(ctx context.Context, accountID uuid.UUID, expectedVersion int64, appliedBy string, reason string) (*model.BalanceRebuildResult, error) {
+	result, err := s.Preview(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if result.CurrentVersion != expectedVersion {
+		return nil, repository.ErrAccountChanged
+	}
+
+	rebuild := &model.BalanceRebuild{
+		ID:              uuid.New(),
+		AccountID:       accountID,
+		PreviousBalance: result.CurrentBalance,
+		PreviousVersion: result.CurrentVersion,
+		RebuiltBalance:  result.RebuiltBalance,
+		RebuiltVersion:  result.RebuiltVersion,
+		EntryCount:      int(result.RebuiltVersion),
+		Source:          model.RebuildSourceLedger,
+		AppliedBy:       appliedBy,
+		Reason:          reason,
+		CreatedAt:       time.Now().UTC(),
+	}
+	if err := s.repo.ApplyRebuild(ctx, rebuild); err != nil {
+		return nil, err
+	}
+	logger.Ctx(ctx).Warn().
+		Str("accountId", accountID.String()).
+		Float64("previousBalance", rebuild.PreviousBalance).
+		Float64("rebuiltBalance", rebuild.RebuiltBalance).
+		Str("appliedBy", appliedBy).
+		Msg("Rebuilt account balance from the ledger")
+
+	result.Applied = rebuild
+	return result, nil
+}
+
+func (s *rebuildService) ListRebuilds(ctx context.Context, accountID uuid.UUID) ([]model.BalanceRebuild, error) {
+	return s.repo.ListRebuilds(ctx, accountID)
+}
+
+// replay returns the ledger entry callback that applies the credits and debits, oldest first, to
+// the rebuilt balance and version of result, keeping the latest maxRebuildEvents as its events.
+// A redelivered entry repeats its transaction with the same acceptedAt, so it follows the first
+// one and is applied once.
+func replay(result *model.BalanceRebuildResult) func(client.LedgerEntry) error {
+	var lastID string
+	return func(entry client.LedgerEntry) error {
+		if entry.ID == lastID || entry.TransactionType != "credit" && entry.TransactionType != "debit" {
+			return nil
+		}
+		lastID = entry.ID
+
+		if entry.TransactionType == "credit" {
+			result.RebuiltBalance += entry.Amount
+		} else {
+			result.RebuiltBalance -= entry.Amount
+		}
+		result.RebuiltBalance = round4(result.RebuiltBalance)
+		result.RebuiltVersion++
+
+		if len(result.Events) == maxRebuildEvents {
+			result.Events = result.Events[1:]
+		}
+		result.Events = append(result.Events, model.LedgerEvent{
+			TransactionID:   entry.ID,
+			TransactionType: entry.TransactionType,
+			Amount:          entry.Amount,
+			AcceptedAt:      entry.AcceptedAt.Format(time.RFC3339Nano),
+			Balance:         result.RebuiltBalance,
+		})
+		return nil
+	}
+}
+
+// round4 rounds to the 4 decimal places balances are stored with.
+func round4(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
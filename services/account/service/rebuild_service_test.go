@@ -0,0 +1,146 @@
+package service
+
+import (
+	"account/model"
+	"account/repository"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRebuildRepository struct {
+	mock.Mock
+}
+
+func (m *MockRebuildRepository) ApplyRebuild(ctx context.Context, rebuild *model.BalanceRebuild) error {
+	args := m.Called(ctx, rebuild)
+	return args.Error(0)
+}
+
+func (m *MockRebuildRepository) ListRebuilds(ctx context.Context, accountID uuid.UUID) ([]model.BalanceRebuild, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.BalanceRebuild), args.Error(1)
+}
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) EachEntry(ctx context.Context, accountID uuid.UUID, fn func(client.LedgerEntry) error) error {
+	args := m.Called(ctx, accountID)
+	if entries, ok := args.Get(0).([]client.LedgerEntry); ok {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockLedgerService) ClosingBalance(ctx context.Context, accountID uuid.UUID, day time.Time) (float64, error) {
+	args := m.Called(ctx, accountID, day)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+// ledgerEntries are the successful entries, oldest first, as the ledger service pages them,
+// with a redelivered duplicate and an entry that never moved money.
+var ledgerEntries = []client.LedgerEntry{
+	{ID: "t1", TransactionType: "credit", Amount: 100.0, AcceptedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+	{ID: "t2", TransactionType: "credit", Amount: 20.0, AcceptedAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+	{ID: "t2", TransactionType: "credit", Amount: 20.0, AcceptedAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+	{ID: "t3", TransactionType: "debit", Amount: 30.5, AcceptedAt: time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)},
+	{ID: "t4", TransactionType: "interest", Amount: 5.0, AcceptedAt: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)},
+}
+
+func TestPreviewRebuild(t *testing.T) {
+	mockAccounts := new(MockAccountRepository)
+	mockLedger := new(MockLedgerService)
+	service := NewRebuildService(mockAccounts, new(MockRebuildRepository), mockLedger)
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	mockAccounts.On("GetAccountByID", accountID).Return(&model.Account{ID: accountID, Balance: 1000, Version: 7}, nil)
+	mockLedger.On("EachEntry", ctx, accountID).Return(ledgerEntries, nil)
+
+	result, err := service.Preview(ctx, accountID)
+	assert.NoError(t, err)
+	assert.Equal(t, 89.5, result.RebuiltBalance)
+	assert.Equal(t, int64(3), result.RebuiltVersion)
+	assert.Equal(t, -910.5, result.BalanceDiff)
+	assert.Equal(t, int64(-4), result.VersionDiff)
+	if assert.Len(t, result.Events, 3) {
+		assert.Equal(t, "t1", result.Events[0].TransactionID)
+		assert.Equal(t, 120.0, result.Events[1].Balance)
+	}
+	assert.Nil(t, result.Applied)
+}
+
+func TestPreviewRebuild_KeepsLatestEvents(t *testing.T) {
+	mockAccounts := new(MockAccountRepository)
+	mockLedger := new(MockLedgerService)
+	service := NewRebuildService(mockAccounts, new(MockRebuildRepository), mockLedger)
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []client.LedgerEntry
+	for i := range maxRebuildEvents + 50 {
+		entries = append(entries, client.LedgerEntry{ID: fmt.Sprintf("t%d", i), TransactionType: "credit", Amount: 1, AcceptedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	mockAccounts.On("GetAccountByID", accountID).Return(&model.Account{ID: accountID, Balance: 150, Version: 150}, nil)
+	mockLedger.On("EachEntry", ctx, accountID).Return(entries, nil)
+
+	result, err := service.Preview(ctx, accountID)
+	assert.NoError(t, err)
+	assert.Equal(t, 150.0, result.RebuiltBalance)
+	assert.Equal(t, int64(150), result.RebuiltVersion)
+	if assert.Len(t, result.Events, maxRebuildEvents) {
+		assert.Equal(t, "t50", result.Events[0].TransactionID)
+		assert.Equal(t, 150.0, result.Events[maxRebuildEvents-1].Balance)
+	}
+}
+
+func TestApplyRebuild(t *testing.T) {
+	mockAccounts := new(MockAccountRepository)
+	mockRepo := new(MockRebuildRepository)
+	mockLedger := new(MockLedgerService)
+	service := NewRebuildService(mockAccounts, mockRepo, mockLedger)
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	mockAccounts.On("GetAccountByID", accountID).Return(&model.Account{ID: accountID, Balance: 1000, Version: 7}, nil)
+	mockLedger.On("EachEntry", ctx, accountID).Return(ledgerEntries, nil)
+	mockRepo.On("ApplyRebuild", ctx, mock.MatchedBy(func(rebuild *model.BalanceRebuild) bool {
+		return rebuild.PreviousBalance == 1000 && rebuild.PreviousVersion == 7 &&
+			rebuild.RebuiltBalance == 89.5 && rebuild.RebuiltVersion == 3 &&
+			rebuild.AppliedBy == "admin" && rebuild.Source == model.RebuildSourceLedger
+	})).Return(nil)
+
+	result, err := service.Apply(ctx, accountID, 7, "admin", "corrupted row")
+	assert.NoError(t, err)
+	assert.NotNil(t, result.Applied)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestApplyRebuild_AccountChanged(t *testing.T) {
+	mockAccounts := new(MockAccountRepository)
+	mockRepo := new(MockRebuildRepository)
+	mockLedger := new(MockLedgerService)
+	service := NewRebuildService(mockAccounts, mockRepo, mockLedger)
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	mockAccounts.On("GetAccountByID", accountID).Return(&model.Account{ID: accountID, Balance: 1000, Version: 8}, nil)
+	mockLedger.On("EachEntry", ctx, accountID).Return(ledgerEntries, nil)
+
+	_, err := service.Apply(ctx, accountID, 7, "admin", "corrupted row")
+	assert.ErrorIs(t, err, repository.ErrAccountChanged)
+	mockRepo.AssertNotCalled(t, "ApplyRebuild", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"ledger/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type chainHandler struct {
+	service        service.ChainService
+	accountService service.AccountService
+}
+
+type ChainHandler interface {
+	VerifyAccount(c *gin.Context)
+}
+
+func NewChainHandler(service service.ChainService, accountService service.AccountService) ChainHandler {
+	return &chainHandler{service: service, accountService: accountService}
+}
+
+// VerifyAccount walks the hash chain of an account's ledger entries and reports the first broken
+// link. A broken chain is a finding, not a failed request.
+func (h *chainHandler) VerifyAccount(c *gin.Context) {
+	accountID := c.Param("id")
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
+	result, err := h.service.Verify(c.Request.Context(), accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ledger/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockChainService struct {
+	mock.Mock
+}
+
+func (m *MockChainService) Verify(ctx context.Context, accountID string) (*model.ChainVerification, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ChainVerification), args.Error(1)
+}
+
+func (m *MockChainService) AccountIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockChainService) Anchor(ctx context.Context) (*model.ChainAnchor, bool, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*model.ChainAnchor), args.Bool(1), args.Error(2)
+}
+
+func (m *MockChainService) AcquireAnchorLease(ctx context.Context, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestVerifyAccount(t *testing.T) {
+	mockService := new(MockChainService)
+	handler := NewChainHandler(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/ledger/accounts/:id/verify", handler.VerifyAccount)
+
+	mockService.On("Verify", mock.Anything, "acc-1").Return(&model.ChainVerification{
+		AccountID: "acc-1",
+		Entries:   2,
+		BrokenAt:  3,
+		Reason:    "hash does not match the entry's content",
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/ledger/accounts/acc-1/verify", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var result model.ChainVerification
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), result.BrokenAt)
+}
+
+func TestCustomerVerifyAccess(t *testing.T) {
+	mockService := new(MockChainService)
+	mockAccountService := new(MockAccountService)
+	handler := NewChainHandler(mockService, mockAccountService)
+
+	customerID := uuid.New().String()
+	otherAccountID := uuid.New()
+	mockAccountService.On("GetAccountByID", mock.Anything, otherAccountID).Return(map[string]any{"CustomerID": uuid.New().String()}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(asCustomer(customerID))
+	router.GET("/ledger/accounts/:id/verify", handler.VerifyAccount)
+
+	req, _ := http.NewRequest(http.MethodGet, "/ledger/accounts/"+otherAccountID.String()+"/verify", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	mockService.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything)
+}
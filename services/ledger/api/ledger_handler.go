@@ -1,27 +1,42 @@
 package api
 
 import (
+	"errors"
 	"ledger/service"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+// maxEntriesPage bounds a page of account entries.
+const maxEntriesPage = 1000
+
 type ledgerHandler struct {
-	service service.LedgerService
+	service        service.LedgerService
+	accountService service.AccountService
 }
 
 type LedgerHandler interface {
 	GetAccountTransactionHistory(c *gin.Context)
+	GetAccountEntries(c *gin.Context)
 	GetTransactionHistory(c *gin.Context)
+	GetTransaction(c *gin.Context)
 }
 
-func NewledgerHandler(service service.LedgerService) LedgerHandler {
-	return &ledgerHandler{service: service}
+func NewledgerHandler(service service.LedgerService, accountService service.AccountService) LedgerHandler {
+	return &ledgerHandler{service: service, accountService: accountService}
 }
 
 func (h *ledgerHandler) GetAccountTransactionHistory(c *gin.Context) {
 	accountID := c.Param("id")
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
 	transactions, err := h.service.GetAccountTransactionHistory(c.Request.Context(), accountID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -30,6 +45,38 @@ func (h *ledgerHandler) GetAccountTransactionHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, transactions)
 }
 
+// GetAccountEntries pages through the successful entries of an account, oldest first. The next
+// page is requested with the acceptedAt and id of the last entry as afterAcceptedAt and afterId.
+func (h *ledgerHandler) GetAccountEntries(c *gin.Context) {
+	accountID := c.Param("id")
+	limit := maxEntriesPage
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxEntriesPage {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxEntriesPage)})
+			return
+		}
+	}
+	var afterAcceptedAt time.Time
+	if value := c.Query("afterAcceptedAt"); value != "" {
+		var err error
+		if afterAcceptedAt, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "afterAcceptedAt must be an RFC 3339 time"})
+			return
+		}
+	}
+
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
+	entries, err := h.service.GetAccountEntries(c.Request.Context(), accountID, afterAcceptedAt, c.Query("afterId"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
 func (h *ledgerHandler) GetTransactionHistory(c *gin.Context) {
 	accountID := c.Param("id")
 	transactions, err := h.service.GetTransactionHistory(c.Request.Context(), accountID)
@@ -37,5 +84,59 @@ func (h *ledgerHandler) GetTransactionHistory(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	checked := make(map[string]bool)
+	for _, transaction := range transactions {
+		entryAccountID, _ := transaction["accountId"].(string)
+		if checked[entryAccountID] {
+			continue
+		}
+		if !checkAccountAccess(c, h.accountService, entryAccountID) {
+			return
+		}
+		checked[entryAccountID] = true
+	}
 	c.JSON(http.StatusOK, transactions)
 }
+
+// GetTransaction returns the transaction at its latest lifecycle stage.
+func (h *ledgerHandler) GetTransaction(c *gin.Context) {
+	transaction, err := h.service.GetTransaction(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID, _ := transaction["accountId"].(string)
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
+	c.JSON(http.StatusOK, transaction)
+}
+
+// checkAccountAccess restricts customers and account-scoped API keys to the ledger entries of
+// their own accounts.
+func checkAccountAccess(c *gin.Context, accountService service.AccountService, accountID string) bool {
+	if !server.CheckAccountAllowed(c, accountID) {
+		return false
+	}
+	if _, restricted := server.CustomerScope(c); !restricted {
+		return true
+	}
+
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return server.CheckCustomerAccess(c, "")
+	}
+	account, err := accountService.GetAccountByID(c.Request.Context(), id)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return false
+	}
+	customerID, _ := account["CustomerID"].(string)
+	return server.CheckCustomerAccess(c, customerID)
+}
@@ -6,11 +6,17 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"ledger/model"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"github.com/shrishyam02/banking-ledger/common/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type MockLedgerService struct {
@@ -32,9 +38,25 @@ func (m *MockLedgerService) GetTransactionHistory(ctx context.Context, accountID
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
 
+func (m *MockLedgerService) GetTransaction(ctx context.Context, id string) (map[string]interface{}, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (m *MockLedgerService) GetAccountEntries(ctx context.Context, accountID string, afterAcceptedAt time.Time, afterID string, limit int) ([]model.LedgerEntry, error) {
+	args := m.Called(ctx, accountID, afterAcceptedAt, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.LedgerEntry), args.Error(1)
+}
+
 func TestGetAccountTransactionHistory(t *testing.T) {
 	mockService := new(MockLedgerService)
-	handler := NewledgerHandler(mockService)
+	handler := NewledgerHandler(mockService, nil)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
@@ -66,9 +88,44 @@ func TestGetAccountTransactionHistory(t *testing.T) {
 	})
 }
 
+func TestGetAccountEntries(t *testing.T) {
+	mockService := new(MockLedgerService)
+	handler := NewledgerHandler(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/ledger/accounts/:id/entries", handler.GetAccountEntries)
+
+	after := time.Date(2026, 3, 2, 10, 0, 0, 123000000, time.UTC)
+	mockService.On("GetAccountEntries", mock.Anything, "acc-1", time.Time{}, "", maxEntriesPage).Return([]model.LedgerEntry{{ID: "t1"}}, nil)
+	mockService.On("GetAccountEntries", mock.Anything, "acc-1", after, "t2", 2).Return([]model.LedgerEntry{}, nil)
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{"first page", "", http.StatusOK},
+		{"next page", "?limit=2&afterAcceptedAt=2026-03-02T10:00:00.123Z&afterId=t2", http.StatusOK},
+		{"limit too large", "?limit=5000", http.StatusBadRequest},
+		{"invalid cursor", "?afterAcceptedAt=yesterday", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/ledger/accounts/acc-1/entries"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
 func TestGetTransactionHistory(t *testing.T) {
 	mockService := new(MockLedgerService)
-	handler := NewledgerHandler(mockService)
+	handler := NewledgerHandler(mockService, nil)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
@@ -99,3 +156,96 @@ func TestGetTransactionHistory(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestGetTransaction(t *testing.T) {
+	mockService := new(MockLedgerService)
+	handler := NewledgerHandler(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/transactions/:id/current", handler.GetTransaction)
+
+	mockService.On("GetTransaction", mock.Anything, "txn-1").Return(map[string]interface{}{"id": "txn-1", "status": "success", "version": 2}, nil)
+	mockService.On("GetTransaction", mock.Anything, "missing").Return(nil, mongo.ErrNoDocuments)
+	mockService.On("GetTransaction", mock.Anything, "broken").Return(nil, errors.New("some error"))
+
+	tests := []struct {
+		name string
+		id   string
+		code int
+	}{
+		{"found", "txn-1", http.StatusOK},
+		{"not found", "missing", http.StatusNotFound},
+		{"error", "broken", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/transactions/"+tt.id+"/current", nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func asCustomer(customerID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		server.SetClaims(c, &server.Claims{Roles: []string{server.RoleCustomer}, CustomerID: customerID})
+		c.Next()
+	}
+}
+
+func TestCustomerLedgerAccess(t *testing.T) {
+	mockService := new(MockLedgerService)
+	mockAccountService := new(MockAccountService)
+	handler := NewledgerHandler(mockService, mockAccountService)
+
+	customerID := uuid.New().String()
+	ownAccountID := uuid.New()
+	otherAccountID := uuid.New()
+	mockAccountService.On("GetAccountByID", mock.Anything, ownAccountID).Return(map[string]any{"CustomerID": customerID}, nil)
+	mockAccountService.On("GetAccountByID", mock.Anything, otherAccountID).Return(map[string]any{"CustomerID": uuid.New().String()}, nil)
+	mockService.On("GetAccountTransactionHistory", mock.Anything, ownAccountID.String()).Return([]map[string]interface{}{{"id": "1"}}, nil)
+	mockService.On("GetTransactionHistory", mock.Anything, "txn-1").Return([]map[string]interface{}{{"id": "1", "accountId": otherAccountID.String()}}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(asCustomer(customerID))
+	router.GET("/ledger/accounts/:id", handler.GetAccountTransactionHistory)
+	router.GET("/ledger/transactions/:id", handler.GetTransactionHistory)
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"own account", "/ledger/accounts/" + ownAccountID.String(), http.StatusOK},
+		{"other account", "/ledger/accounts/" + otherAccountID.String(), http.StatusForbidden},
+		{"other account transaction", "/ledger/transactions/txn-1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+	mockService.AssertNotCalled(t, "GetAccountTransactionHistory", mock.Anything, otherAccountID.String())
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ledger/model"
+	"ledger/repository"
+	"ledger/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type reconciliationHandler struct {
+	service service.ReconciliationService
+}
+
+type ReconciliationHandler interface {
+	RunReconciliation(c *gin.Context)
+	ListRuns(c *gin.Context)
+	GetReport(c *gin.Context)
+}
+
+func NewReconciliationHandler(service service.ReconciliationService) ReconciliationHandler {
+	return &reconciliationHandler{service: service}
+}
+
+type runReconciliationRequest struct {
+	Mode string `json:"mode"`
+}
+
+// RunReconciliation runs a reconciliation and returns its report. Mode defaults to incremental.
+func (h *reconciliationHandler) RunReconciliation(c *gin.Context) {
+	var req runReconciliationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = model.ReconciliationIncremental
+	case model.ReconciliationFull, model.ReconciliationIncremental:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be full or incremental"})
+		return
+	}
+
+	// Finish the run even if the client goes away
+	report, err := h.service.Run(context.WithoutCancel(c.Request.Context()), req.Mode)
+	if errors.Is(err, repository.ErrReconciliationLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *reconciliationHandler) ListRuns(c *gin.Context) {
+	limit := 20
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+	}
+
+	runs, err := h.service.ListRuns(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetReport returns a run with the breaks it found.
+func (h *reconciliationHandler) GetReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	report, err := h.service.GetReport(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reconciliation run not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ledger/model"
+	"ledger/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockReconciliationService struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationService) Run(ctx context.Context, mode string) (*model.ReconciliationReport, error) {
+	args := m.Called(ctx, mode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReconciliationReport), args.Error(1)
+}
+
+func (m *MockReconciliationService) GetReport(ctx context.Context, id uuid.UUID) (*model.ReconciliationReport, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReconciliationReport), args.Error(1)
+}
+
+func (m *MockReconciliationService) ListRuns(ctx context.Context, limit int) ([]model.ReconciliationRun, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]model.ReconciliationRun), args.Error(1)
+}
+
+func TestRunReconciliation(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	handler := NewReconciliationHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/reconciliations", handler.RunReconciliation)
+
+	mockService.On("Run", mock.Anything, model.ReconciliationIncremental).Return(&model.ReconciliationReport{}, nil).Once()
+	mockService.On("Run", mock.Anything, model.ReconciliationFull).Return(nil, repository.ErrReconciliationLocked).Once()
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"default mode", "", http.StatusCreated},
+		{"already running", `{"mode": "full"}`, http.StatusConflict},
+		{"invalid mode", `{"mode": "partial"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/reconciliations", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetReconciliationReport(t *testing.T) {
+	mockService := new(MockReconciliationService)
+	handler := NewReconciliationHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/reconciliations/:id", handler.GetReport)
+
+	found, missing, broken := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("GetReport", mock.Anything, found).Return(&model.ReconciliationReport{}, nil)
+	mockService.On("GetReport", mock.Anything, missing).Return(nil, gorm.ErrRecordNotFound)
+	mockService.On("GetReport", mock.Anything, broken).Return(nil, errors.New("some error"))
+
+	tests := []struct {
+		name string
+		id   string
+		code int
+	}{
+		{"found", found.String(), http.StatusOK},
+		{"not found", missing.String(), http.StatusNotFound},
+		{"error", broken.String(), http.StatusInternalServerError},
+		{"invalid id", "latest", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/reconciliations/"+tt.id, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
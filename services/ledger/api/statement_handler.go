@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"ledger/model"
+	"ledger/service"
+	"ledger/statement"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var periodPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+type statementHandler struct {
+	service        service.StatementService
+	accountService service.AccountService
+	now            func() time.Time
+}
+
+type StatementHandler interface {
+	GetStatement(c *gin.Context)
+	ListStatements(c *gin.Context)
+	DownloadStatement(c *gin.Context)
+}
+
+func NewStatementHandler(service service.StatementService, accountService service.AccountService) StatementHandler {
+	return &statementHandler{
+		service:        service,
+		accountService: accountService,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetStatement streams the statement of an account between from and to (YYYY-MM-DD, both
+// inclusive). The period defaults to the current month to date.
+func (h *statementHandler) GetStatement(c *gin.Context) {
+	accountID := c.Param("id")
+	format := c.DefaultQuery("format", model.StatementCSV)
+
+	now := h.now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(time.DateOnly, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(time.DateOnly, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+	}
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
+	w, err := statement.NewWriter(format, c.Writer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", statement.ContentType(format))
+	c.Header("Content-Disposition", attachment(accountID, from.Format(time.DateOnly)+"_"+to.AddDate(0, 0, -1).Format(time.DateOnly), statement.Extension(format)))
+	if err := h.service.Generate(c.Request.Context(), accountID, from, to, w); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// The status is already sent; the statement ends without its totals
+		logger.Ctx(c.Request.Context()).Error().Err(err).Msgf("Statement for account %s failed while streaming", accountID)
+	}
+}
+
+// ListStatements lists the stored month-end statements of an account.
+func (h *statementHandler) ListStatements(c *gin.Context) {
+	accountID := c.Param("id")
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
+	statements, err := h.service.ListStored(c.Request.Context(), accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, statements)
+}
+
+// DownloadStatement returns a stored month-end statement; period is YYYY-MM.
+func (h *statementHandler) DownloadStatement(c *gin.Context) {
+	accountID := c.Param("id")
+	period := c.Param("period")
+	format := c.DefaultQuery("format", model.StatementCSV)
+	if !periodPattern.MatchString(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be in YYYY-MM format"})
+		return
+	}
+	if !checkAccountAccess(c, h.accountService, accountID) {
+		return
+	}
+
+	stored, err := h.service.GetStored(c.Request.Context(), accountID, period, format)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Statement not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", attachment(accountID, period, statement.Extension(format)))
+	c.Data(http.StatusOK, statement.ContentType(format), stored.Content)
+}
+
+func attachment(accountID, period, extension string) string {
+	return fmt.Sprintf(`attachment; filename="statement_%s_%s.%s"`, accountID, period, extension)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ledger/model"
+	"ledger/statement"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MockStatementService struct {
+	mock.Mock
+}
+
+func (m *MockStatementService) Generate(ctx context.Context, accountID string, from, to time.Time, w statement.Writer) error {
+	args := m.Called(ctx, accountID, from, to, w)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	w.Begin(model.StatementHeader{AccountID: accountID, From: from, To: to})
+	return w.End(model.StatementTotals{})
+}
+
+func (m *MockStatementService) GenerateMonth(ctx context.Context, month time.Time) error {
+	args := m.Called(ctx, month)
+	return args.Error(0)
+}
+
+func (m *MockStatementService) ListStored(ctx context.Context, accountID string) ([]model.StoredStatement, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.StoredStatement), args.Error(1)
+}
+
+func (m *MockStatementService) GetStored(ctx context.Context, accountID, period, format string) (*model.StoredStatement, error) {
+	args := m.Called(ctx, accountID, period, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.StoredStatement), args.Error(1)
+}
+
+func TestGetStatement(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/ledger/accounts/:id/statement", handler.GetStatement)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("Generate", mock.Anything, "acc-1", from, to, mock.Anything).Return(nil)
+	mockService.On("Generate", mock.Anything, "broken", from, to, mock.Anything).Return(errors.New("some error"))
+
+	tests := []struct {
+		name        string
+		query       string
+		code        int
+		contentType string
+	}{
+		{"csv", "acc-1/statement?from=2026-09-01&to=2026-09-30", http.StatusOK, "text/csv; charset=utf-8"},
+		{"json", "acc-1/statement?from=2026-09-01&to=2026-09-30&format=json", http.StatusOK, "application/json; charset=utf-8"},
+		{"camt.053", "acc-1/statement?from=2026-09-01&to=2026-09-30&format=camt053", http.StatusOK, "application/xml; charset=utf-8"},
+		{"unknown format", "acc-1/statement?from=2026-09-01&to=2026-09-30&format=pdf", http.StatusBadRequest, ""},
+		{"invalid date", "acc-1/statement?from=01-09-2026", http.StatusBadRequest, ""},
+		{"reversed period", "acc-1/statement?from=2026-09-30&to=2026-09-01", http.StatusBadRequest, ""},
+		{"error", "broken/statement?from=2026-09-01&to=2026-09-30", http.StatusInternalServerError, "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/ledger/accounts/"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, resp.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestDownloadStatement(t *testing.T) {
+	mockService := new(MockStatementService)
+	handler := NewStatementHandler(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/ledger/accounts/:id/statements/:period", handler.DownloadStatement)
+
+	mockService.On("GetStored", mock.Anything, "acc-1", "2026-09", "txt").Return(&model.StoredStatement{Content: []byte("ACCOUNT STATEMENT\n")}, nil)
+	mockService.On("GetStored", mock.Anything, "acc-1", "2026-08", "csv").Return(nil, mongo.ErrNoDocuments)
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"stored", "acc-1/statements/2026-09?format=txt", http.StatusOK},
+		{"not generated", "acc-1/statements/2026-08", http.StatusNotFound},
+		{"invalid period", "acc-1/statements/2026-13", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/ledger/accounts/"+tt.path, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
+
+func TestCustomerStatementAccess(t *testing.T) {
+	mockService := new(MockStatementService)
+	mockAccountService := new(MockAccountService)
+	handler := NewStatementHandler(mockService, mockAccountService)
+
+	customerID := uuid.New().String()
+	otherAccountID := uuid.New()
+	mockAccountService.On("GetAccountByID", mock.Anything, otherAccountID).Return(map[string]any{"CustomerID": uuid.New().String()}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(asCustomer(customerID))
+	router.GET("/ledger/accounts/:id/statement", handler.GetStatement)
+
+	req, _ := http.NewRequest(http.MethodGet, "/ledger/accounts/"+otherAccountID.String()+"/statement", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	mockService.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
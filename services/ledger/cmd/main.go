@@ -2,28 +2,64 @@ package main
 
 import (
 	"context"
+	"io"
 	"ledger/api"
+	"ledger/repository"
+	"ledger/scheduler"
 	"ledger/service"
+	"ledger/statement"
 	"log"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/segmentio/kafka-go"
+	"github.com/shrishyam02/banking-ledger/common/apikey"
+	"github.com/shrishyam02/banking-ledger/common/app"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/auth"
 	"github.com/shrishyam02/banking-ledger/common/config"
 	"github.com/shrishyam02/banking-ledger/common/db"
 	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/shrishyam02/banking-ledger/common/logger"
 	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
 )
 
 func main() {
-	logger.InitLogger()
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	logger.InitLogger(logger.Options{
+		Service: config.LedgerService,
+		Version: cfg.Logging.Version,
+		Level:   cfg.Services[config.LedgerService].LogLevel,
+		Format:  cfg.Logging.Format,
+	})
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if err := runReconcile(context.Background(), cfg, os.Args[2:]); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Reconciliation failed")
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		if err := runVerify(context.Background(), cfg, os.Args[2:]); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Ledger verification failed")
+		}
+		return
+	}
 	logger.Log.Info().Msg("Initialized logger for service: " + config.LedgerService)
 
+	shutdownTracing, err := tracing.Init(context.Background(), config.LedgerService, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	lifecycle := app.NewLifecycle(config.LedgerService, cfg.Lifecycle.ShutdownTimeout)
+	lifecycle.OnStop("tracing", shutdownTracing)
+
 	brokers := strings.Split(cfg.Kafka.Brokers, ",")
 	consumerTopics := []string{"ledger-topic"}
 	consumerGroup := "ledger-group"
@@ -37,42 +73,188 @@ func main() {
 		logger.Log.Info().Msgf("kafka broker string slice %s", brokers)
 	}
 
-	consumer := ckafka.NewKafkaConsumer(brokers, consumerGroup, consumerTopics)
+	health := server.NewHealth()
+	consumer := ckafka.NewKafkaConsumer(brokers, consumerGroup, consumerTopics,
+		ckafka.WithHeartbeat(health.Heartbeat("ledger-consumer", time.Minute)))
+	lifecycle.OnStop("kafka consumer", func(context.Context) error { return consumer.Close() })
+	health.AddReadinessCheck("kafka", ckafka.BrokerHealthCheck(brokers))
 
 	mongoClient, err := db.ConnectMongo(cfg.Database.MongoDBConnectionString)
 	if err != nil {
 		logger.Log.Fatal().Err(err).Msg("Failed to connect to MongoDB")
 	}
 	logger.Log.Info().Msg("Connected to MongoDB: " + config.LedgerService)
+	lifecycle.OnStop("mongo", mongoClient.Disconnect)
+	health.AddReadinessCheck("mongo", db.MongoHealthCheck(mongoClient))
 
 	mongoDB := mongoClient.Database("banking_ledger_db")
+	if err := service.EnsureSchema(context.Background(), repository.NewSchemaRepository(mongoDB)); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to apply ledger indexes and schema validation")
+	}
 	ledgerService := service.NewledgerService(mongoDB)
-	ledgerHandler := api.NewledgerHandler(ledgerService)
+	ledgerRepository := repository.NewLedgerRepository(mongoDB.Collection("transactions"))
+
+	// Ownership of ledger entries is checked against the account service for customers
+	accountServiceURL := os.Getenv("ACCOUNT_SERVICE_URL")
+	if accountServiceURL == "" {
+		log.Fatal("ACCOUNT_SERVICE_URL environment variable is required")
+	}
+	accountService := service.NewAccountService(accountServiceURL, auth.NewClient(context.Background(), cfg.Auth, cfg.ApiAuth))
+	ledgerHandler := api.NewledgerHandler(ledgerService, accountService)
+
+	// Month-end statements are stored in each configured format; the ledger keeps amounts in a
+	// single currency, named in the camt.053 and MT940 exports
+	statementFormats := strings.Split(getEnv("STATEMENT_FORMATS", "csv"), ",")
+	for _, format := range statementFormats {
+		if _, err := statement.NewWriter(format, io.Discard); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Invalid STATEMENT_FORMATS")
+		}
+	}
+	statementService := service.NewStatementService(ledgerRepository,
+		repository.NewStatementRepository(mongoDB.Collection("statements")),
+		statementFormats, getEnv("STATEMENT_CURRENCY", "USD"))
+	statementHandler := api.NewStatementHandler(statementService, accountService)
+	statementScheduler := scheduler.NewStatementScheduler(statementService)
+
+	// Ledger entries are hash-chained per account; the heads of the chains are anchored in a
+	// Merkle root every LEDGER_ANCHOR_INTERVAL, 0 disabling the schedule
+	anchorInterval, err := time.ParseDuration(getEnv("LEDGER_ANCHOR_INTERVAL", "1h"))
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Invalid LEDGER_ANCHOR_INTERVAL")
+	}
+	chainRepository := repository.NewChainRepository(mongoDB.Collection("transactions"),
+		mongoDB.Collection("ledger_anchors"), mongoDB.Collection("ledger_anchor_heads"), mongoDB.Collection("ledger_leases"))
+
+	// API keys, the audit log and reconciliation results are stored in Postgres; they are
+	// enabled when a connection is configured
+	var apiKeyService apikey.Service
+	var auditLog audit.Recorder
+	var reconciliationHandler api.ReconciliationHandler
+	var reconciliationScheduler scheduler.ReconciliationScheduler
+	var chainService service.ChainService
+	if cfg.Database.PostgresConnectionString != "" {
+		pgDb, err := db.ConnectPostgres(cfg.Database.PostgresConnectionString)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to connect to postgres")
+		}
+		if err := db.CheckSchemaVersion(context.Background(), pgDb); err != nil {
+			logger.Log.Fatal().Err(err).Msg("Database schema is older than this build; run `account migrate up`")
+		}
+		apiKeyService = apikey.NewService(apikey.NewRepository(pgDb))
+		auditLog = audit.NewLog(pgDb, config.LedgerService)
+		health.AddReadinessCheck("postgres", db.PostgresHealthCheck(pgDb))
+		lifecycle.OnStop("postgres", func(context.Context) error { return db.ClosePostgres(pgDb) })
+
+		// Anchor roots are copied to the audit log, out of reach of whoever can rewrite MongoDB
+		chainService = service.NewChainService(chainRepository, auditLog)
+
+		reconciliationService := service.NewReconciliationService(
+			repository.NewReconciliationRepository(pgDb), ledgerRepository)
+		reconciliationHandler = api.NewReconciliationHandler(reconciliationService)
+
+		interval, err := time.ParseDuration(getEnv("RECONCILIATION_INTERVAL", "1h"))
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Invalid RECONCILIATION_INTERVAL")
+		}
+		if interval > 0 {
+			reconciliationScheduler = scheduler.NewReconciliationScheduler(reconciliationService, interval)
+		}
+	}
+
+	if chainService == nil {
+		chainService = service.NewChainService(chainRepository, nil)
+	}
+	chainHandler := api.NewChainHandler(chainService, accountService)
+	var anchorScheduler scheduler.AnchorScheduler
+	if anchorInterval > 0 {
+		anchorScheduler = scheduler.NewAnchorScheduler(chainService, anchorInterval)
+	}
 
 	registerHandlers := func(apiGroup *gin.RouterGroup) {
 		ledger := apiGroup.Group("/ledger")
 		{
 			ledger.GET("/accounts/:id", ledgerHandler.GetAccountTransactionHistory)
+			ledger.GET("/accounts/:id/entries", ledgerHandler.GetAccountEntries)
 			ledger.GET("/transactions/:id", ledgerHandler.GetTransactionHistory)
+			ledger.GET("/transactions/:id/current", ledgerHandler.GetTransaction)
+			ledger.GET("/accounts/:id/statement", statementHandler.GetStatement)
+			ledger.GET("/accounts/:id/statements", statementHandler.ListStatements)
+			ledger.GET("/accounts/:id/statements/:period", statementHandler.DownloadStatement)
+			ledger.GET("/accounts/:id/verify", chainHandler.VerifyAccount)
+		}
+		if reconciliationHandler != nil {
+			reconciliations := apiGroup.Group("/ledger/reconciliations")
+			{
+				reconciliations.POST("", reconciliationHandler.RunReconciliation)
+				reconciliations.GET("", reconciliationHandler.ListRuns)
+				reconciliations.GET("/:id", reconciliationHandler.GetReport)
+			}
 		}
 	}
 	logger.Log.Info().Msg("Handlers for: " + config.LedgerService)
 
 	serverConfig := server.Config{
-		Port:        cfg.Services[config.LedgerService].Port,
-		ServiceName: config.LedgerService,
-		ApiAuth:     cfg.ApiAuth,
+		Port:            cfg.Services[config.LedgerService].Port,
+		ServiceName:     config.LedgerService,
+		ApiAuth:         cfg.ApiAuth,
+		Auth:            cfg.Auth,
+		RateLimit:       cfg.RateLimit,
+		Health:          health,
+		ShutdownTimeout: cfg.Lifecycle.ShutdownTimeout,
+		Permissions: server.RoutePermissions{
+			"GET /api/v1/ledger/accounts/:id":                    server.PermLedgerRead,
+			"GET /api/v1/ledger/accounts/:id/entries":            server.PermLedgerRead,
+			"GET /api/v1/ledger/transactions/:id":                server.PermLedgerRead,
+			"GET /api/v1/ledger/transactions/:id/current":        server.PermLedgerRead,
+			"GET /api/v1/ledger/accounts/:id/statement":          server.PermLedgerRead,
+			"GET /api/v1/ledger/accounts/:id/statements":         server.PermLedgerRead,
+			"GET /api/v1/ledger/accounts/:id/statements/:period": server.PermLedgerRead,
+			"GET /api/v1/ledger/accounts/:id/verify":             server.PermLedgerRead,
+
+			"POST /api/v1/ledger/reconciliations":    server.PermReconciliationRun,
+			"GET /api/v1/ledger/reconciliations":     server.PermReconciliationRead,
+			"GET /api/v1/ledger/reconciliations/:id": server.PermReconciliationRead,
+		},
+		APIKeys: apiKeyService,
+		Audit:   auditLog,
 	}
 
-	ctx := context.Background()
+	lifecycle.Go("ledger consumer", func(ctx context.Context) error {
+		return consumer.Consume(ctx, consumerTopics[0], consumerGroup, func(msg kafka.Message) error {
+			// Shutdown must not cancel a message halfway through
+			msgCtx, span := ckafka.StartProcessSpan(context.WithoutCancel(ctx), msg, consumerGroup)
+			defer span.End()
+			return ledgerService.HandleMessage(msgCtx, msg)
+		})
+	})
+	lifecycle.Go("statement scheduler", func(ctx context.Context) error {
+		statementScheduler.Run(ctx)
+		return nil
+	})
+	if reconciliationScheduler != nil {
+		lifecycle.Go("reconciliation scheduler", func(ctx context.Context) error {
+			reconciliationScheduler.Run(ctx)
+			return nil
+		})
+	}
+	if anchorScheduler != nil {
+		lifecycle.Go("anchor scheduler", func(ctx context.Context) error {
+			anchorScheduler.Run(ctx)
+			return nil
+		})
+	}
+	lifecycle.Go("http server", func(ctx context.Context) error {
+		server.RunServer(ctx, serverConfig, registerHandlers)
+		return nil
+	})
+	if err := lifecycle.Wait(); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Unclean shutdown")
+	}
+}
 
-	go func() {
-		logger.Log.Info().Msg("Starting to process ledger transactions")
-		if err := consumer.Consume(ctx, consumerTopics[0], consumerGroup, func(msg kafka.Message) error {
-			return ledgerService.HandleMessage(ctx, msg)
-		}); err != nil {
-			log.Fatalf("Failed to process ledger transactions: %v", err)
-		}
-	}()
-	server.RunServer(ctx, serverConfig, registerHandlers)
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ledger/model"
+	"ledger/repository"
+	"ledger/service"
+	"os"
+
+	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/db"
+)
+
+const reconcileUsage = `usage: ledger reconcile [full|incremental]
+
+Compares account balances with the ledger once and prints the report.
+Runs incrementally from the last completed run by default.`
+
+// runReconcile runs a single reconciliation outside the server, e.g. from a cron job.
+func runReconcile(ctx context.Context, cfg *config.Config, args []string) error {
+	mode := model.ReconciliationIncremental
+	if len(args) > 0 {
+		mode = args[0]
+	}
+	if len(args) > 1 || mode != model.ReconciliationFull && mode != model.ReconciliationIncremental {
+		return errors.New(reconcileUsage)
+	}
+
+	pgDb, err := db.ConnectPostgres(cfg.Database.PostgresConnectionString)
+	if err != nil {
+		return err
+	}
+	defer db.ClosePostgres(pgDb)
+	if err := db.CheckSchemaVersion(ctx, pgDb); err != nil {
+		return err
+	}
+
+	mongoClient, err := db.ConnectMongo(cfg.Database.MongoDBConnectionString)
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Disconnect(context.WithoutCancel(ctx))
+
+	reconciliationService := service.NewReconciliationService(
+		repository.NewReconciliationRepository(pgDb),
+		repository.NewLedgerRepository(mongoClient.Database("banking_ledger_db").Collection("transactions")))
+	report, err := reconciliationService.Run(ctx, mode)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ledger/model"
+	"ledger/repository"
+	"ledger/service"
+	"os"
+
+	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/db"
+)
+
+const verifyUsage = `usage: ledger verify [account-id...]
+
+Walks the hash chain of each account's ledger entries and prints the result.
+Verifies every chained account when none is given; exits non-zero if a chain is broken.`
+
+// runVerify verifies account chains outside the server, e.g. from a cron job.
+func runVerify(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		fmt.Println(verifyUsage)
+		return nil
+	}
+
+	mongoClient, err := db.ConnectMongo(cfg.Database.MongoDBConnectionString)
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Disconnect(context.WithoutCancel(ctx))
+
+	mongoDB := mongoClient.Database("banking_ledger_db")
+	chainService := service.NewChainService(repository.NewChainRepository(
+		mongoDB.Collection("transactions"),
+		mongoDB.Collection("ledger_anchors"),
+		mongoDB.Collection("ledger_anchor_heads"),
+		mongoDB.Collection("ledger_leases")), nil)
+
+	accountIDs := args
+	if len(accountIDs) == 0 {
+		if accountIDs, err = chainService.AccountIDs(ctx); err != nil {
+			return err
+		}
+	}
+
+	results := make([]*model.ChainVerification, 0, len(accountIDs))
+	broken := 0
+	for _, accountID := range accountIDs {
+		result, err := chainService.Verify(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if !result.Valid {
+			broken++
+		}
+		results = append(results, result)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return err
+	}
+	if broken > 0 {
+		return fmt.Errorf("%d of %d account chains are broken", broken, len(results))
+	}
+	return nil
+}
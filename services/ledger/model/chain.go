@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// ChainHead is the last entry of an account's hash chain.
+type ChainHead struct {
+	AccountID string `json:"accountId" bson:"accountId"`
+	Seq       int64  `json:"seq" bson:"seq"`
+	Hash      string `json:"hash" bson:"hash"`
+}
+
+// ChainAnchor is a Merkle root over the heads of every account chain at one point in time. The
+// heads it covers are stored with it, so a chain that lost entries since is caught.
+type ChainAnchor struct {
+	ID        string    `json:"id" bson:"id"`
+	Root      string    `json:"root" bson:"root"`
+	Accounts  int       `json:"accounts" bson:"accounts"`
+	Entries   int64     `json:"entries" bson:"entries"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// AnchoredHead is an account's chain head as covered by an anchor.
+type AnchoredHead struct {
+	AnchorID  string    `json:"anchorId" bson:"anchorId"`
+	AccountID string    `json:"accountId" bson:"accountId"`
+	Seq       int64     `json:"seq" bson:"seq"`
+	Hash      string    `json:"hash" bson:"hash"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// ChainVerification is the result of walking an account's hash chain. BrokenAt is the sequence
+// number of the first entry that is missing, altered, or out of line with the last anchor.
+// Unchained entries were recorded before the ledger was chained and are not covered.
+type ChainVerification struct {
+	AccountID     string `json:"accountId"`
+	Valid         bool   `json:"valid"`
+	Entries       int64  `json:"entries"`
+	Unchained     int64  `json:"unchained"`
+	HeadSeq       int64  `json:"headSeq"`
+	HeadHash      string `json:"headHash,omitempty"`
+	AnchorID      string `json:"anchorId,omitempty"`
+	AnchoredSeq   int64  `json:"anchoredSeq,omitempty"`
+	BrokenAt      int64  `json:"brokenAt,omitempty"`
+	TransactionID string `json:"transactionId,omitempty"`
+	Status        string `json:"status,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
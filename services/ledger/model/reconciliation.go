@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reconciliation modes. A full run checks every account; an incremental run checks accounts
+// changed since the previous completed run, plus the accounts that were out of balance then.
+const (
+	ReconciliationFull        = "full"
+	ReconciliationIncremental = "incremental"
+)
+
+// Reconciliation run statuses.
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+)
+
+// ReconciliationRun is one comparison of the account balances in Postgres with the ledger.
+type ReconciliationRun struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	Mode            string     `json:"mode" gorm:"type:varchar(20);not null"`
+	Status          string     `json:"status" gorm:"type:varchar(20);not null"`
+	WatermarkFrom   *time.Time `json:"watermarkFrom,omitempty" gorm:"type:timestamp with time zone"`
+	WatermarkTo     time.Time  `json:"watermarkTo" gorm:"type:timestamp with time zone;not null"`
+	AccountsChecked int        `json:"accountsChecked" gorm:"not null"`
+	BreaksFound     int        `json:"breaksFound" gorm:"not null"`
+	Error           string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt       time.Time  `json:"startedAt" gorm:"type:timestamp with time zone;not null"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty" gorm:"type:timestamp with time zone"`
+}
+
+// ReconciliationBreak is an account whose balance disagrees with the sum of its successful
+// ledger entries. AccountBalance is nil when the account only exists in the ledger.
+type ReconciliationBreak struct {
+	RunID          uuid.UUID     `json:"runId" gorm:"type:uuid;primaryKey"`
+	AccountID      string        `json:"accountId" gorm:"type:varchar(64);primaryKey"`
+	AccountBalance *float64      `json:"accountBalance" gorm:"type:decimal(19,4)"`
+	LedgerBalance  float64       `json:"ledgerBalance" gorm:"type:decimal(19,4);not null"`
+	Difference     float64       `json:"difference" gorm:"type:decimal(19,4);not null"`
+	EntryCount     int           `json:"entryCount" gorm:"not null"`
+	Transactions   []LedgerEntry `json:"transactions" gorm:"type:jsonb;serializer:json;not null"`
+}
+
+// ReconciliationReport is a run with the breaks it found.
+type ReconciliationReport struct {
+	ReconciliationRun
+	Breaks []ReconciliationBreak `json:"breaks"`
+}
+
+// LedgerEntry is a successful ledger entry contributing to an account's ledger balance.
+type LedgerEntry struct {
+	ID              string    `json:"id" bson:"id"`
+	TransactionType string    `json:"transactionType" bson:"transactionType"`
+	Amount          float64   `json:"amount" bson:"amount"`
+	Category        string    `json:"category,omitempty" bson:"category,omitempty"`
+	Details         string    `json:"details,omitempty" bson:"details,omitempty"`
+	AcceptedAt      time.Time `json:"acceptedAt" bson:"acceptedAt"`
+}
+
+// LedgerTotal is the balance implied by an account's successful ledger entries.
+type LedgerTotal struct {
+	Balance    float64
+	EntryCount int
+}
@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// Statement formats.
+const (
+	StatementCSV  = "csv"
+	StatementJSON = "json"
+	StatementText = "txt"
+	// Bank statement interchange formats for treasury and accounting systems
+	StatementCAMT053 = "camt053"
+	StatementMT940   = "mt940"
+)
+
+// Entry categories broken out in statement totals. Entries without a category are plain
+// credits and debits.
+const (
+	CategoryInterest = "interest"
+	CategoryFee      = "fee"
+)
+
+// StatementHeader opens a statement. To is exclusive.
+type StatementHeader struct {
+	AccountID      string    `json:"accountId"`
+	Currency       string    `json:"currency"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance float64   `json:"openingBalance"`
+	GeneratedAt    time.Time `json:"generatedAt"`
+}
+
+// StatementLine is a successful ledger entry with the balance after it. Amount is negative for
+// debits.
+type StatementLine struct {
+	Date          time.Time `json:"date"`
+	TransactionID string    `json:"transactionId"`
+	Description   string    `json:"description"`
+	Category      string    `json:"category,omitempty"`
+	Type          string    `json:"type"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+}
+
+// StatementTotals closes a statement. Interest and fees are also counted in credits and debits.
+type StatementTotals struct {
+	EntryCount     int     `json:"entryCount"`
+	Credits        float64 `json:"credits"`
+	Debits         float64 `json:"debits"`
+	Interest       float64 `json:"interest"`
+	Fees           float64 `json:"fees"`
+	ClosingBalance float64 `json:"closingBalance"`
+}
+
+// StoredStatement is a statement generated at month-end and kept for download.
+type StoredStatement struct {
+	AccountID      string    `json:"accountId" bson:"accountId"`
+	Period         string    `json:"period" bson:"period"`
+	Format         string    `json:"format" bson:"format"`
+	From           time.Time `json:"from" bson:"from"`
+	To             time.Time `json:"to" bson:"to"`
+	OpeningBalance float64   `json:"openingBalance" bson:"openingBalance"`
+	ClosingBalance float64   `json:"closingBalance" bson:"closingBalance"`
+	EntryCount     int       `json:"entryCount" bson:"entryCount"`
+	Content        []byte    `json:"-" bson:"content"`
+	GeneratedAt    time.Time `json:"generatedAt" bson:"generatedAt"`
+}
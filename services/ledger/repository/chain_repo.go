@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ledger/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// anchorHeadBatchSize bounds the heads inserted per round trip when an anchor is saved.
+const anchorHeadBatchSize = 1000
+
+// chained selects the entries recorded since the ledger was chained.
+var chained = bson.M{"$exists": true}
+
+// ChainRepository reads the hash chains of the ledger entries and keeps the anchors over them.
+type ChainRepository interface {
+	// StreamChain calls fn with the chained entries of an account in chain order.
+	StreamChain(ctx context.Context, accountID string, fn func(map[string]interface{}) error) error
+	CountUnchained(ctx context.Context, accountID string) (int64, error)
+	ChainedAccountIDs(ctx context.Context) ([]string, error)
+	Heads(ctx context.Context) ([]model.ChainHead, error)
+	LatestAnchor(ctx context.Context) (*model.ChainAnchor, error)
+	SaveAnchor(ctx context.Context, anchor *model.ChainAnchor, heads []model.ChainHead) error
+	LatestAnchoredHead(ctx context.Context, accountID string) (*model.AnchoredHead, error)
+	// AcquireLease takes or renews the lease called name for owner until ttl from now. It reports
+	// false while another owner holds an unexpired lease.
+	AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error)
+}
+
+type chainRepository struct {
+	entries     *mongo.Collection
+	anchors     *mongo.Collection
+	anchorHeads *mongo.Collection
+	leases      *mongo.Collection
+}
+
+func NewChainRepository(entries, anchors, anchorHeads, leases *mongo.Collection) ChainRepository {
+	return &chainRepository{entries: entries, anchors: anchors, anchorHeads: anchorHeads, leases: leases}
+}
+
+func (r *chainRepository) StreamChain(ctx context.Context, accountID string, fn func(map[string]interface{}) error) error {
+	cursor, err := r.entries.Find(ctx,
+		bson.M{"accountId": accountID, "chainSeq": chained},
+		options.Find().SetSort(bson.D{{Key: "chainSeq", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry map[string]interface{}
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (r *chainRepository) CountUnchained(ctx context.Context, accountID string) (int64, error) {
+	return r.entries.CountDocuments(ctx, bson.M{"accountId": accountID, "chainSeq": bson.M{"$exists": false}})
+}
+
+func (r *chainRepository) ChainedAccountIDs(ctx context.Context) ([]string, error) {
+	var accountIDs []string
+	if err := r.entries.Distinct(ctx, "accountId", bson.M{"chainSeq": chained}).Decode(&accountIDs); err != nil {
+		return nil, err
+	}
+	return accountIDs, nil
+}
+
+// Heads returns the last chained entry of every account.
+func (r *chainRepository) Heads(ctx context.Context) ([]model.ChainHead, error) {
+	cursor, err := r.entries.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"chainSeq": chained}}},
+		{{Key: "$sort", Value: bson.D{{Key: "accountId", Value: 1}, {Key: "chainSeq", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":  "$accountId",
+			"seq":  bson.M{"$first": "$chainSeq"},
+			"hash": bson.M{"$first": "$hash"},
+		}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "accountId": "$_id", "seq": 1, "hash": 1}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var heads []model.ChainHead
+	if err := cursor.All(ctx, &heads); err != nil {
+		return nil, err
+	}
+	return heads, nil
+}
+
+func (r *chainRepository) LatestAnchor(ctx context.Context) (*model.ChainAnchor, error) {
+	var anchor model.ChainAnchor
+	err := r.anchors.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})).Decode(&anchor)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &anchor, nil
+}
+
+// SaveAnchor stores the heads before the anchor, so the latest anchor always has its heads.
+func (r *chainRepository) SaveAnchor(ctx context.Context, anchor *model.ChainAnchor, heads []model.ChainHead) error {
+	for start := 0; start < len(heads); start += anchorHeadBatchSize {
+		end := min(start+anchorHeadBatchSize, len(heads))
+		documents := make([]model.AnchoredHead, 0, end-start)
+		for _, head := range heads[start:end] {
+			documents = append(documents, model.AnchoredHead{
+				AnchorID:  anchor.ID,
+				AccountID: head.AccountID,
+				Seq:       head.Seq,
+				Hash:      head.Hash,
+				CreatedAt: anchor.CreatedAt,
+			})
+		}
+		if _, err := r.anchorHeads.InsertMany(ctx, documents); err != nil {
+			return err
+		}
+	}
+	_, err := r.anchors.InsertOne(ctx, anchor)
+	return err
+}
+
+func (r *chainRepository) LatestAnchoredHead(ctx context.Context, accountID string) (*model.AnchoredHead, error) {
+	var head model.AnchoredHead
+	err := r.anchorHeads.FindOne(ctx, bson.M{"accountId": accountID},
+		options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})).Decode(&head)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// AcquireLease updates the lease when owner holds it or it has expired. Otherwise the upsert
+// collides with the held lease on its _id.
+func (r *chainRepository) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	_, err := r.leases.UpdateOne(ctx,
+		bson.M{"_id": name, "$or": bson.A{bson.M{"owner": owner}, bson.M{"expiresAt": bson.M{"$lte": now}}}},
+		bson.M{"$set": bson.M{"owner": owner, "expiresAt": now.Add(ttl)}},
+		options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ledger/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// LedgerRepository reads the balances implied by the ledger entries in MongoDB.
+type LedgerRepository interface {
+	AccountIDs(ctx context.Context) ([]string, error)
+	AccountsRecordedSince(ctx context.Context, since time.Time) ([]string, error)
+	Totals(ctx context.Context, accountIDs []string) (map[string]model.LedgerTotal, error)
+	Entries(ctx context.Context, accountID string) ([]model.LedgerEntry, error)
+	BalanceBefore(ctx context.Context, accountID string, before time.Time) (float64, error)
+	StreamEntries(ctx context.Context, accountID string, from, to time.Time, fn func(model.LedgerEntry) error) error
+}
+
+type ledgerRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLedgerRepository(collection *mongo.Collection) LedgerRepository {
+	return &ledgerRepository{collection: collection}
+}
+
+// succeeded selects the entries that moved money.
+func succeeded(filter bson.M) bson.M {
+	filter["status"] = "success"
+	return filter
+}
+
+// signedAmount credits add to and debits subtract from the balance.
+var signedAmount = bson.M{"$switch": bson.M{
+	"branches": bson.A{
+		bson.M{"case": bson.M{"$eq": bson.A{"$transactionType", "credit"}}, "then": "$amount"},
+		bson.M{"case": bson.M{"$eq": bson.A{"$transactionType", "debit"}}, "then": bson.M{"$multiply": bson.A{"$amount", -1}}},
+	},
+	"default": 0,
+}}
+
+func (r *ledgerRepository) AccountIDs(ctx context.Context) ([]string, error) {
+	return r.distinctAccounts(ctx, succeeded(bson.M{}))
+}
+
+// AccountsRecordedSince returns the accounts with ledger entries written after since.
+func (r *ledgerRepository) AccountsRecordedSince(ctx context.Context, since time.Time) ([]string, error) {
+	return r.distinctAccounts(ctx, bson.M{"recordedAt": bson.M{"$gt": since}})
+}
+
+func (r *ledgerRepository) distinctAccounts(ctx context.Context, filter bson.M) ([]string, error) {
+	var accountIDs []string
+	if err := r.collection.Distinct(ctx, "accountId", filter).Decode(&accountIDs); err != nil {
+		return nil, err
+	}
+	return accountIDs, nil
+}
+
+func (r *ledgerRepository) Totals(ctx context.Context, accountIDs []string) (map[string]model.LedgerTotal, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: succeeded(bson.M{"accountId": bson.M{"$in": accountIDs}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$accountId",
+			"balance":    bson.M{"$sum": signedAmount},
+			"entryCount": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		AccountID  string  `bson:"_id"`
+		Balance    float64 `bson:"balance"`
+		EntryCount int     `bson:"entryCount"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("sum ledger entries: %w", err)
+	}
+
+	totals := make(map[string]model.LedgerTotal, len(rows))
+	for _, row := range rows {
+		totals[row.AccountID] = model.LedgerTotal{Balance: row.Balance, EntryCount: row.EntryCount}
+	}
+	return totals, nil
+}
+
+// Entries returns the successful entries of an account, oldest first. Timestamps stored as
+// strings are converted so every entry decodes the same way.
+func (r *ledgerRepository) Entries(ctx context.Context, accountID string) ([]model.LedgerEntry, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: succeeded(bson.M{"accountId": accountID})}},
+		{{Key: "$project", Value: bson.M{
+			"id":              1,
+			"transactionType": 1,
+			"amount":          1,
+			"acceptedAt":      bson.M{"$toDate": "$acceptedAt"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "acceptedAt", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []model.LedgerEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// acceptedBetween matches entries accepted in [from, to). Timestamps stored as strings are
+// converted so they compare as dates.
+func acceptedBetween(from, to time.Time) bson.M {
+	acceptedAt := bson.M{"$toDate": "$acceptedAt"}
+	conditions := bson.A{bson.M{"$lt": bson.A{acceptedAt, to}}}
+	if !from.IsZero() {
+		conditions = append(conditions, bson.M{"$gte": bson.A{acceptedAt, from}})
+	}
+	return bson.M{"$and": conditions}
+}
+
+// BalanceBefore returns the balance implied by the entries accepted before the given time.
+func (r *ledgerRepository) BalanceBefore(ctx context.Context, accountID string, before time.Time) (float64, error) {
+	filter := succeeded(bson.M{"accountId": accountID})
+	filter["$expr"] = acceptedBetween(time.Time{}, before)
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "balance": bson.M{"$sum": signedAmount}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Balance float64 `bson:"balance"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	return rows[0].Balance, nil
+}
+
+// StreamEntries calls fn with the successful entries accepted in [from, to), oldest first,
+// while reading them from the cursor.
+func (r *ledgerRepository) StreamEntries(ctx context.Context, accountID string, from, to time.Time, fn func(model.LedgerEntry) error) error {
+	filter := succeeded(bson.M{"accountId": accountID})
+	filter["$expr"] = acceptedBetween(from, to)
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$set", Value: bson.M{"acceptedAt": bson.M{"$toDate": "$acceptedAt"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "acceptedAt", Value: 1}, {Key: "id", Value: 1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry model.LedgerEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ledger/model"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"gorm.io/gorm"
+)
+
+// reconciliationLockID is the Postgres advisory lock held during a run, so replicas do not
+// reconcile at the same time.
+const reconciliationLockID int64 = 4_725_319_002
+
+// ErrReconciliationLocked is returned by Lock while another run holds the lock.
+var ErrReconciliationLocked = errors.New("a reconciliation run is already in progress")
+
+type ReconciliationRepository interface {
+	Lock(ctx context.Context) (unlock func(), err error)
+	LastWatermark(ctx context.Context) (*time.Time, error)
+	LastBreakAccounts(ctx context.Context) ([]string, error)
+	AccountBalances(ctx context.Context, accountIDs []string) (map[string]float64, error)
+	AllAccountIDs(ctx context.Context) ([]string, error)
+	AccountsUpdatedSince(ctx context.Context, since time.Time) ([]string, error)
+	CreateRun(ctx context.Context, run *model.ReconciliationRun) error
+	FinishRun(ctx context.Context, run *model.ReconciliationRun, breaks []model.ReconciliationBreak) error
+	GetRun(ctx context.Context, id uuid.UUID) (*model.ReconciliationRun, error)
+	ListRuns(ctx context.Context, limit int) ([]model.ReconciliationRun, error)
+	ListBreaks(ctx context.Context, runID uuid.UUID) ([]model.ReconciliationBreak, error)
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// Lock takes the reconciliation lock on a dedicated connection without waiting.
+func (r *reconciliationRepository) Lock(ctx context.Context) (func(), error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, reconciliationLockID).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrReconciliationLocked
+	}
+	return func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, reconciliationLockID); err != nil {
+			logger.Log.Error().Err(err).Msg("Failed to release reconciliation lock")
+		}
+		conn.Close()
+	}, nil
+}
+
+// LastWatermark returns the watermark of the latest completed run, or nil if there is none.
+func (r *reconciliationRepository) LastWatermark(ctx context.Context) (*time.Time, error) {
+	run, err := r.lastCompletedRun(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run.WatermarkTo, nil
+}
+
+// LastBreakAccounts returns the accounts that were out of balance in the latest completed run.
+func (r *reconciliationRepository) LastBreakAccounts(ctx context.Context) ([]string, error) {
+	run, err := r.lastCompletedRun(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var accountIDs []string
+	err = r.db.WithContext(ctx).Model(&model.ReconciliationBreak{}).
+		Where("run_id = ?", run.ID).
+		Pluck("account_id", &accountIDs).Error
+	return accountIDs, err
+}
+
+func (r *reconciliationRepository) lastCompletedRun(ctx context.Context) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	err := r.db.WithContext(ctx).
+		Where("status = ?", model.ReconciliationCompleted).
+		Order("started_at DESC").
+		First(&run).Error
+	return &run, err
+}
+
+func (r *reconciliationRepository) AccountBalances(ctx context.Context, accountIDs []string) (map[string]float64, error) {
+	var rows []struct {
+		ID      string
+		Balance float64
+	}
+	err := r.db.WithContext(ctx).Table("accounts").
+		Select("id::text AS id, balance").
+		Where("id::text IN ?", accountIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		balances[row.ID] = row.Balance
+	}
+	return balances, nil
+}
+
+func (r *reconciliationRepository) AllAccountIDs(ctx context.Context) ([]string, error) {
+	var accountIDs []string
+	err := r.db.WithContext(ctx).Table("accounts").Pluck("id::text", &accountIDs).Error
+	return accountIDs, err
+}
+
+func (r *reconciliationRepository) AccountsUpdatedSince(ctx context.Context, since time.Time) ([]string, error) {
+	var accountIDs []string
+	err := r.db.WithContext(ctx).Table("accounts").
+		Where("updated_at > ?", since).
+		Pluck("id::text", &accountIDs).Error
+	return accountIDs, err
+}
+
+func (r *reconciliationRepository) CreateRun(ctx context.Context, run *model.ReconciliationRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// FinishRun records the outcome of a run together with the breaks it found.
+func (r *reconciliationRepository) FinishRun(ctx context.Context, run *model.ReconciliationRun, breaks []model.ReconciliationBreak) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(breaks) > 0 {
+			if err := tx.CreateInBatches(breaks, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(run).Updates(map[string]interface{}{
+			"status":           run.Status,
+			"accounts_checked": run.AccountsChecked,
+			"breaks_found":     run.BreaksFound,
+			"error":            run.Error,
+			"finished_at":      run.FinishedAt,
+		}).Error
+	})
+}
+
+func (r *reconciliationRepository) GetRun(ctx context.Context, id uuid.UUID) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	err := r.db.WithContext(ctx).First(&run, "id = ?", id).Error
+	return &run, err
+}
+
+func (r *reconciliationRepository) ListRuns(ctx context.Context, limit int) ([]model.ReconciliationRun, error) {
+	var runs []model.ReconciliationRun
+	err := r.db.WithContext(ctx).Order("started_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (r *reconciliationRepository) ListBreaks(ctx context.Context, runID uuid.UUID) ([]model.ReconciliationBreak, error) {
+	var breaks []model.ReconciliationBreak
+	err := r.db.WithContext(ctx).Where("run_id = ?", runID).Order("account_id").Find(&breaks).Error
+	return breaks, err
+}
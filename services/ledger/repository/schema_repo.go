@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/shrishyam02/banking-ledger/common/db"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SchemaRepository applies the validators and indexes of the ledger collections.
+type SchemaRepository interface {
+	EnsureValidator(ctx context.Context, collection string, schema bson.M) error
+	EnsureIndexes(ctx context.Context, collection string, indexes []db.Index) error
+	HasIndex(ctx context.Context, collection string, name string) (bool, error)
+	// MoveDuplicates keeps one document of each group sharing the values of keys in collection
+	// and moves the others to target. Chained entries are kept over unchained ones, then the
+	// oldest. It returns the number of documents moved.
+	MoveDuplicates(ctx context.Context, collection string, keys []string, target string) (int, error)
+}
+
+type schemaRepository struct {
+	database *mongo.Database
+}
+
+func NewSchemaRepository(database *mongo.Database) SchemaRepository {
+	return &schemaRepository{database: database}
+}
+
+func (r *schemaRepository) EnsureValidator(ctx context.Context, collection string, schema bson.M) error {
+	return db.EnsureValidator(ctx, r.database, collection, schema)
+}
+
+func (r *schemaRepository) EnsureIndexes(ctx context.Context, collection string, indexes []db.Index) error {
+	return db.EnsureIndexes(ctx, r.database.Collection(collection), indexes)
+}
+
+func (r *schemaRepository) HasIndex(ctx context.Context, collection string, name string) (bool, error) {
+	return db.HasIndex(ctx, r.database.Collection(collection), name)
+}
+
+func (r *schemaRepository) MoveDuplicates(ctx context.Context, collection string, keys []string, target string) (int, error) {
+	group := bson.M{}
+	for _, key := range keys {
+		group[key] = "$" + key
+	}
+	source := r.database.Collection(collection)
+	cursor, err := source.Aggregate(ctx, mongo.Pipeline{
+		// Missing chain sequences sort last in descending order
+		{{Key: "$sort", Value: bson.D{{Key: "chainSeq", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   group,
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	moved := 0
+	for cursor.Next(ctx) {
+		var duplicates struct {
+			IDs []any `bson:"ids"`
+		}
+		if err := cursor.Decode(&duplicates); err != nil {
+			return moved, err
+		}
+		extra := bson.M{"_id": bson.M{"$in": duplicates.IDs[1:]}}
+
+		documents, err := source.Find(ctx, extra)
+		if err != nil {
+			return moved, err
+		}
+		var copies []bson.M
+		if err := documents.All(ctx, &copies); err != nil {
+			return moved, err
+		}
+		if len(copies) == 0 {
+			continue
+		}
+		// Copies left over from an interrupted run are already in target
+		_, err = r.database.Collection(target).InsertMany(ctx, copies, options.InsertMany().SetOrdered(false))
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return moved, err
+		}
+		result, err := source.DeleteMany(ctx, extra)
+		if err != nil {
+			return moved, err
+		}
+		moved += int(result.DeletedCount)
+	}
+	return moved, cursor.Err()
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"ledger/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// StatementRepository keeps generated statements for later download.
+type StatementRepository interface {
+	Exists(ctx context.Context, accountID, period, format string) (bool, error)
+	Save(ctx context.Context, statement *model.StoredStatement) error
+	Get(ctx context.Context, accountID, period, format string) (*model.StoredStatement, error)
+	List(ctx context.Context, accountID string) ([]model.StoredStatement, error)
+}
+
+type statementRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStatementRepository(collection *mongo.Collection) StatementRepository {
+	return &statementRepository{collection: collection}
+}
+
+func statementKey(accountID, period, format string) bson.M {
+	return bson.M{"accountId": accountID, "period": period, "format": format}
+}
+
+func (r *statementRepository) Exists(ctx context.Context, accountID, period, format string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, statementKey(accountID, period, format), options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// Save replaces the statement of the same account, period and format.
+func (r *statementRepository) Save(ctx context.Context, statement *model.StoredStatement) error {
+	_, err := r.collection.ReplaceOne(ctx,
+		statementKey(statement.AccountID, statement.Period, statement.Format),
+		statement,
+		options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *statementRepository) Get(ctx context.Context, accountID, period, format string) (*model.StoredStatement, error) {
+	var statement model.StoredStatement
+	if err := r.collection.FindOne(ctx, statementKey(accountID, period, format)).Decode(&statement); err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// List returns the stored statements of an account without their content, newest first.
+func (r *statementRepository) List(ctx context.Context, accountID string) ([]model.StoredStatement, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"accountId": accountID}, options.Find().
+		SetProjection(bson.M{"content": 0}).
+		SetSort(bson.D{{Key: "period", Value: -1}, {Key: "format", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	statements := []model.StoredStatement{}
+	if err := cursor.All(ctx, &statements); err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
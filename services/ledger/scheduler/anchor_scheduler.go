@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"ledger/service"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+)
+
+type AnchorScheduler interface {
+	Run(ctx context.Context)
+}
+
+type anchorScheduler struct {
+	chainService service.ChainService
+	interval     time.Duration
+}
+
+// NewAnchorScheduler anchors the heads of the account chains every interval. Of several
+// replicas, only the one holding the anchor lease anchors.
+func NewAnchorScheduler(chainService service.ChainService, interval time.Duration) AnchorScheduler {
+	return &anchorScheduler{
+		chainService: chainService,
+		interval:     interval,
+	}
+}
+
+func (s *anchorScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		runCtx := requestid.NewContext(ctx, requestid.New())
+		// The lease outlives a missed tick, so another replica takes over only when the holder
+		// has stopped anchoring
+		held, err := s.chainService.AcquireAnchorLease(runCtx, 2*s.interval)
+		if err != nil {
+			logger.Ctx(runCtx).Error().Err(err).Msg("Failed to acquire the anchor lease")
+			continue
+		}
+		if !held {
+			continue
+		}
+		if _, _, err := s.chainService.Anchor(runCtx); err != nil {
+			logger.Ctx(runCtx).Error().Err(err).Msg("Anchoring ledger chains failed")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ledger/model"
+	"ledger/repository"
+	"ledger/service"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+)
+
+type ReconciliationScheduler interface {
+	Run(ctx context.Context)
+}
+
+type reconciliationScheduler struct {
+	reconciliationService service.ReconciliationService
+	interval              time.Duration
+}
+
+// NewReconciliationScheduler runs an incremental reconciliation every interval.
+func NewReconciliationScheduler(reconciliationService service.ReconciliationService, interval time.Duration) ReconciliationScheduler {
+	return &reconciliationScheduler{
+		reconciliationService: reconciliationService,
+		interval:              interval,
+	}
+}
+
+func (s *reconciliationScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		runCtx := requestid.NewContext(ctx, requestid.New())
+		_, err := s.reconciliationService.Run(runCtx, model.ReconciliationIncremental)
+		if errors.Is(err, repository.ErrReconciliationLocked) {
+			// Another replica is reconciling
+			continue
+		}
+		if err != nil {
+			logger.Ctx(runCtx).Error().Err(err).Msg("Reconciliation failed")
+		}
+	}
+}
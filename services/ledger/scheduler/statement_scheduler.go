@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"ledger/service"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+)
+
+type StatementScheduler interface {
+	Run(ctx context.Context)
+}
+
+type statementScheduler struct {
+	statementService service.StatementService
+	now              func() time.Time
+}
+
+// NewStatementScheduler stores the statements of the previous month at the start of every
+// month, and on start in case the service was down at month-end.
+func NewStatementScheduler(statementService service.StatementService) StatementScheduler {
+	return &statementScheduler{
+		statementService: statementService,
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *statementScheduler) Run(ctx context.Context) {
+	for {
+		now := s.now()
+		s.generate(ctx, now.AddDate(0, 0, -now.Day()))
+
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		timer := time.NewTimer(monthStart.AddDate(0, 1, 0).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *statementScheduler) generate(ctx context.Context, month time.Time) {
+	runCtx := requestid.NewContext(ctx, requestid.New())
+	if err := s.statementService.GenerateMonth(runCtx, month); err != nil {
+		logger.Ctx(runCtx).Error().Err(err).Msgf("Statement generation for %s failed", month.Format("2006-01"))
+	}
+}
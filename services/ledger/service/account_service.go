@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+)
+
+type AccountService interface {
+	GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error)
+}
+
+// NewAccountService calls the account service with httpClient, which authenticates the requests.
+func NewAccountService(accountServiceURL string, httpClient *http.Client) AccountService {
+	return client.NewAccounts(accountServiceURL, httpClient)
+}
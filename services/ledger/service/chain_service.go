@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ledger/model"
+	"ledger/repository"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+// errChainBroken stops the walk over a chain at its first broken link.
+var errChainBroken = errors.New("chain broken")
+
+type ChainService interface {
+	Verify(ctx context.Context, accountID string) (*model.ChainVerification, error)
+	AccountIDs(ctx context.Context) ([]string, error)
+	// Anchor stores the Merkle root over the current chain heads. It reports false when nothing
+	// was recorded since the last anchor.
+	Anchor(ctx context.Context) (*model.ChainAnchor, bool, error)
+	// AcquireAnchorLease reports whether this instance holds the anchoring lease, taking or
+	// renewing it for ttl. Only the holder anchors, so replicas do not store anchors of the same
+	// heads side by side.
+	AcquireAnchorLease(ctx context.Context, ttl time.Duration) (bool, error)
+}
+
+// anchorLease names the lease held by the instance that anchors the chains.
+const anchorLease = "ledger-anchor"
+
+type chainService struct {
+	repo     repository.ChainRepository
+	recorder audit.Recorder
+	now      func() time.Time
+	// instance identifies this process as the owner of leases
+	instance string
+}
+
+// NewChainService verifies and anchors the account chains. Anchor roots are also written to
+// recorder, when set, so they are kept outside the database holding the chains.
+func NewChainService(repo repository.ChainRepository, recorder audit.Recorder) ChainService {
+	return &chainService{
+		repo:     repo,
+		recorder: recorder,
+		now:      func() time.Time { return time.Now().UTC() },
+		instance: uuid.NewString(),
+	}
+}
+
+// Verify walks the chain of an account from its first entry and reports the first entry that is
+// missing, no longer links to its predecessor, or no longer matches its hash. The chain must
+// also still contain the head covered by the last anchor; this catches the newest entries being
+// removed, or the whole chain being rehashed.
+func (s *chainService) Verify(ctx context.Context, accountID string) (*model.ChainVerification, error) {
+	anchored, err := s.repo.LatestAnchoredHead(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ChainVerification{AccountID: accountID, Valid: true}
+	prevHash := genesisHash(accountID)
+	var anchoredHash string
+	err = s.repo.StreamChain(ctx, accountID, func(entry map[string]interface{}) error {
+		seq := toInt64(entry[chainSeqField])
+		if reason := checkLink(entry, seq, result.HeadSeq+1, prevHash); reason != "" {
+			markBroken(result, result.HeadSeq+1, entry, reason)
+			return errChainBroken
+		}
+		hash, _ := entry[hashField].(string)
+		if anchored != nil && seq == anchored.Seq {
+			anchoredHash = hash
+		}
+		result.Entries++
+		result.HeadSeq = seq
+		result.HeadHash = hash
+		prevHash = hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+
+	if anchored != nil {
+		result.AnchorID = anchored.AnchorID
+		result.AnchoredSeq = anchored.Seq
+		if result.Valid && anchored.Seq > result.HeadSeq {
+			markBroken(result, result.HeadSeq+1, nil, "entries covered by the last anchor are missing")
+		} else if result.Valid && anchoredHash != anchored.Hash {
+			markBroken(result, anchored.Seq, nil, "entry does not match the last anchor")
+		}
+	}
+
+	if result.Unchained, err = s.repo.CountUnchained(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkLink returns why the entry does not follow the entry hashed prevHash at seq-1.
+func checkLink(entry map[string]interface{}, seq, expectedSeq int64, prevHash string) string {
+	switch {
+	case seq > expectedSeq:
+		return "entry missing from the chain"
+	case seq < expectedSeq:
+		return "sequence number used twice"
+	case entry[prevHashField] != prevHash:
+		return "previous hash does not match the preceding entry"
+	case entry[hashField] != entryHash(entry):
+		return "hash does not match the entry's content"
+	}
+	return ""
+}
+
+// markBroken records where the chain breaks and, when it is known, the entry found there.
+func markBroken(result *model.ChainVerification, seq int64, entry map[string]interface{}, reason string) {
+	result.Valid = false
+	result.BrokenAt = seq
+	result.Reason = reason
+	if entry != nil {
+		result.TransactionID, _ = entry["id"].(string)
+		result.Status, _ = entry["status"].(string)
+	}
+}
+
+func (s *chainService) AccountIDs(ctx context.Context) ([]string, error) {
+	return s.repo.ChainedAccountIDs(ctx)
+}
+
+func (s *chainService) Anchor(ctx context.Context) (*model.ChainAnchor, bool, error) {
+	heads, err := s.repo.Heads(ctx)
+	if err != nil || len(heads) == 0 {
+		return nil, false, err
+	}
+	root := merkleRoot(heads)
+
+	latest, err := s.repo.LatestAnchor(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if latest != nil && latest.Root == root {
+		return latest, false, nil
+	}
+
+	anchor := &model.ChainAnchor{
+		ID:        uuid.NewString(),
+		Root:      root,
+		Accounts:  len(heads),
+		CreatedAt: s.now().Truncate(time.Millisecond),
+	}
+	for _, head := range heads {
+		anchor.Entries += head.Seq
+	}
+	if err := s.repo.SaveAnchor(ctx, anchor, heads); err != nil {
+		return nil, false, err
+	}
+	logger.Ctx(ctx).Info().Str("anchor", anchor.ID).Str("root", root).Int("accounts", anchor.Accounts).Msg("Anchored ledger chains")
+
+	if s.recorder != nil {
+		entry := &audit.Entry{
+			Actor:      "system",
+			Action:     "ledger anchor",
+			EntityType: "ledger-anchor",
+			EntityID:   anchor.ID,
+			Changes:    audit.Diff(nil, map[string]any{"root": anchor.Root, "accounts": anchor.Accounts, "entries": anchor.Entries}),
+		}
+		// The anchor is stored; a missing copy only weakens what it proves
+		if err := s.recorder.Record(ctx, entry); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("anchor", anchor.ID).Msg("Failed to record ledger anchor in the audit log")
+		}
+	}
+	return anchor, true, nil
+}
+
+func (s *chainService) AcquireAnchorLease(ctx context.Context, ttl time.Duration) (bool, error) {
+	return s.repo.AcquireLease(ctx, anchorLease, s.instance, ttl)
+}
+
+func toInt64(v interface{}) int64 {
+	switch value := v.(type) {
+	case int64:
+		return value
+	case int32:
+		return int64(value)
+	case int:
+		return int64(value)
+	case float64:
+		return int64(value)
+	}
+	return 0
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"ledger/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockChainRepository struct {
+	mock.Mock
+}
+
+func (m *MockChainRepository) StreamChain(ctx context.Context, accountID string, fn func(map[string]interface{}) error) error {
+	args := m.Called(ctx, accountID)
+	for _, entry := range args.Get(0).([]map[string]interface{}) {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockChainRepository) CountUnchained(ctx context.Context, accountID string) (int64, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockChainRepository) ChainedAccountIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockChainRepository) Heads(ctx context.Context) ([]model.ChainHead, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.ChainHead), args.Error(1)
+}
+
+func (m *MockChainRepository) LatestAnchor(ctx context.Context) (*model.ChainAnchor, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ChainAnchor), args.Error(1)
+}
+
+func (m *MockChainRepository) SaveAnchor(ctx context.Context, anchor *model.ChainAnchor, heads []model.ChainHead) error {
+	args := m.Called(ctx, anchor, heads)
+	return args.Error(0)
+}
+
+func (m *MockChainRepository) LatestAnchoredHead(ctx context.Context, accountID string) (*model.AnchoredHead, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AnchoredHead), args.Error(1)
+}
+
+func (m *MockChainRepository) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, name, owner, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+// chainEntries chains n entries of the account the way HandleMessage records them and reads them
+// back through BSON, as the repository returns them.
+func chainEntries(t *testing.T, accountID string, n int) []map[string]interface{} {
+	t.Helper()
+	entries := make([]map[string]interface{}, 0, n)
+	prevHash := genesisHash(accountID)
+	for i := 1; i <= n; i++ {
+		var entry map[string]interface{}
+		message := `{"id": "txn-` + strconv.Itoa(i) + `", "accountId": "` + accountID +
+			`", "status": "success", "amount": 12.5, "metadata": {"channel": "api", "tags": ["a", 1]}}`
+		assert.NoError(t, json.Unmarshal([]byte(message), &entry))
+		entry["recordedAt"] = time.Date(2026, 10, 1, 9, 30, i, 123456789, time.UTC).Truncate(time.Millisecond)
+		entry[chainSeqField] = int64(i)
+		entry[prevHashField] = prevHash
+		entry[hashField] = entryHash(entry)
+		prevHash = entry[hashField].(string)
+
+		data, err := bson.Marshal(entry)
+		assert.NoError(t, err)
+		var stored map[string]interface{}
+		assert.NoError(t, bson.Unmarshal(data, &stored))
+		stored["_id"] = bson.NewObjectID()
+		entries = append(entries, stored)
+	}
+	return entries
+}
+
+func TestVerifyChain(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		tamper   func(entries []map[string]interface{}) []map[string]interface{}
+		anchored *model.AnchoredHead
+		valid    bool
+		brokenAt int64
+		reason   string
+	}{
+		{
+			name:   "intact",
+			tamper: func(entries []map[string]interface{}) []map[string]interface{} { return entries },
+			valid:  true,
+		},
+		{
+			name: "amount changed",
+			tamper: func(entries []map[string]interface{}) []map[string]interface{} {
+				entries[1]["amount"] = 1250.0
+				return entries
+			},
+			brokenAt: 2,
+			reason:   "hash does not match the entry's content",
+		},
+		{
+			name: "entry removed",
+			tamper: func(entries []map[string]interface{}) []map[string]interface{} {
+				return append(entries[:1], entries[2:]...)
+			},
+			brokenAt: 2,
+			reason:   "entry missing from the chain",
+		},
+		{
+			name: "entry rehashed",
+			tamper: func(entries []map[string]interface{}) []map[string]interface{} {
+				entries[0]["amount"] = 1250.0
+				entries[0][hashField] = entryHash(entries[0])
+				return entries
+			},
+			brokenAt: 2,
+			reason:   "previous hash does not match the preceding entry",
+		},
+		{
+			name: "newest entry removed since anchor",
+			tamper: func(entries []map[string]interface{}) []map[string]interface{} {
+				return entries[:2]
+			},
+			anchored: &model.AnchoredHead{AnchorID: "anchor-1", Seq: 3},
+			brokenAt: 3,
+			reason:   "entries covered by the last anchor are missing",
+		},
+		{
+			name:     "anchored entry replaced",
+			tamper:   func(entries []map[string]interface{}) []map[string]interface{} { return entries },
+			anchored: &model.AnchoredHead{AnchorID: "anchor-1", Seq: 2, Hash: "rewritten"},
+			brokenAt: 2,
+			reason:   "entry does not match the last anchor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockChainRepository)
+			service := NewChainService(mockRepo, nil)
+
+			mockRepo.On("LatestAnchoredHead", ctx, "acc-1").Return(tt.anchored, nil)
+			mockRepo.On("StreamChain", ctx, "acc-1").Return(tt.tamper(chainEntries(t, "acc-1", 3)), nil)
+			mockRepo.On("CountUnchained", ctx, "acc-1").Return(int64(4), nil)
+
+			result, err := service.Verify(ctx, "acc-1")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.valid, result.Valid)
+			assert.Equal(t, tt.brokenAt, result.BrokenAt)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, int64(4), result.Unchained)
+		})
+	}
+}
+
+func TestVerifyChain_ReportsEntry(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockChainRepository)
+	service := NewChainService(mockRepo, nil)
+
+	entries := chainEntries(t, "acc-1", 3)
+	entries[2]["status"] = "failed"
+	mockRepo.On("LatestAnchoredHead", ctx, "acc-1").Return(nil, nil)
+	mockRepo.On("StreamChain", ctx, "acc-1").Return(entries, nil)
+	mockRepo.On("CountUnchained", ctx, "acc-1").Return(int64(0), nil)
+
+	result, err := service.Verify(ctx, "acc-1")
+
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), result.BrokenAt)
+	assert.Equal(t, "txn-3", result.TransactionID)
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, int64(2), result.Entries)
+	assert.Equal(t, entries[1][hashField], result.HeadHash)
+}
+
+func TestMerkleRoot(t *testing.T) {
+	heads := []model.ChainHead{
+		{AccountID: "acc-2", Seq: 4, Hash: "b"},
+		{AccountID: "acc-1", Seq: 7, Hash: "a"},
+		{AccountID: "acc-3", Seq: 1, Hash: "c"},
+	}
+	root := merkleRoot(heads)
+
+	assert.Len(t, root, 64)
+	assert.Equal(t, root, merkleRoot([]model.ChainHead{heads[1], heads[2], heads[0]}), "order of the heads")
+	heads[2].Seq = 2
+	assert.NotEqual(t, root, merkleRoot(heads))
+	assert.Empty(t, merkleRoot(nil))
+}
+
+func TestAnchor(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockChainRepository)
+	service := NewChainService(mockRepo, nil)
+
+	heads := []model.ChainHead{{AccountID: "acc-1", Seq: 3, Hash: "a"}, {AccountID: "acc-2", Seq: 2, Hash: "b"}}
+	mockRepo.On("Heads", ctx).Return(heads, nil)
+	mockRepo.On("LatestAnchor", ctx).Return(&model.ChainAnchor{ID: "anchor-1", Root: "stale"}, nil).Once()
+	mockRepo.On("SaveAnchor", ctx, mock.Anything, heads).Return(nil).Once()
+
+	anchor, anchored, err := service.Anchor(ctx)
+
+	assert.NoError(t, err)
+	assert.True(t, anchored)
+	assert.Equal(t, merkleRoot(heads), anchor.Root)
+	assert.Equal(t, 2, anchor.Accounts)
+	assert.Equal(t, int64(5), anchor.Entries)
+
+	// Nothing recorded since
+	mockRepo.On("LatestAnchor", ctx).Return(anchor, nil).Once()
+	latest, anchored, err := service.Anchor(ctx)
+
+	assert.NoError(t, err)
+	assert.False(t, anchored)
+	assert.Equal(t, anchor.ID, latest.ID)
+	mockRepo.AssertNumberOfCalls(t, "SaveAnchor", 1)
+}
+
+func TestAcquireAnchorLease(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockChainRepository)
+	service := NewChainService(mockRepo, nil)
+	other := NewChainService(mockRepo, nil)
+
+	var owners []string
+	mockRepo.On("AcquireLease", ctx, anchorLease, mock.Anything, time.Minute).
+		Run(func(args mock.Arguments) { owners = append(owners, args.String(2)) }).
+		Return(true, nil).Twice()
+	mockRepo.On("AcquireLease", ctx, anchorLease, mock.Anything, time.Minute).
+		Run(func(args mock.Arguments) { owners = append(owners, args.String(2)) }).
+		Return(false, nil).Once()
+
+	held, err := service.AcquireAnchorLease(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, held)
+	// Renewing the lease names the same owner
+	held, err = service.AcquireAnchorLease(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, owners[0], owners[1])
+
+	held, err = other.AcquireAnchorLease(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, held)
+	// Another replica competes for the lease under its own name
+	assert.NotEqual(t, owners[0], owners[2])
+}
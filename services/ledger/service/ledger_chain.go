@@ -0,0 +1,119 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"ledger/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Fields chaining a ledger entry to the entry recorded before it for the same account.
+const (
+	chainSeqField = "chainSeq"
+	prevHashField = "prevHash"
+	hashField     = "hash"
+)
+
+// An entry that lost the race for its account's head is chained again after a backoff that
+// doubles from chainRetryDelay up to chainRetryMaxDelay, at most maxChainAppend times in all.
+// Together the attempts wait over 15 seconds, far longer than any burst on one account; a head
+// that keeps colliding is broken rather than busy.
+const (
+	chainRetryDelay    = 5 * time.Millisecond
+	chainRetryMaxDelay = 500 * time.Millisecond
+	maxChainAppend     = 50
+)
+
+// genesisHash is the previous hash of an account's first entry. It differs per account, so every
+// previous hash in the collection is unique and the unique index on it rejects forks.
+func genesisHash(accountID string) string {
+	sum := sha256.Sum256([]byte("ledger:" + accountID))
+	return hex.EncodeToString(sum[:])
+}
+
+// entryHash hashes every field of an entry, its chain fields included, except the hash itself and
+// the _id MongoDB assigns.
+func entryHash(entry map[string]interface{}) string {
+	fields := make(map[string]interface{}, len(entry))
+	for k, v := range entry {
+		if k != "_id" && k != hashField {
+			fields[k] = canonical(v)
+		}
+	}
+	content, _ := json.Marshal(fields)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonical brings a value decoded from the Kafka message and the same value read back from
+// MongoDB to one JSON form. Dates are stored with millisecond precision.
+func canonical(v interface{}) interface{} {
+	switch value := v.(type) {
+	case time.Time:
+		return value.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+	case bson.DateTime:
+		return value.Time().UTC().Format(time.RFC3339Nano)
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	case bson.D:
+		object := make(map[string]interface{}, len(value))
+		for _, element := range value {
+			object[element.Key] = canonical(element.Value)
+		}
+		return object
+	case bson.M:
+		return canonical(map[string]interface{}(value))
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for k, element := range value {
+			object[k] = canonical(element)
+		}
+		return object
+	case bson.A:
+		return canonical([]interface{}(value))
+	case []interface{}:
+		values := make([]interface{}, len(value))
+		for i, element := range value {
+			values[i] = canonical(element)
+		}
+		return values
+	}
+	return v
+}
+
+// merkleRoot combines the heads of the account chains, ordered by account, into one hash. Leaves
+// and inner nodes are hashed with distinct prefixes; an unpaired node moves up unchanged.
+func merkleRoot(heads []model.ChainHead) string {
+	if len(heads) == 0 {
+		return ""
+	}
+	sorted := append([]model.ChainHead(nil), heads...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].AccountID < sorted[j].AccountID })
+
+	level := make([][]byte, len(sorted))
+	for i, head := range sorted {
+		sum := sha256.Sum256([]byte("\x00" + head.AccountID + ":" + strconv.FormatInt(head.Seq, 10) + ":" + head.Hash))
+		level[i] = sum[:]
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			sum := sha256.Sum256(append(append([]byte{1}, level[i]...), level[i+1]...))
+			next = append(next, sum[:])
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0])
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"ledger/repository"
+
+	"github.com/shrishyam02/banking-ledger/common/db"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	transactionsCollection      = "transactions"
+	transactionStatesCollection = "transaction_states"
+	statementsCollection        = "statements"
+	anchorsCollection           = "ledger_anchors"
+	anchorHeadsCollection       = "ledger_anchor_heads"
+)
+
+// transactionIndexes serve the history lookups. A transaction is recorded once per status it
+// passes through, so id + status identifies a ledger entry.
+var transactionIndexes = []db.Index{
+	{
+		Name:   "id_status",
+		Keys:   bson.D{{Key: "id", Value: 1}, {Key: "status", Value: 1}},
+		Unique: true,
+	},
+	{
+		Name: "accountId_acceptedAt",
+		Keys: bson.D{{Key: "accountId", Value: 1}, {Key: "acceptedAt", Value: -1}},
+	},
+	{
+		Name: "recordedAt",
+		Keys: bson.D{{Key: "recordedAt", Value: 1}},
+	},
+	{
+		Name: "accountId_chainSeq",
+		Keys: bson.D{{Key: "accountId", Value: 1}, {Key: "chainSeq", Value: 1}},
+	},
+	// Every entry has one successor, so two entries chained to the same head collide here.
+	// Entries recorded before the ledger was chained have no previous hash.
+	{
+		Name:   "prevHash",
+		Keys:   bson.D{{Key: "prevHash", Value: 1}},
+		Unique: true,
+		Sparse: true,
+	},
+}
+
+// transactionStateIndexes make id the key of the consolidated view, which HandleMessage relies on
+// to reject stale stages.
+var transactionStateIndexes = []db.Index{
+	{
+		Name:   "id",
+		Keys:   bson.D{{Key: "id", Value: 1}},
+		Unique: true,
+	},
+}
+
+// anchorHeadIndexes find the latest anchored head of an account.
+var anchorHeadIndexes = []db.Index{
+	{
+		Name: "accountId_createdAt",
+		Keys: bson.D{{Key: "accountId", Value: 1}, {Key: "createdAt", Value: -1}},
+	},
+}
+
+// statementIndexes keep one stored statement per account, period and format.
+var statementIndexes = []db.Index{
+	{
+		Name:   "accountId_period_format",
+		Keys:   bson.D{{Key: "accountId", Value: 1}, {Key: "period", Value: 1}, {Key: "format", Value: 1}},
+		Unique: true,
+	},
+}
+
+// transactionSchema is the $jsonSchema every ledger entry must satisfy. Failed transactions are
+// recorded too, so only the keys are required and the other fields are checked when present.
+// Timestamps are accepted as strings since entries are stored as decoded from the Kafka message.
+var transactionSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"id", "accountId", "status"},
+	"properties": bson.M{
+		"id":              bson.M{"bsonType": "string"},
+		"accountId":       bson.M{"bsonType": "string"},
+		"amount":          bson.M{"bsonType": "number"},
+		"transactionType": bson.M{"bsonType": "string"},
+		"status":          bson.M{"enum": bson.A{"success", "failed", "pending_review"}},
+		"acceptedAt":      bson.M{"bsonType": bson.A{"date", "string"}},
+		"processedAt":     bson.M{"bsonType": bson.A{"date", "string"}},
+		"recordedAt":      bson.M{"bsonType": "date"},
+		"chainSeq":        bson.M{"bsonType": bson.A{"long", "int"}},
+		"prevHash":        bson.M{"bsonType": "string"},
+		"hash":            bson.M{"bsonType": "string"},
+	},
+}
+
+// duplicatesCollection keeps the ledger entries set aside so that id + status can be unique.
+const duplicatesCollection = "transactions_duplicates"
+
+// EnsureSchema applies the validator and indexes of the ledger collections. It is safe to run
+// on every start.
+func EnsureSchema(ctx context.Context, repo repository.SchemaRepository) error {
+	if err := repo.EnsureValidator(ctx, transactionsCollection, transactionSchema); err != nil {
+		return err
+	}
+	if err := dedupeEntries(ctx, repo); err != nil {
+		return err
+	}
+	if err := repo.EnsureIndexes(ctx, transactionsCollection, transactionIndexes); err != nil {
+		return err
+	}
+	if err := repo.EnsureIndexes(ctx, transactionStatesCollection, transactionStateIndexes); err != nil {
+		return err
+	}
+	if err := repo.EnsureIndexes(ctx, anchorHeadsCollection, anchorHeadIndexes); err != nil {
+		return err
+	}
+	return repo.EnsureIndexes(ctx, statementsCollection, statementIndexes)
+}
+
+// dedupeEntries sets aside the entries recorded twice for the same status before the id_status
+// index made that impossible, so the index can be built. Once it exists there is nothing to do.
+func dedupeEntries(ctx context.Context, repo repository.SchemaRepository) error {
+	indexed, err := repo.HasIndex(ctx, transactionsCollection, "id_status")
+	if err != nil || indexed {
+		return err
+	}
+	moved, err := repo.MoveDuplicates(ctx, transactionsCollection, []string{"id", "status"}, duplicatesCollection)
+	if err != nil {
+		return fmt.Errorf("set aside duplicate ledger entries: %w", err)
+	}
+	if moved > 0 {
+		logger.Ctx(ctx).Warn().Int("entries", moved).Str("collection", duplicatesCollection).Msg("Moved duplicate ledger entries")
+	}
+	return nil
+}
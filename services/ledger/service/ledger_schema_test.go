@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shrishyam02/banking-ledger/common/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type MockSchemaRepository struct {
+	mock.Mock
+	calls []string
+}
+
+func (m *MockSchemaRepository) EnsureValidator(ctx context.Context, collection string, schema bson.M) error {
+	m.calls = append(m.calls, "validator "+collection)
+	args := m.Called(ctx, collection, schema)
+	return args.Error(0)
+}
+
+func (m *MockSchemaRepository) EnsureIndexes(ctx context.Context, collection string, indexes []db.Index) error {
+	m.calls = append(m.calls, "indexes "+collection)
+	args := m.Called(ctx, collection, indexes)
+	return args.Error(0)
+}
+
+func (m *MockSchemaRepository) HasIndex(ctx context.Context, collection string, name string) (bool, error) {
+	args := m.Called(ctx, collection, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSchemaRepository) MoveDuplicates(ctx context.Context, collection string, keys []string, target string) (int, error) {
+	m.calls = append(m.calls, "dedupe "+collection)
+	args := m.Called(ctx, collection, keys, target)
+	return args.Int(0), args.Error(1)
+}
+
+func TestEnsureSchema_DedupesBeforeUniqueIndex(t *testing.T) {
+	repo := new(MockSchemaRepository)
+	ctx := context.Background()
+
+	repo.On("EnsureValidator", ctx, transactionsCollection, transactionSchema).Return(nil)
+	repo.On("HasIndex", ctx, transactionsCollection, "id_status").Return(false, nil)
+	repo.On("MoveDuplicates", ctx, transactionsCollection, []string{"id", "status"}, duplicatesCollection).Return(2, nil)
+	repo.On("EnsureIndexes", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	err := EnsureSchema(ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"validator " + transactionsCollection,
+		"dedupe " + transactionsCollection,
+		"indexes " + transactionsCollection,
+		"indexes " + transactionStatesCollection,
+		"indexes " + anchorHeadsCollection,
+		"indexes " + statementsCollection,
+	}, repo.calls)
+	repo.AssertCalled(t, "EnsureIndexes", ctx, transactionsCollection, transactionIndexes)
+}
+
+func TestEnsureSchema_SkipsDedupeOnceIndexed(t *testing.T) {
+	repo := new(MockSchemaRepository)
+	ctx := context.Background()
+
+	repo.On("EnsureValidator", ctx, transactionsCollection, transactionSchema).Return(nil)
+	repo.On("HasIndex", ctx, transactionsCollection, "id_status").Return(true, nil)
+	repo.On("EnsureIndexes", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	err := EnsureSchema(ctx, repo)
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "MoveDuplicates", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEnsureSchema_ValidatorError(t *testing.T) {
+	repo := new(MockSchemaRepository)
+	ctx := context.Background()
+
+	repo.On("EnsureValidator", ctx, transactionsCollection, transactionSchema).Return(errors.New("not authorized on banking_ledger_db to execute command { collMod: ... }"))
+
+	err := EnsureSchema(ctx, repo)
+	assert.ErrorContains(t, err, "not authorized")
+	repo.AssertNotCalled(t, "EnsureIndexes", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEnsureSchema_DedupeErrorStopsIndexes(t *testing.T) {
+	repo := new(MockSchemaRepository)
+	ctx := context.Background()
+
+	repo.On("EnsureValidator", ctx, transactionsCollection, transactionSchema).Return(nil)
+	repo.On("HasIndex", ctx, transactionsCollection, "id_status").Return(false, nil)
+	repo.On("MoveDuplicates", ctx, transactionsCollection, []string{"id", "status"}, duplicatesCollection).Return(0, errors.New("cursor killed"))
+
+	err := EnsureSchema(ctx, repo)
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "EnsureIndexes", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionSchema(t *testing.T) {
+	// Failed entries carry no amount, so only the keys may be required
+	assert.Equal(t, bson.A{"id", "accountId", "status"}, transactionSchema["required"])
+	properties := transactionSchema["properties"].(bson.M)
+	assert.Equal(t, bson.M{"enum": bson.A{"success", "failed", "pending_review"}}, properties["status"])
+}
@@ -3,40 +3,157 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"ledger/model"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type ledgerService struct {
 	collection *mongo.Collection
+	states     *mongo.Collection
 }
 
 type LedgerService interface {
 	HandleMessage(ctx context.Context, msg kafka.Message) error
 	GetAccountTransactionHistory(ctx context.Context, accountID string) ([]map[string]interface{}, error)
+	// GetAccountEntries returns up to limit successful entries of the account, oldest first, that
+	// come after the entry accepted at afterAcceptedAt with afterID. Entries are ordered by
+	// acceptedAt and then id, so a page continues from the last entry of the one before.
+	GetAccountEntries(ctx context.Context, accountID string, afterAcceptedAt time.Time, afterID string, limit int) ([]model.LedgerEntry, error)
 	GetTransactionHistory(ctx context.Context, id string) ([]map[string]interface{}, error)
+	GetTransaction(ctx context.Context, id string) (map[string]interface{}, error)
+}
+
+// stageVersions orders the statuses a transaction passes through. The consolidated view of a
+// transaction only moves to a later stage; success and failed are both final.
+var stageVersions = map[string]int{
+	"pending_review": 1,
+	"success":        2,
+	"failed":         2,
 }
 
 func NewledgerService(db *mongo.Database) LedgerService {
 	return &ledgerService{
-		collection: db.Collection("transactions"),
+		collection: db.Collection(transactionsCollection),
+		states:     db.Collection(transactionStatesCollection),
 	}
 }
 
+// HandleMessage records the transaction once per status, so redelivered messages are no-ops, and
+// advances the consolidated view of the transaction. Each entry is chained to the entry recorded
+// before it for the same account.
 func (s *ledgerService) HandleMessage(ctx context.Context, msg kafka.Message) error {
 	var transaction map[string]interface{}
 	if err := json.Unmarshal(msg.Value, &transaction); err != nil {
 		return err
 	}
+	id, _ := transaction["id"].(string)
+	status, _ := transaction["status"].(string)
+	if id == "" || status == "" {
+		return fmt.Errorf("ledger entry needs an id and a status")
+	}
 
-	_, err := s.collection.InsertOne(ctx, transaction)
-	if err != nil {
+	// recordedAt lets reconciliation pick up the accounts that changed since its last run
+	entry := make(map[string]interface{}, len(transaction)+1)
+	for k, v := range transaction {
+		entry[k] = v
+	}
+	entry["recordedAt"] = time.Now().UTC().Truncate(time.Millisecond)
+
+	if err := s.appendEntry(ctx, id, status, entry); err != nil {
 		return err
 	}
+	return s.advanceState(ctx, id, status, transaction)
+}
+
+// appendEntry records the entry at the head of its account's hash chain unless the transaction
+// already has an entry for the status. A writer chaining another entry to the same head makes
+// the insert collide on the previous hash, and the entry is chained again to the new head. An
+// entry still colliding after maxChainAppend attempts points at a forked or corrupted chain.
+func (s *ledgerService) appendEntry(ctx context.Context, id, status string, entry map[string]interface{}) error {
+	accountID, _ := entry["accountId"].(string)
+	delay := chainRetryDelay
+	for attempt := 1; ; attempt++ {
+		head, err := s.chainHead(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		entry[chainSeqField] = head.Seq + 1
+		entry[prevHashField] = head.Hash
+		entry[hashField] = entryHash(entry)
+
+		_, err = s.collection.UpdateOne(ctx,
+			bson.M{"id": id, "status": status},
+			bson.M{"$setOnInsert": entry},
+			options.UpdateOne().SetUpsert(true))
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		if attempt == maxChainAppend {
+			return fmt.Errorf("chain %s to account %s: still colliding after %d attempts: %w", id, accountID, attempt, err)
+		}
+
+		// Writers racing for a busy account back off with jitter so one of them wins
+		logger.Ctx(ctx).Debug().Str("accountId", accountID).Msgf("Chain head moved; chaining %s again", id)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay/2 + rand.N(delay/2+1)):
+		}
+		delay = min(delay*2, chainRetryMaxDelay)
+	}
+}
+
+// chainHead returns the last chained entry of the account, or the genesis of its chain.
+func (s *ledgerService) chainHead(ctx context.Context, accountID string) (model.ChainHead, error) {
+	var last struct {
+		Seq  int64  `bson:"chainSeq"`
+		Hash string `bson:"hash"`
+	}
+	err := s.collection.FindOne(ctx,
+		bson.M{"accountId": accountID, chainSeqField: bson.M{"$exists": true}},
+		options.FindOne().
+			SetSort(bson.D{{Key: chainSeqField, Value: -1}}).
+			SetProjection(bson.M{chainSeqField: 1, hashField: 1})).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.ChainHead{AccountID: accountID, Hash: genesisHash(accountID)}, nil
+	}
+	if err != nil {
+		return model.ChainHead{}, err
+	}
+	return model.ChainHead{AccountID: accountID, Seq: last.Seq, Hash: last.Hash}, nil
+}
+
+// advanceState replaces the consolidated view of the transaction unless it already holds the
+// same or a later stage.
+func (s *ledgerService) advanceState(ctx context.Context, id, status string, transaction map[string]interface{}) error {
+	version := stageVersions[status]
+	state := make(map[string]interface{}, len(transaction)+2)
+	for k, v := range transaction {
+		state[k] = v
+	}
+	state["version"] = version
+	state["updatedAt"] = time.Now().UTC()
 
-	return nil
+	_, err := s.states.ReplaceOne(ctx,
+		bson.M{"id": id, "version": bson.M{"$lt": version}},
+		state,
+		options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// The view already holds this or a later stage; the upsert collided with it on id
+		logger.Ctx(ctx).Info().Msgf("Ignoring status %s of transaction %s: a later stage is recorded", status, id)
+		return nil
+	}
+	return err
 }
 
 func (s *ledgerService) GetAccountTransactionHistory(ctx context.Context, accountID string) ([]map[string]interface{}, error) {
@@ -57,6 +174,35 @@ func (s *ledgerService) GetAccountTransactionHistory(ctx context.Context, accoun
 	return transactions, nil
 }
 
+func (s *ledgerService) GetAccountEntries(ctx context.Context, accountID string, afterAcceptedAt time.Time, afterID string, limit int) ([]model.LedgerEntry, error) {
+	// Timestamps stored as strings are converted so every entry orders and decodes the same way
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"accountId": accountID, "status": "success"}}},
+		{{Key: "$set", Value: bson.M{"acceptedAt": bson.M{"$toDate": "$acceptedAt"}}}},
+	}
+	if !afterAcceptedAt.IsZero() {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"acceptedAt": bson.M{"$gt": afterAcceptedAt}},
+			bson.M{"acceptedAt": afterAcceptedAt, "id": bson.M{"$gt": afterID}},
+		}}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "acceptedAt", Value: 1}, {Key: "id", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	)
+	cursor, err := s.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []model.LedgerEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func (s *ledgerService) GetTransactionHistory(ctx context.Context, id string) ([]map[string]interface{}, error) {
 	filter := map[string]interface{}{
 		"id": id,
@@ -74,3 +220,12 @@ func (s *ledgerService) GetTransactionHistory(ctx context.Context, id string) ([
 
 	return transactions, nil
 }
+
+// GetTransaction returns the consolidated view of a transaction at its latest stage.
+func (s *ledgerService) GetTransaction(ctx context.Context, id string) (map[string]interface{}, error) {
+	var transaction map[string]interface{}
+	if err := s.states.FindOne(ctx, bson.M{"id": id}).Decode(&transaction); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"ledger/model"
+	"ledger/repository"
+
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+)
+
+// reconciliationChunk bounds the number of accounts looked up per query.
+const reconciliationChunk = 500
+
+// balanceTolerance absorbs rounding below the 4 decimal places balances are stored with.
+const balanceTolerance = 0.00005
+
+type ReconciliationService interface {
+	Run(ctx context.Context, mode string) (*model.ReconciliationReport, error)
+	GetReport(ctx context.Context, id uuid.UUID) (*model.ReconciliationReport, error)
+	ListRuns(ctx context.Context, limit int) ([]model.ReconciliationRun, error)
+}
+
+type reconciliationService struct {
+	repo   repository.ReconciliationRepository
+	ledger repository.LedgerRepository
+	now    func() time.Time
+}
+
+func NewReconciliationService(repo repository.ReconciliationRepository, ledger repository.LedgerRepository) ReconciliationService {
+	return &reconciliationService{
+		repo:   repo,
+		ledger: ledger,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run compares account balances with the sum of their successful ledger entries. An
+// incremental run without a previous completed run falls back to a full run.
+func (s *reconciliationService) Run(ctx context.Context, mode string) (*model.ReconciliationReport, error) {
+	unlock, err := s.repo.Lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var from *time.Time
+	if mode == model.ReconciliationIncremental {
+		if from, err = s.repo.LastWatermark(ctx); err != nil {
+			return nil, err
+		}
+		if from == nil {
+			mode = model.ReconciliationFull
+		}
+	}
+
+	run := &model.ReconciliationRun{
+		ID:            uuid.New(),
+		Mode:          mode,
+		Status:        model.ReconciliationRunning,
+		WatermarkFrom: from,
+		WatermarkTo:   s.now(),
+		StartedAt:     s.now(),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	breaks, runErr := s.reconcile(ctx, run)
+	finishedAt := s.now()
+	run.FinishedAt = &finishedAt
+	run.Status = model.ReconciliationCompleted
+	if runErr != nil {
+		run.Status = model.ReconciliationFailed
+		run.Error = runErr.Error()
+		breaks = nil
+	}
+	run.BreaksFound = len(breaks)
+
+	// Record the outcome even if the caller went away
+	if err := s.repo.FinishRun(context.WithoutCancel(ctx), run, breaks); err != nil {
+		return nil, err
+	}
+
+	var amount float64
+	for _, b := range breaks {
+		amount += math.Abs(b.Difference)
+	}
+	metrics.RecordReconciliation(run.Mode, run.Status, run.AccountsChecked, run.BreaksFound, amount)
+	logger.Ctx(ctx).Info().
+		Str("runId", run.ID.String()).
+		Str("mode", run.Mode).
+		Str("status", run.Status).
+		Int("accountsChecked", run.AccountsChecked).
+		Int("breaks", run.BreaksFound).
+		Msg("Reconciliation finished")
+
+	if runErr != nil {
+		return nil, runErr
+	}
+	return &model.ReconciliationReport{ReconciliationRun: *run, Breaks: breaks}, nil
+}
+
+func (s *reconciliationService) reconcile(ctx context.Context, run *model.ReconciliationRun) ([]model.ReconciliationBreak, error) {
+	accountIDs, err := s.accountsToCheck(ctx, run.WatermarkFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	var breaks []model.ReconciliationBreak
+	for start := 0; start < len(accountIDs); start += reconciliationChunk {
+		chunk := accountIDs[start:min(start+reconciliationChunk, len(accountIDs))]
+		balances, err := s.repo.AccountBalances(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		totals, err := s.ledger.Totals(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, accountID := range chunk {
+			total := totals[accountID]
+			balance, exists := balances[accountID]
+			difference := balance - total.Balance
+			if exists && math.Abs(difference) < balanceTolerance {
+				continue
+			}
+			entries, err := s.ledger.Entries(ctx, accountID)
+			if err != nil {
+				return nil, err
+			}
+			b := model.ReconciliationBreak{
+				RunID:         run.ID,
+				AccountID:     accountID,
+				LedgerBalance: total.Balance,
+				Difference:    difference,
+				EntryCount:    total.EntryCount,
+				Transactions:  entries,
+			}
+			if exists {
+				b.AccountBalance = &balance
+			}
+			breaks = append(breaks, b)
+		}
+	}
+	run.AccountsChecked = len(accountIDs)
+	return breaks, nil
+}
+
+// accountsToCheck returns every account for a full run. Otherwise it returns the accounts
+// whose balance or ledger entries changed after from, and those still out of balance at the
+// previous run so that resolved breaks are cleared.
+func (s *reconciliationService) accountsToCheck(ctx context.Context, from *time.Time) ([]string, error) {
+	var sources []func(context.Context) ([]string, error)
+	if from == nil {
+		sources = append(sources, s.repo.AllAccountIDs, s.ledger.AccountIDs)
+	} else {
+		sources = append(sources,
+			func(ctx context.Context) ([]string, error) { return s.repo.AccountsUpdatedSince(ctx, *from) },
+			func(ctx context.Context) ([]string, error) { return s.ledger.AccountsRecordedSince(ctx, *from) },
+			s.repo.LastBreakAccounts,
+		)
+	}
+
+	seen := make(map[string]bool)
+	var accountIDs []string
+	for _, source := range sources {
+		ids, err := source(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				accountIDs = append(accountIDs, id)
+			}
+		}
+	}
+	sort.Strings(accountIDs)
+	return accountIDs, nil
+}
+
+func (s *reconciliationService) GetReport(ctx context.Context, id uuid.UUID) (*model.ReconciliationReport, error) {
+	run, err := s.repo.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	breaks, err := s.repo.ListBreaks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ReconciliationReport{ReconciliationRun: *run, Breaks: breaks}, nil
+}
+
+func (s *reconciliationService) ListRuns(ctx context.Context, limit int) ([]model.ReconciliationRun, error) {
+	return s.repo.ListRuns(ctx, limit)
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ledger/model"
+	"ledger/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReconciliationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationRepository) Lock(ctx context.Context) (func(), error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(func()), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) LastWatermark(ctx context.Context) (*time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) LastBreakAccounts(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) AccountBalances(ctx context.Context, accountIDs []string) (map[string]float64, error) {
+	args := m.Called(ctx, accountIDs)
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) AllAccountIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) AccountsUpdatedSince(ctx context.Context, since time.Time) ([]string, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) CreateRun(ctx context.Context, run *model.ReconciliationRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationRepository) FinishRun(ctx context.Context, run *model.ReconciliationRun, breaks []model.ReconciliationBreak) error {
+	args := m.Called(ctx, run, breaks)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationRepository) GetRun(ctx context.Context, id uuid.UUID) (*model.ReconciliationRun, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.ReconciliationRun), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) ListRuns(ctx context.Context, limit int) ([]model.ReconciliationRun, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]model.ReconciliationRun), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) ListBreaks(ctx context.Context, runID uuid.UUID) ([]model.ReconciliationBreak, error) {
+	args := m.Called(ctx, runID)
+	return args.Get(0).([]model.ReconciliationBreak), args.Error(1)
+}
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) AccountIDs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockLedgerRepository) AccountsRecordedSince(ctx context.Context, since time.Time) ([]string, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockLedgerRepository) Totals(ctx context.Context, accountIDs []string) (map[string]model.LedgerTotal, error) {
+	args := m.Called(ctx, accountIDs)
+	return args.Get(0).(map[string]model.LedgerTotal), args.Error(1)
+}
+
+func (m *MockLedgerRepository) Entries(ctx context.Context, accountID string) ([]model.LedgerEntry, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.LedgerEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) BalanceBefore(ctx context.Context, accountID string, before time.Time) (float64, error) {
+	args := m.Called(ctx, accountID, before)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockLedgerRepository) StreamEntries(ctx context.Context, accountID string, from, to time.Time, fn func(model.LedgerEntry) error) error {
+	args := m.Called(ctx, accountID, from, to, fn)
+	for _, entry := range args.Get(0).([]model.LedgerEntry) {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestReconcile_Full(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	mockLedger := new(MockLedgerRepository)
+	service := NewReconciliationService(mockRepo, mockLedger)
+
+	ctx := context.Background()
+	unlocked := false
+	entries := []model.LedgerEntry{{ID: "txn-1", TransactionType: "credit", Amount: 90}}
+	mockRepo.On("Lock", ctx).Return(func() { unlocked = true }, nil)
+	mockRepo.On("CreateRun", ctx, mock.Anything).Return(nil)
+	mockRepo.On("AllAccountIDs", ctx).Return([]string{"a", "b"}, nil)
+	mockLedger.On("AccountIDs", ctx).Return([]string{"b", "c"}, nil)
+	mockRepo.On("AccountBalances", ctx, []string{"a", "b", "c"}).Return(map[string]float64{"a": 100, "b": 50.25}, nil)
+	mockLedger.On("Totals", ctx, []string{"a", "b", "c"}).Return(map[string]model.LedgerTotal{
+		"a": {Balance: 90, EntryCount: 1},
+		"b": {Balance: 50.25, EntryCount: 3},
+		"c": {Balance: 10, EntryCount: 1},
+	}, nil)
+	mockLedger.On("Entries", ctx, "a").Return(entries, nil)
+	mockLedger.On("Entries", ctx, "c").Return([]model.LedgerEntry{}, nil)
+	mockRepo.On("FinishRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	report, err := service.Run(ctx, model.ReconciliationFull)
+	assert.NoError(t, err)
+	assert.True(t, unlocked)
+	assert.Equal(t, model.ReconciliationCompleted, report.Status)
+	assert.Nil(t, report.WatermarkFrom)
+	assert.Equal(t, 3, report.AccountsChecked)
+	assert.Equal(t, 2, report.BreaksFound)
+	if assert.Len(t, report.Breaks, 2) {
+		assert.Equal(t, "a", report.Breaks[0].AccountID)
+		assert.Equal(t, 10.0, report.Breaks[0].Difference)
+		assert.Equal(t, entries, report.Breaks[0].Transactions)
+		assert.Equal(t, "c", report.Breaks[1].AccountID)
+		assert.Nil(t, report.Breaks[1].AccountBalance)
+		assert.Equal(t, -10.0, report.Breaks[1].Difference)
+	}
+	mockLedger.AssertNotCalled(t, "Entries", ctx, "b")
+}
+
+func TestReconcile_IncrementalFromWatermark(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	mockLedger := new(MockLedgerRepository)
+	service := NewReconciliationService(mockRepo, mockLedger)
+
+	ctx := context.Background()
+	watermark := time.Now().UTC().Add(-time.Hour)
+	mockRepo.On("Lock", ctx).Return(func() {}, nil)
+	mockRepo.On("LastWatermark", ctx).Return(&watermark, nil)
+	mockRepo.On("CreateRun", ctx, mock.Anything).Return(nil)
+	mockRepo.On("AccountsUpdatedSince", ctx, watermark).Return([]string{"b"}, nil)
+	mockLedger.On("AccountsRecordedSince", ctx, watermark).Return([]string{"a", "b"}, nil)
+	mockRepo.On("LastBreakAccounts", ctx).Return([]string{"d"}, nil)
+	mockRepo.On("AccountBalances", ctx, []string{"a", "b", "d"}).Return(map[string]float64{"a": 5, "b": 0, "d": 1}, nil)
+	mockLedger.On("Totals", ctx, []string{"a", "b", "d"}).Return(map[string]model.LedgerTotal{
+		"a": {Balance: 5, EntryCount: 1},
+		"d": {Balance: 1, EntryCount: 1},
+	}, nil)
+	mockRepo.On("FinishRun", mock.Anything, mock.Anything, []model.ReconciliationBreak(nil)).Return(nil)
+
+	report, err := service.Run(ctx, model.ReconciliationIncremental)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReconciliationIncremental, report.Mode)
+	assert.Equal(t, &watermark, report.WatermarkFrom)
+	assert.Equal(t, 3, report.AccountsChecked)
+	assert.Empty(t, report.Breaks)
+	mockRepo.AssertNotCalled(t, "AllAccountIDs", ctx)
+}
+
+func TestReconcile_IncrementalWithoutPreviousRunIsFull(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	mockLedger := new(MockLedgerRepository)
+	service := NewReconciliationService(mockRepo, mockLedger)
+
+	ctx := context.Background()
+	mockRepo.On("Lock", ctx).Return(func() {}, nil)
+	mockRepo.On("LastWatermark", ctx).Return((*time.Time)(nil), nil)
+	mockRepo.On("CreateRun", ctx, mock.Anything).Return(nil)
+	mockRepo.On("AllAccountIDs", ctx).Return([]string{}, nil)
+	mockLedger.On("AccountIDs", ctx).Return([]string{}, nil)
+	mockRepo.On("FinishRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	report, err := service.Run(ctx, model.ReconciliationIncremental)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReconciliationFull, report.Mode)
+}
+
+func TestReconcile_FailureIsRecorded(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	mockLedger := new(MockLedgerRepository)
+	service := NewReconciliationService(mockRepo, mockLedger)
+
+	ctx := context.Background()
+	mockRepo.On("Lock", ctx).Return(func() {}, nil)
+	mockRepo.On("CreateRun", ctx, mock.Anything).Return(nil)
+	mockRepo.On("AllAccountIDs", ctx).Return([]string{}, errors.New("connection refused"))
+	mockRepo.On("FinishRun", mock.Anything, mock.MatchedBy(func(run *model.ReconciliationRun) bool {
+		return run.Status == model.ReconciliationFailed && run.Error == "connection refused"
+	}), []model.ReconciliationBreak(nil)).Return(nil)
+
+	_, err := service.Run(ctx, model.ReconciliationFull)
+	assert.EqualError(t, err, "connection refused")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReconcile_Locked(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	service := NewReconciliationService(mockRepo, new(MockLedgerRepository))
+
+	ctx := context.Background()
+	mockRepo.On("Lock", ctx).Return(nil, repository.ErrReconciliationLocked)
+
+	_, err := service.Run(ctx, model.ReconciliationFull)
+	assert.ErrorIs(t, err, repository.ErrReconciliationLocked)
+	mockRepo.AssertNotCalled(t, "CreateRun", mock.Anything, mock.Anything)
+}
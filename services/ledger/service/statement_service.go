@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"ledger/model"
+	"ledger/repository"
+	"ledger/statement"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+type StatementService interface {
+	Generate(ctx context.Context, accountID string, from, to time.Time, w statement.Writer) error
+	GenerateMonth(ctx context.Context, month time.Time) error
+	ListStored(ctx context.Context, accountID string) ([]model.StoredStatement, error)
+	GetStored(ctx context.Context, accountID, period, format string) (*model.StoredStatement, error)
+}
+
+type statementService struct {
+	ledger   repository.LedgerRepository
+	store    repository.StatementRepository
+	formats  []string
+	currency string
+	now      func() time.Time
+}
+
+// NewStatementService stores month-end statements in each of formats. Balances are stated in
+// currency.
+func NewStatementService(ledger repository.LedgerRepository, store repository.StatementRepository, formats []string, currency string) StatementService {
+	return &statementService{
+		ledger:   ledger,
+		store:    store,
+		formats:  formats,
+		currency: currency,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Generate writes the statement of [from, to) to w as the entries are read from the ledger.
+func (s *statementService) Generate(ctx context.Context, accountID string, from, to time.Time, w statement.Writer) error {
+	opening, err := s.ledger.BalanceBefore(ctx, accountID, from)
+	if err != nil {
+		return err
+	}
+	err = w.Begin(model.StatementHeader{
+		AccountID:      accountID,
+		Currency:       s.currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: round2(opening),
+		GeneratedAt:    s.now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	totals := model.StatementTotals{}
+	balance := opening
+	err = s.ledger.StreamEntries(ctx, accountID, from, to, func(entry model.LedgerEntry) error {
+		amount := entry.Amount
+		if entry.TransactionType == "debit" {
+			amount = -amount
+			totals.Debits += entry.Amount
+		} else {
+			totals.Credits += entry.Amount
+		}
+		switch entry.Category {
+		case model.CategoryInterest:
+			totals.Interest += amount
+		case model.CategoryFee:
+			totals.Fees -= amount
+		}
+		balance += amount
+		totals.EntryCount++
+
+		return w.Line(model.StatementLine{
+			Date:          entry.AcceptedAt,
+			TransactionID: entry.ID,
+			Description:   entry.Details,
+			Category:      entry.Category,
+			Type:          entry.TransactionType,
+			Amount:        amount,
+			Balance:       round2(balance),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	totals.Credits = round2(totals.Credits)
+	totals.Debits = round2(totals.Debits)
+	totals.Interest = round2(totals.Interest)
+	totals.Fees = round2(totals.Fees)
+	totals.ClosingBalance = round2(balance)
+	return w.End(totals)
+}
+
+// GenerateMonth stores the statements of the calendar month containing month for every
+// account with ledger entries. Statements already stored are kept, so a failed run can be
+// repeated.
+func (s *statementService) GenerateMonth(ctx context.Context, month time.Time) error {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	period := from.Format("2006-01")
+
+	accountIDs, err := s.ledger.AccountIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	stored := 0
+	for _, accountID := range accountIDs {
+		for _, format := range s.formats {
+			created, err := s.storeStatement(ctx, accountID, period, format, from, to)
+			if err != nil {
+				logger.Ctx(ctx).Error().Err(err).Msgf("Failed to generate %s statement for account %s period %s", format, accountID, period)
+				errs = append(errs, err)
+				continue
+			}
+			if created {
+				stored++
+			}
+		}
+	}
+	logger.Ctx(ctx).Info().Msgf("Stored %d statements for period %s", stored, period)
+	return errors.Join(errs...)
+}
+
+func (s *statementService) storeStatement(ctx context.Context, accountID, period, format string, from, to time.Time) (bool, error) {
+	exists, err := s.store.Exists(ctx, accountID, period, format)
+	if err != nil || exists {
+		return false, err
+	}
+
+	var content bytes.Buffer
+	recorder := &totalsRecorder{}
+	w, err := statement.NewWriter(format, &content)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Generate(ctx, accountID, from, to, recorder.wrap(w)); err != nil {
+		return false, err
+	}
+
+	return true, s.store.Save(ctx, &model.StoredStatement{
+		AccountID:      accountID,
+		Period:         period,
+		Format:         format,
+		From:           from,
+		To:             to,
+		OpeningBalance: recorder.header.OpeningBalance,
+		ClosingBalance: recorder.totals.ClosingBalance,
+		EntryCount:     recorder.totals.EntryCount,
+		Content:        content.Bytes(),
+		GeneratedAt:    recorder.header.GeneratedAt,
+	})
+}
+
+func (s *statementService) ListStored(ctx context.Context, accountID string) ([]model.StoredStatement, error) {
+	return s.store.List(ctx, accountID)
+}
+
+func (s *statementService) GetStored(ctx context.Context, accountID, period, format string) (*model.StoredStatement, error) {
+	return s.store.Get(ctx, accountID, period, format)
+}
+
+// totalsRecorder keeps the header and totals passing through a Writer for the stored metadata.
+type totalsRecorder struct {
+	statement.Writer
+	header model.StatementHeader
+	totals model.StatementTotals
+}
+
+func (r *totalsRecorder) wrap(w statement.Writer) statement.Writer {
+	r.Writer = w
+	return r
+}
+
+func (r *totalsRecorder) Begin(header model.StatementHeader) error {
+	r.header = header
+	return r.Writer.Begin(header)
+}
+
+func (r *totalsRecorder) End(totals model.StatementTotals) error {
+	r.totals = totals
+	return r.Writer.End(totals)
+}
+
+// round2 rounds to cents for display.
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ledger/model"
+	"ledger/statement"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatementRepository) Exists(ctx context.Context, accountID, period, format string) (bool, error) {
+	args := m.Called(ctx, accountID, period, format)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStatementRepository) Save(ctx context.Context, statement *model.StoredStatement) error {
+	args := m.Called(ctx, statement)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) Get(ctx context.Context, accountID, period, format string) (*model.StoredStatement, error) {
+	args := m.Called(ctx, accountID, period, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.StoredStatement), args.Error(1)
+}
+
+func (m *MockStatementRepository) List(ctx context.Context, accountID string) ([]model.StoredStatement, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.StoredStatement), args.Error(1)
+}
+
+var statementEntries = []model.LedgerEntry{
+	{ID: "t1", TransactionType: "credit", Amount: 100, AcceptedAt: time.Date(2026, 9, 2, 10, 0, 0, 0, time.UTC)},
+	{ID: "t2", TransactionType: "debit", Amount: 30.5, Details: "Groceries", AcceptedAt: time.Date(2026, 9, 10, 10, 0, 0, 0, time.UTC)},
+	{ID: "t3", TransactionType: "debit", Amount: 2.5, Category: model.CategoryFee, AcceptedAt: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)},
+	{ID: "t4", TransactionType: "credit", Amount: 0.75, Category: model.CategoryInterest, AcceptedAt: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)},
+}
+
+func TestGenerateStatement(t *testing.T) {
+	mockLedger := new(MockLedgerRepository)
+	service := NewStatementService(mockLedger, new(MockStatementRepository), nil, "USD")
+
+	ctx := context.Background()
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockLedger.On("BalanceBefore", ctx, "acc-1", from).Return(50.0, nil)
+	mockLedger.On("StreamEntries", ctx, "acc-1", from, to, mock.Anything).Return(statementEntries, nil)
+
+	var out bytes.Buffer
+	w, _ := statement.NewWriter(model.StatementJSON, &out)
+	err := service.Generate(ctx, "acc-1", from, to, w)
+	assert.NoError(t, err)
+
+	var result struct {
+		model.StatementHeader
+		Entries []model.StatementLine `json:"entries"`
+		Totals  model.StatementTotals `json:"totals"`
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, 50.0, result.OpeningBalance)
+	assert.Len(t, result.Entries, 4)
+	assert.Equal(t, -30.5, result.Entries[1].Amount)
+	assert.Equal(t, 119.5, result.Entries[1].Balance)
+	assert.Equal(t, model.StatementTotals{
+		EntryCount:     4,
+		Credits:        100.75,
+		Debits:         33,
+		Interest:       0.75,
+		Fees:           2.5,
+		ClosingBalance: 117.75,
+	}, result.Totals)
+}
+
+func TestGenerateMonth(t *testing.T) {
+	mockLedger := new(MockLedgerRepository)
+	mockStore := new(MockStatementRepository)
+	service := NewStatementService(mockLedger, mockStore, []string{model.StatementCSV, model.StatementText}, "USD")
+
+	ctx := context.Background()
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockLedger.On("AccountIDs", ctx).Return([]string{"acc-1"}, nil)
+	mockLedger.On("BalanceBefore", ctx, "acc-1", from).Return(50.0, nil)
+	mockLedger.On("StreamEntries", ctx, "acc-1", from, to, mock.Anything).Return(statementEntries, nil)
+	mockStore.On("Exists", ctx, "acc-1", "2026-09", model.StatementCSV).Return(false, nil)
+	mockStore.On("Exists", ctx, "acc-1", "2026-09", model.StatementText).Return(true, nil)
+	mockStore.On("Save", ctx, mock.MatchedBy(func(s *model.StoredStatement) bool {
+		return s.Period == "2026-09" && s.Format == model.StatementCSV &&
+			s.OpeningBalance == 50 && s.ClosingBalance == 117.75 && s.EntryCount == 4 &&
+			bytes.Contains(s.Content, []byte("Closing balance,,,,117.75"))
+	})).Return(nil).Once()
+
+	err := service.GenerateMonth(ctx, time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	mockStore.AssertExpectations(t)
+}
@@ -0,0 +1,241 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"time"
+
+	"ledger/model"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// camt053Writer renders an ISO 20022 BankToCustomerStatement. The schema puts the closing
+// balance ahead of the entries, so unlike the other formats the entries are held until End.
+type camt053Writer struct {
+	w       io.Writer
+	header  model.StatementHeader
+	entries []camtEntry
+}
+
+func newCAMT053Writer(w io.Writer) *camt053Writer {
+	return &camt053Writer{w: w}
+}
+
+type camtDocument struct {
+	XMLName   xml.Name      `xml:"Document"`
+	Namespace string        `xml:"xmlns,attr"`
+	Statement camtStatement `xml:"BkToCstmrStmt"`
+}
+
+type camtStatement struct {
+	GroupHeader camtGroupHeader `xml:"GrpHdr"`
+	Statement   camtStmt        `xml:"Stmt"`
+}
+
+type camtGroupHeader struct {
+	MessageID string `xml:"MsgId"`
+	Created   string `xml:"CreDtTm"`
+}
+
+type camtStmt struct {
+	ID       string        `xml:"Id"`
+	Created  string        `xml:"CreDtTm"`
+	Period   camtPeriod    `xml:"FrToDt"`
+	Account  camtAccount   `xml:"Acct"`
+	Balances []camtBalance `xml:"Bal"`
+	Summary  camtSummary   `xml:"TxsSummry"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	ID       string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Type   string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount camtAmount `xml:"Amt"`
+	Sign   string     `xml:"CdtDbtInd"`
+	Date   string     `xml:"Dt>Dt"`
+}
+
+type camtSummary struct {
+	Total   camtTotal `xml:"TtlNtries"`
+	Credits camtCount `xml:"TtlCdtNtries"`
+	Debits  camtCount `xml:"TtlDbtNtries"`
+}
+
+type camtTotal struct {
+	Count     int    `xml:"NbOfNtries"`
+	Sum       string `xml:"Sum"`
+	NetAmount string `xml:"TtlNetNtry>Amt"`
+	NetSign   string `xml:"TtlNetNtry>CdtDbtInd"`
+}
+
+type camtCount struct {
+	Count int    `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	Reference      string      `xml:"NtryRef"`
+	Amount         camtAmount  `xml:"Amt"`
+	Sign           string      `xml:"CdtDbtInd"`
+	Status         string      `xml:"Sts>Cd"`
+	BookingDate    string      `xml:"BookgDt>DtTm"`
+	ValueDate      string      `xml:"ValDt>Dt"`
+	ServicerRef    string      `xml:"AcctSvcrRef"`
+	Domain         string      `xml:"BkTxCd>Domn>Cd"`
+	Family         string      `xml:"BkTxCd>Domn>Fmly>Cd"`
+	SubFamily      string      `xml:"BkTxCd>Domn>Fmly>SubFmlyCd"`
+	Details        camtDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string      `xml:"AddtlNtryInf"`
+}
+
+type camtDetails struct {
+	TransactionID string          `xml:"Refs>TxId"`
+	Remittance    *camtRemittance `xml:"RmtInf,omitempty"`
+}
+
+type camtRemittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
+
+func (c *camt053Writer) Begin(header model.StatementHeader) error {
+	c.header = header
+	return nil
+}
+
+func (c *camt053Writer) Line(line model.StatementLine) error {
+	domain, family, subFamily := bankTransactionCode(line)
+	entry := camtEntry{
+		Reference:      reference(line.TransactionID, 35),
+		Amount:         c.amount(line.Amount),
+		Sign:           camtSign(line.Amount),
+		Status:         "BOOK",
+		BookingDate:    line.Date.UTC().Format(time.RFC3339),
+		ValueDate:      line.Date.UTC().Format(time.DateOnly),
+		ServicerRef:    reference(line.TransactionID, 35),
+		Domain:         domain,
+		Family:         family,
+		SubFamily:      subFamily,
+		Details:        camtDetails{TransactionID: reference(line.TransactionID, 35)},
+		AdditionalInfo: line.TransactionID,
+	}
+	if line.Description != "" {
+		entry.Details.Remittance = &camtRemittance{Unstructured: truncate(line.Description, 140)}
+	}
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+func (c *camt053Writer) End(totals model.StatementTotals) error {
+	header := c.header
+	id := header.From.UTC().Format("20060102") + reference(header.AccountID, 27)
+	created := header.GeneratedAt.UTC().Format(time.RFC3339)
+	net := totals.Credits - totals.Debits
+
+	document := camtDocument{
+		Namespace: camt053Namespace,
+		Statement: camtStatement{
+			GroupHeader: camtGroupHeader{MessageID: id, Created: created},
+			Statement: camtStmt{
+				ID:      id,
+				Created: created,
+				Period: camtPeriod{
+					From: header.From.UTC().Format(time.RFC3339),
+					To:   header.To.UTC().Add(-time.Second).Format(time.RFC3339),
+				},
+				Account: camtAccount{ID: reference(header.AccountID, 34), Currency: header.Currency},
+				Balances: []camtBalance{
+					c.balance("OPBD", header.OpeningBalance, header.From),
+					c.balance("CLBD", totals.ClosingBalance, header.To.AddDate(0, 0, -1)),
+				},
+				Summary: camtSummary{
+					Total: camtTotal{
+						Count:     totals.EntryCount,
+						Sum:       amount(totals.Credits + totals.Debits),
+						NetAmount: amount(math.Abs(net)),
+						NetSign:   camtSign(net),
+					},
+					Credits: camtCount{Count: c.count("CRDT"), Sum: amount(totals.Credits)},
+					Debits:  camtCount{Count: c.count("DBIT"), Sum: amount(totals.Debits)},
+				},
+				Entries: c.entries,
+			},
+		},
+	}
+
+	if _, err := io.WriteString(c.w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(c.w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.w, "\n")
+	return err
+}
+
+func (c *camt053Writer) balance(code string, value float64, date time.Time) camtBalance {
+	return camtBalance{
+		Type:   code,
+		Amount: c.amount(value),
+		Sign:   camtSign(value),
+		Date:   date.UTC().Format(time.DateOnly),
+	}
+}
+
+func (c *camt053Writer) amount(value float64) camtAmount {
+	return camtAmount{Currency: c.header.Currency, Value: amount(math.Abs(value))}
+}
+
+func (c *camt053Writer) count(sign string) int {
+	count := 0
+	for _, entry := range c.entries {
+		if entry.Sign == sign {
+			count++
+		}
+	}
+	return count
+}
+
+func camtSign(value float64) string {
+	if value < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// bankTransactionCode is the ISO 20022 domain, family and sub-family of an entry.
+func bankTransactionCode(line model.StatementLine) (string, string, string) {
+	switch {
+	case line.Category == model.CategoryInterest:
+		return "ACMT", "MCOP", "INTR"
+	case line.Category == model.CategoryFee:
+		return "ACMT", "MDOP", "CHRG"
+	case line.Amount < 0:
+		return "PMNT", "ICDT", "OTHR"
+	}
+	return "PMNT", "RCDT", "OTHR"
+}
+
+func truncate(value string, size int) string {
+	if runes := []rune(value); len(runes) > size {
+		return string(runes[:size])
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"ledger/model"
+)
+
+// csvWriter puts the opening balance, the entries and the totals in one table; summary rows
+// carry their label in the description column.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Begin(header model.StatementHeader) error {
+	if err := c.w.Write([]string{"date", "transaction_id", "description", "category", "type", "amount", "balance"}); err != nil {
+		return err
+	}
+	return c.summary(header.From, "Opening balance", header.OpeningBalance)
+}
+
+func (c *csvWriter) Line(line model.StatementLine) error {
+	return c.w.Write([]string{
+		line.Date.UTC().Format(time.RFC3339),
+		line.TransactionID,
+		line.Description,
+		line.Category,
+		line.Type,
+		amount(line.Amount),
+		amount(line.Balance),
+	})
+}
+
+func (c *csvWriter) End(totals model.StatementTotals) error {
+	rows := []struct {
+		label string
+		value float64
+	}{
+		{"Total credits", totals.Credits},
+		{"Total debits", -totals.Debits},
+		{"Interest", totals.Interest},
+		{"Fees", -totals.Fees},
+	}
+	for _, row := range rows {
+		if err := c.w.Write([]string{"", "", row.label, "", "", amount(row.value), ""}); err != nil {
+			return err
+		}
+	}
+	if err := c.summary(time.Time{}, "Closing balance", totals.ClosingBalance); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) summary(date time.Time, label string, balance float64) error {
+	var day string
+	if !date.IsZero() {
+		day = date.UTC().Format(time.RFC3339)
+	}
+	return c.w.Write([]string{day, "", label, "", "", "", amount(balance)})
+}
@@ -0,0 +1,61 @@
+package statement
+
+import (
+	"encoding/json"
+	"io"
+
+	"ledger/model"
+)
+
+// jsonWriter writes a single object, emitting the entries array as they come.
+type jsonWriter struct {
+	w     io.Writer
+	lines int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: w}
+}
+
+func (j *jsonWriter) Begin(header model.StatementHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	// Reopen the header object to append the entries
+	if _, err := j.w.Write(data[:len(data)-1]); err != nil {
+		return err
+	}
+	_, err = io.WriteString(j.w, `,"entries":[`)
+	return err
+}
+
+func (j *jsonWriter) Line(line model.StatementLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if j.lines > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.lines++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) End(totals model.StatementTotals) error {
+	data, err := json.Marshal(totals)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(j.w, `],"totals":`); err != nil {
+		return err
+	}
+	if _, err := j.w.Write(data); err != nil {
+		return err
+	}
+	_, err = io.WriteString(j.w, "}\n")
+	return err
+}
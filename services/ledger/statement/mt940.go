@@ -0,0 +1,103 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"ledger/model"
+)
+
+// mt940Writer renders the text block of a SWIFT MT940 customer statement message.
+type mt940Writer struct {
+	w      *bufio.Writer
+	header model.StatementHeader
+}
+
+func newMT940Writer(w io.Writer) *mt940Writer {
+	return &mt940Writer{w: bufio.NewWriter(w)}
+}
+
+func (m *mt940Writer) Begin(header model.StatementHeader) error {
+	m.header = header
+	fmt.Fprintf(m.w, ":20:%s\r\n", "STMT"+header.From.UTC().Format("060102"))
+	fmt.Fprintf(m.w, ":25:%s\r\n", reference(header.AccountID, 35))
+	fmt.Fprintf(m.w, ":28C:%s/1\r\n", header.From.UTC().Format("0601"))
+	fmt.Fprintf(m.w, ":60F:%s\r\n", m.balance(header.OpeningBalance, header.From))
+	return nil
+}
+
+func (m *mt940Writer) Line(line model.StatementLine) error {
+	date := line.Date.UTC()
+	mark := "C"
+	if line.Amount < 0 {
+		mark = "D"
+	}
+	fmt.Fprintf(m.w, ":61:%s%s%s%sN%s%s\r\n",
+		date.Format("060102"), date.Format("0102"), mark, mt940Amount(line.Amount),
+		swiftTransactionType(line), reference(line.TransactionID, 16))
+
+	details := line.TransactionID
+	if line.Description != "" {
+		details += " " + line.Description
+	}
+	_, err := fmt.Fprintf(m.w, ":86:%s\r\n", strings.Join(wrap(swiftText(details), 65, 6), "\r\n"))
+	return err
+}
+
+func (m *mt940Writer) End(totals model.StatementTotals) error {
+	fmt.Fprintf(m.w, ":62F:%s\r\n", m.balance(totals.ClosingBalance, m.header.To.AddDate(0, 0, -1)))
+	fmt.Fprint(m.w, "-\r\n")
+	return m.w.Flush()
+}
+
+func (m *mt940Writer) balance(value float64, date time.Time) string {
+	mark := "C"
+	if value < 0 {
+		mark = "D"
+	}
+	return mark + date.UTC().Format("060102") + m.header.Currency + mt940Amount(value)
+}
+
+// mt940Amount formats an absolute amount with the decimal comma SWIFT requires.
+func mt940Amount(value float64) string {
+	return strings.Replace(amount(math.Abs(value)), ".", ",", 1)
+}
+
+// swiftTransactionType is the SWIFT transaction type identification code of an entry.
+func swiftTransactionType(line model.StatementLine) string {
+	switch line.Category {
+	case model.CategoryInterest:
+		return "INT"
+	case model.CategoryFee:
+		return "CHG"
+	}
+	return "TRF"
+}
+
+// swiftText replaces the characters outside the SWIFT X character set.
+func swiftText(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("/-?:().,'+ ", r):
+			return r
+		}
+		return '.'
+	}, value)
+}
+
+// wrap splits value into at most lines lines of width characters.
+func wrap(value string, width, lines int) []string {
+	var result []string
+	for len(value) > 0 && len(result) < lines {
+		size := min(width, len(value))
+		result = append(result, value[:size])
+		value = value[size:]
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"ledger/model"
+)
+
+const textRule = "--------------------------------------------------------------------------------------------"
+
+// textWriter renders a fixed-width statement for printing or plain-text email.
+type textWriter struct {
+	w *bufio.Writer
+}
+
+func newTextWriter(w io.Writer) *textWriter {
+	return &textWriter{w: bufio.NewWriter(w)}
+}
+
+func (t *textWriter) Begin(header model.StatementHeader) error {
+	fmt.Fprintf(t.w, "ACCOUNT STATEMENT\n")
+	fmt.Fprintf(t.w, "Account:   %s\n", header.AccountID)
+	fmt.Fprintf(t.w, "Period:    %s to %s\n", header.From.UTC().Format(time.DateOnly), header.To.UTC().Add(-time.Nanosecond).Format(time.DateOnly))
+	fmt.Fprintf(t.w, "Generated: %s\n\n", header.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(t.w, "%-10s  %-36s  %-20s  %10s  %12s\n", "Date", "Transaction", "Description", "Amount", "Balance")
+	fmt.Fprintln(t.w, textRule)
+	fmt.Fprintf(t.w, "%-10s  %-36s  %-20s  %10s  %12s\n", header.From.UTC().Format(time.DateOnly), "", "Opening balance", "", amount(header.OpeningBalance))
+	return nil
+}
+
+func (t *textWriter) Line(line model.StatementLine) error {
+	description := line.Description
+	if description == "" {
+		description = line.Category
+	}
+	if runes := []rune(description); len(runes) > 20 {
+		description = string(runes[:17]) + "..."
+	}
+	_, err := fmt.Fprintf(t.w, "%-10s  %-36s  %-20s  %10s  %12s\n",
+		line.Date.UTC().Format(time.DateOnly), line.TransactionID, description, amount(line.Amount), amount(line.Balance))
+	return err
+}
+
+func (t *textWriter) End(totals model.StatementTotals) error {
+	fmt.Fprintln(t.w, textRule)
+	fmt.Fprintf(t.w, "%-24s %12s\n", "Entries:", fmt.Sprint(totals.EntryCount))
+	fmt.Fprintf(t.w, "%-24s %12s\n", "Total credits:", amount(totals.Credits))
+	fmt.Fprintf(t.w, "%-24s %12s\n", "Total debits:", amount(-totals.Debits))
+	fmt.Fprintf(t.w, "%-24s %12s\n", "  of which interest:", amount(totals.Interest))
+	fmt.Fprintf(t.w, "%-24s %12s\n", "  of which fees:", amount(-totals.Fees))
+	fmt.Fprintf(t.w, "%-24s %12s\n", "Closing balance:", amount(totals.ClosingBalance))
+	return t.w.Flush()
+}
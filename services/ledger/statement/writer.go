@@ -0,0 +1,76 @@
+// Package statement renders account statements line by line, so a statement can be streamed
+// to the client while its entries are read from the ledger.
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"ledger/model"
+)
+
+// Writer renders a statement. Begin is called once, then Line for every entry in date order,
+// then End.
+type Writer interface {
+	Begin(header model.StatementHeader) error
+	Line(line model.StatementLine) error
+	End(totals model.StatementTotals) error
+}
+
+// NewWriter returns a Writer rendering format to w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case model.StatementCSV:
+		return newCSVWriter(w), nil
+	case model.StatementJSON:
+		return newJSONWriter(w), nil
+	case model.StatementText:
+		return newTextWriter(w), nil
+	case model.StatementCAMT053:
+		return newCAMT053Writer(w), nil
+	case model.StatementMT940:
+		return newMT940Writer(w), nil
+	}
+	return nil, fmt.Errorf("unknown statement format %q", format)
+}
+
+// ContentType is the media type of format.
+func ContentType(format string) string {
+	switch format {
+	case model.StatementCSV:
+		return "text/csv; charset=utf-8"
+	case model.StatementJSON:
+		return "application/json; charset=utf-8"
+	case model.StatementCAMT053:
+		return "application/xml; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Extension is the file name extension of format.
+func Extension(format string) string {
+	switch format {
+	case model.StatementCAMT053:
+		return "xml"
+	case model.StatementMT940:
+		return "sta"
+	}
+	return format
+}
+
+func amount(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
+
+// reference maps a transaction or account ID onto a reference field of at most size
+// characters. UUIDs lose their hyphens, which fits them in the 35 characters of ISO 20022
+// references; the MT940 16 character references keep a prefix, so the full ID is repeated in
+// the entry details.
+func reference(id string, size int) string {
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) > size {
+		id = id[:size]
+	}
+	return id
+}
@@ -0,0 +1,76 @@
+package statement
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ledger/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+var (
+	testHeader = model.StatementHeader{
+		AccountID:      "8db6626d-5e84-4c4e-8cec-7dc54cb20ff5",
+		Currency:       "USD",
+		From:           time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 50,
+		GeneratedAt:    time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC),
+	}
+	testLines = []model.StatementLine{
+		{Date: time.Date(2026, 9, 2, 10, 15, 0, 0, time.UTC), TransactionID: "d6263bc8-0eeb-4195-9e64-81abd6d5685c", Description: "Salary", Type: "credit", Amount: 100, Balance: 150},
+		{Date: time.Date(2026, 9, 10, 18, 0, 0, 0, time.UTC), TransactionID: "152a42be-63b6-46f9-919e-ba3996eaa890", Description: "Groceries & more <store #12>", Type: "debit", Amount: -30.5, Balance: 119.5},
+		{Date: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), TransactionID: "0b7e1f4e-3c1d-4d8a-9a4e-52f0c1a7d9e2", Category: model.CategoryFee, Type: "debit", Amount: -2.5, Balance: 117},
+		{Date: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), TransactionID: "7f0a6c3b-95e2-4b1f-8d7c-2e4a9b6f1c30", Category: model.CategoryInterest, Type: "credit", Amount: 0.75, Balance: 117.75},
+	}
+	testTotals = model.StatementTotals{
+		EntryCount:     4,
+		Credits:        100.75,
+		Debits:         33,
+		Interest:       0.75,
+		Fees:           2.5,
+		ClosingBalance: 117.75,
+	}
+)
+
+func TestWriterGolden(t *testing.T) {
+	formats := []string{
+		model.StatementCSV,
+		model.StatementJSON,
+		model.StatementText,
+		model.StatementCAMT053,
+		model.StatementMT940,
+	}
+	for _, format := range formats {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			w, err := NewWriter(format, &out)
+			assert.NoError(t, err)
+			assert.NoError(t, w.Begin(testHeader))
+			for _, line := range testLines {
+				assert.NoError(t, w.Line(line))
+			}
+			assert.NoError(t, w.End(testTotals))
+
+			golden := filepath.Join("testdata", "statement."+Extension(format)+".golden")
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, out.Bytes(), 0o644))
+			}
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), out.String())
+		})
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{})
+	assert.Error(t, err)
+}
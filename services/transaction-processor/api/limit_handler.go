@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"transaction-processor/model"
+	"transaction-processor/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+	"github.com/shrishyam02/banking-ledger/common/server"
+)
+
+type limitHandler struct {
+	limitService   service.LimitService
+	accountService service.AccountService
+}
+
+type LimitHandler interface {
+	GetAccountLimits(c *gin.Context)
+	SetAccountLimit(c *gin.Context)
+	DeleteAccountLimit(c *gin.Context)
+	SetCustomerLimit(c *gin.Context)
+	DeleteCustomerLimit(c *gin.Context)
+	SetAccountTypeLimit(c *gin.Context)
+}
+
+func NewLimitHandler(limitService service.LimitService, accountService service.AccountService) LimitHandler {
+	return &limitHandler{
+		limitService:   limitService,
+		accountService: accountService,
+	}
+}
+
+func (h *limitHandler) GetAccountLimits(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+
+	account, err := h.accountService.GetAccountByID(c.Request.Context(), accountID)
+	if errors.Is(err, client.ErrAccountNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Account lookup failed"})
+		return
+	}
+	accountType, _ := account["AccountType"].(string)
+	customerID, _ := account["CustomerID"].(string)
+	if !server.CheckCustomerAccess(c, customerID) || !server.CheckAccountAllowed(c, accountID.String()) {
+		return
+	}
+
+	status, err := h.limitService.GetLimitStatus(c.Request.Context(), accountID.String(), accountType, customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *limitHandler) SetAccountLimit(c *gin.Context) {
+	h.setLimit(c, model.LimitScopeAccount)
+}
+
+func (h *limitHandler) DeleteAccountLimit(c *gin.Context) {
+	h.deleteLimit(c, model.LimitScopeAccount)
+}
+
+func (h *limitHandler) SetCustomerLimit(c *gin.Context) {
+	h.setLimit(c, model.LimitScopeCustomer)
+}
+
+func (h *limitHandler) DeleteCustomerLimit(c *gin.Context) {
+	h.deleteLimit(c, model.LimitScopeCustomer)
+}
+
+func (h *limitHandler) SetAccountTypeLimit(c *gin.Context) {
+	h.setLimit(c, model.LimitScopeAccountType)
+}
+
+func (h *limitHandler) setLimit(c *gin.Context, scope string) {
+	var limit model.TransactionLimit
+	if err := c.ShouldBindJSON(&limit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit.Scope = scope
+	limit.ScopeID = c.Param("id")
+	if err := limit.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.limitService.SetLimit(c.Request.Context(), &limit); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, limit)
+}
+
+func (h *limitHandler) deleteLimit(c *gin.Context, scope string) {
+	if err := h.limitService.DeleteLimit(c.Request.Context(), scope, c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
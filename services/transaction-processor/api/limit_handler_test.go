@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"transaction-processor/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLimitService struct {
+	mock.Mock
+}
+
+func (m *MockLimitService) Check(ctx context.Context, transaction model.Transaction) error {
+	args := m.Called(ctx, transaction)
+	return args.Error(0)
+}
+
+func (m *MockLimitService) Release(ctx context.Context, transactionID string) error {
+	args := m.Called(ctx, transactionID)
+	return args.Error(0)
+}
+
+func (m *MockLimitService) GetLimitStatus(ctx context.Context, accountID string, accountType string, customerID string) (*model.LimitStatus, error) {
+	args := m.Called(ctx, accountID, accountType, customerID)
+	status, _ := args.Get(0).(*model.LimitStatus)
+	return status, args.Error(1)
+}
+
+func (m *MockLimitService) SetLimit(ctx context.Context, limit *model.TransactionLimit) error {
+	args := m.Called(ctx, limit)
+	return args.Error(0)
+}
+
+func (m *MockLimitService) DeleteLimit(ctx context.Context, scope string, scopeID string) error {
+	args := m.Called(ctx, scope, scopeID)
+	return args.Error(0)
+}
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error) {
+	args := m.Called(ctx, accountID)
+	account, _ := args.Get(0).(map[string]any)
+	return account, args.Error(1)
+}
+
+func TestGetAccountLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLimitService := new(MockLimitService)
+	mockAccountService := new(MockAccountService)
+	handler := NewLimitHandler(mockLimitService, mockAccountService)
+
+	router := gin.Default()
+	router.GET("/limits/accounts/:id", handler.GetAccountLimits)
+
+	t.Run("should return 400 for invalid account id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/limits/accounts/invalid", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("should return 404 if account is not found", func(t *testing.T) {
+		accountID := uuid.New()
+		mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(nil, client.ErrAccountNotFound)
+
+		req, _ := http.NewRequest(http.MethodGet, "/limits/accounts/"+accountID.String(), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("should return 502 if the account lookup fails", func(t *testing.T) {
+		accountID := uuid.New()
+		mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(nil, errors.New("connection refused"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/limits/accounts/"+accountID.String(), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadGateway, resp.Code)
+	})
+
+	t.Run("should return remaining limits", func(t *testing.T) {
+		accountID := uuid.New()
+		mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(map[string]any{"AccountType": "savings", "CustomerID": "cust-1"}, nil)
+		mockLimitService.On("GetLimitStatus", mock.Anything, accountID.String(), "savings", "cust-1").Return(&model.LimitStatus{AccountID: accountID.String()}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/limits/accounts/"+accountID.String(), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockLimitService.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 for another customer's account", func(t *testing.T) {
+		customerRouter := gin.Default()
+		customerRouter.Use(func(c *gin.Context) {
+			server.SetClaims(c, &server.Claims{Roles: []string{server.RoleCustomer}, CustomerID: "cust-2"})
+		})
+		customerRouter.GET("/limits/accounts/:id", handler.GetAccountLimits)
+
+		accountID := uuid.New()
+		mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(map[string]any{"AccountType": "savings", "CustomerID": "cust-1"}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/limits/accounts/"+accountID.String(), nil)
+		resp := httptest.NewRecorder()
+		customerRouter.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+}
+
+func TestSetCustomerLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLimitService := new(MockLimitService)
+	handler := NewLimitHandler(mockLimitService, new(MockAccountService))
+
+	router := gin.Default()
+	router.PUT("/limits/customers/:id", handler.SetCustomerLimit)
+
+	mockLimitService.On("SetLimit", mock.Anything, mock.MatchedBy(func(limit *model.TransactionLimit) bool {
+		return limit.Scope == model.LimitScopeCustomer && limit.ScopeID == "cust-1" && *limit.DailyDebitMax == 250
+	})).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPut, "/limits/customers/cust-1", bytes.NewBufferString(`{"dailyDebitMax": 250}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	mockLimitService.AssertExpectations(t)
+}
+
+func TestSetCustomerLimit_RejectsNonPositiveLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockLimitService := new(MockLimitService)
+	handler := NewLimitHandler(mockLimitService, new(MockAccountService))
+
+	router := gin.Default()
+	router.PUT("/limits/customers/:id", handler.SetCustomerLimit)
+
+	for _, body := range []string{`{"dailyDebitMax": 0}`, `{"singleTransactionMax": -5}`, `{"hourlyCountMax": 0}`} {
+		req, _ := http.NewRequest(http.MethodPut, "/limits/customers/cust-1", bytes.NewBufferString(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+	mockLimitService.AssertNotCalled(t, "SetLimit", mock.Anything, mock.Anything)
+}
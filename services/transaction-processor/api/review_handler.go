@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"transaction-processor/model"
+	"transaction-processor/repository"
+	"transaction-processor/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
+)
+
+type reviewHandler struct {
+	service service.ReviewService
+}
+
+type ReviewHandler interface {
+	ListReviews(c *gin.Context)
+	GetReview(c *gin.Context)
+	ApproveReview(c *gin.Context)
+	RejectReview(c *gin.Context)
+}
+
+type reviewDecision struct {
+	Note string `json:"note"`
+}
+
+func NewReviewHandler(service service.ReviewService) ReviewHandler {
+	return &reviewHandler{service: service}
+}
+
+func (h *reviewHandler) ListReviews(c *gin.Context) {
+	reviews, err := h.service.ListReviews(c.Request.Context(), c.DefaultQuery("status", "pending"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+func (h *reviewHandler) GetReview(c *gin.Context) {
+	review, err := h.service.GetReview(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *reviewHandler) ApproveReview(c *gin.Context) {
+	h.decide(c, h.service.Approve)
+}
+
+func (h *reviewHandler) RejectReview(c *gin.Context) {
+	h.decide(c, h.service.Reject)
+}
+
+func (h *reviewHandler) decide(c *gin.Context, decide func(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error)) {
+	var decision reviewDecision
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&decision); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	reviewer, _, _ := c.Request.BasicAuth()
+	if claims, ok := server.ClaimsFromContext(c); ok {
+		reviewer = claims.Principal()
+	}
+
+	review, err := decide(c.Request.Context(), c.Param("id"), reviewer, decision.Note)
+	if errors.Is(err, repository.ErrReviewNotPending) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
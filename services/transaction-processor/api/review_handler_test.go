@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"transaction-processor/model"
+	"transaction-processor/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockReviewService struct {
+	mock.Mock
+}
+
+func (m *MockReviewService) ListReviews(ctx context.Context, status string) ([]model.RiskReview, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]model.RiskReview), args.Error(1)
+}
+
+func (m *MockReviewService) GetReview(ctx context.Context, transactionID string) (*model.RiskReview, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).(*model.RiskReview), args.Error(1)
+}
+
+func (m *MockReviewService) Approve(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error) {
+	args := m.Called(ctx, transactionID, reviewer, note)
+	return args.Get(0).(*model.RiskReview), args.Error(1)
+}
+
+func (m *MockReviewService) Reject(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error) {
+	args := m.Called(ctx, transactionID, reviewer, note)
+	return args.Get(0).(*model.RiskReview), args.Error(1)
+}
+
+func TestListReviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockReviewService)
+	handler := NewReviewHandler(mockService)
+
+	router := gin.Default()
+	router.GET("/reviews", handler.ListReviews)
+
+	mockService.On("ListReviews", mock.Anything, "pending").Return([]model.RiskReview{{TransactionID: "t1"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/reviews", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetReview_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockReviewService)
+	handler := NewReviewHandler(mockService)
+
+	router := gin.Default()
+	router.GET("/reviews/:id", handler.GetReview)
+
+	mockService.On("GetReview", mock.Anything, "t1").Return(&model.RiskReview{}, gorm.ErrRecordNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/reviews/t1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestApproveReview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockReviewService)
+	handler := NewReviewHandler(mockService)
+
+	router := gin.Default()
+	router.POST("/reviews/:id/approve", handler.ApproveReview)
+
+	t.Run("should approve a pending review", func(t *testing.T) {
+		mockService.On("Approve", mock.Anything, "t1", "ops", "looks fine").Return(&model.RiskReview{TransactionID: "t1", Status: model.ReviewApproved}, nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/reviews/t1/approve", bytes.NewBufferString(`{"note":"looks fine"}`))
+		req.SetBasicAuth("ops", "secret")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("should return 409 if the review was already decided", func(t *testing.T) {
+		mockService.On("Approve", mock.Anything, "t2", "", "").Return(&model.RiskReview{}, repository.ErrReviewNotPending)
+
+		req, _ := http.NewRequest(http.MethodPost, "/reviews/t2/approve", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+}
@@ -3,25 +3,50 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
+	"github.com/shrishyam02/banking-ledger/common/apikey"
+	"github.com/shrishyam02/banking-ledger/common/app"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/auth"
 	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/db"
 	"github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/shrishyam02/banking-ledger/common/logger"
 	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
 
+	"transaction-processor/api"
 	"transaction-processor/processor"
+	"transaction-processor/repository"
+	"transaction-processor/risk"
+	"transaction-processor/service"
 )
 
 func main() {
-	logger.InitLogger()
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	logger.InitLogger(logger.Options{
+		Service: config.ProcessorService,
+		Version: cfg.Logging.Version,
+		Level:   cfg.Services[config.ProcessorService].LogLevel,
+		Format:  cfg.Logging.Format,
+	})
 	logger.Log.Info().Msg("Intialized logger for service: " + config.ProcessorService)
 
+	shutdownTracing, err := tracing.Init(context.Background(), config.ProcessorService, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	lifecycle := app.NewLifecycle(config.ProcessorService, cfg.Lifecycle.ShutdownTimeout)
+	lifecycle.OnStop("tracing", shutdownTracing)
+
 	// Create Kafka topic
 	brokers := strings.Split(cfg.Kafka.Brokers, ",")
 	consumerTopics := map[string]string{
@@ -55,31 +80,99 @@ func main() {
 	for _, topic := range consumerTopics {
 		consumerTopicList = append(consumerTopicList, topic)
 	}
-	consumer := kafka.NewKafkaConsumer(brokers, consumerGroup, consumerTopicList)
+	health := server.NewHealth()
+	consumer := kafka.NewKafkaConsumer(brokers, consumerGroup, consumerTopicList,
+		kafka.WithHeartbeat(health.Heartbeat("transactions-consumer", time.Minute)))
 	producer := kafka.NewKafkaProducer(brokers)
+	lifecycle.OnStop("kafka consumer", func(context.Context) error { return consumer.Close() })
+	lifecycle.OnStop("kafka producer", func(context.Context) error { return producer.Close() })
 
-	transactionProcessor := processor.NewTransactionProcessor(consumer, producer, consumerTopics, producerTopics, consumerGroup, 5) // 5 workers
+	pgDb, err := db.ConnectPostgres(cfg.Database.PostgresConnectionString)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	logger.Log.Info().Msg("Connected to postgres: " + config.ProcessorService)
+	if err := db.CheckSchemaVersion(context.Background(), pgDb); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Database schema is older than this build; run `account migrate up`")
+	}
+	lifecycle.OnStop("postgres", func(context.Context) error { return db.ClosePostgres(pgDb) })
+	health.AddReadinessCheck("postgres", db.PostgresHealthCheck(pgDb))
+	health.AddReadinessCheck("kafka", kafka.BrokerHealthCheck(brokers))
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	accountServiceURL := os.Getenv("ACCOUNT_SERVICE_URL")
+	if accountServiceURL == "" {
+		log.Fatal("ACCOUNT_SERVICE_URL environment variable is required")
+	}
+	accountService := service.NewAccountService(accountServiceURL, auth.NewClient(context.Background(), cfg.Auth, cfg.ApiAuth))
+	limitService := service.NewLimitService(repository.NewLimitRepository(pgDb))
+	limitHandler := api.NewLimitHandler(limitService, accountService)
 
-	go func() {
-		if err := transactionProcessor.ProcessTransactions(ctx); err != nil {
-			log.Fatalf("Failed to process transactions: %v", err)
-		}
-	}()
+	riskConfig, err := risk.LoadConfig(os.Getenv("RISK_RULES_FILE"))
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to load risk rules")
+	}
+	riskRepo := repository.NewRiskRepository(pgDb)
+	riskEngine, err := risk.NewEngineFromConfig(riskConfig, riskRepo)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Invalid risk rules")
+	}
+	riskService := service.NewRiskService(riskEngine, riskRepo)
+
+	transactionProcessor := processor.NewTransactionProcessor(consumer, producer, consumerTopics, producerTopics, consumerGroup, 5, limitService, riskService) // 5 workers
+	reviewHandler := api.NewReviewHandler(service.NewReviewService(riskRepo, transactionProcessor))
 
-	go func() {
-		if err := transactionProcessor.ProcessTransactionStatus(ctx); err != nil {
-			log.Fatalf("Failed to process transaction status: %v", err)
+	lifecycle.Go("transactions consumer", transactionProcessor.ProcessTransactions)
+	lifecycle.Go("transaction status consumer", transactionProcessor.ProcessTransactionStatus)
+
+	registerHandlers := func(apiGroup *gin.RouterGroup) {
+		limits := apiGroup.Group("/limits")
+		{
+			limits.GET("/accounts/:id", limitHandler.GetAccountLimits)
+			limits.PUT("/accounts/:id", limitHandler.SetAccountLimit)
+			limits.DELETE("/accounts/:id", limitHandler.DeleteAccountLimit)
+			limits.PUT("/customers/:id", limitHandler.SetCustomerLimit)
+			limits.DELETE("/customers/:id", limitHandler.DeleteCustomerLimit)
+			limits.PUT("/account-types/:id", limitHandler.SetAccountTypeLimit)
+		}
+		reviews := apiGroup.Group("/reviews")
+		{
+			reviews.GET("", reviewHandler.ListReviews)
+			reviews.GET("/:id", reviewHandler.GetReview)
+			reviews.POST("/:id/approve", reviewHandler.ApproveReview)
+			reviews.POST("/:id/reject", reviewHandler.RejectReview)
 		}
-	}()
+	}
+	logger.Log.Info().Msg("Handlers for: " + config.ProcessorService)
 
 	serverConfig := server.Config{
-		Port:        cfg.Services[config.ProcessorService].Port,
-		ServiceName: config.ProcessorService,
-		ApiAuth:     cfg.ApiAuth,
+		Port:            cfg.Services[config.ProcessorService].Port,
+		ServiceName:     config.ProcessorService,
+		ApiAuth:         cfg.ApiAuth,
+		Auth:            cfg.Auth,
+		RateLimit:       cfg.RateLimit,
+		Health:          health,
+		ShutdownTimeout: cfg.Lifecycle.ShutdownTimeout,
+		Permissions: server.RoutePermissions{
+			"GET /api/v1/limits/accounts/:id":      server.PermLimitsRead,
+			"PUT /api/v1/limits/accounts/:id":      server.PermLimitsWrite,
+			"DELETE /api/v1/limits/accounts/:id":   server.PermLimitsWrite,
+			"PUT /api/v1/limits/customers/:id":     server.PermLimitsWrite,
+			"DELETE /api/v1/limits/customers/:id":  server.PermLimitsWrite,
+			"PUT /api/v1/limits/account-types/:id": server.PermLimitsWrite,
+			"GET /api/v1/reviews":                  server.PermReviewsRead,
+			"GET /api/v1/reviews/:id":              server.PermReviewsRead,
+			"POST /api/v1/reviews/:id/approve":     server.PermReviewsDecide,
+			"POST /api/v1/reviews/:id/reject":      server.PermReviewsDecide,
+		},
+		APIKeys: apikey.NewService(apikey.NewRepository(pgDb)),
+		Audit:   audit.NewLog(pgDb, config.ProcessorService),
 	}
 
-	server.RunServer(ctx, serverConfig, nil)
+	lifecycle.Go("http server", func(ctx context.Context) error {
+		server.RunServer(ctx, serverConfig, registerHandlers)
+		return nil
+	})
+	if err := lifecycle.Wait(); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Unclean shutdown")
+	}
 }
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limit scopes, from least to most specific.
+const (
+	LimitScopeAccountType = "account_type"
+	LimitScopeCustomer    = "customer"
+	LimitScopeAccount     = "account"
+)
+
+// Usage periods tracked per account.
+const (
+	PeriodHour  = "hour"
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Rejection reasons reported on transactions that break a limit.
+const (
+	ReasonSingleTransactionLimit = "single_transaction_limit_exceeded"
+	ReasonDailyDebitLimit        = "daily_debit_limit_exceeded"
+	ReasonMonthlyDebitLimit      = "monthly_debit_limit_exceeded"
+	ReasonHourlyCountLimit       = "hourly_transaction_count_exceeded"
+	ReasonLimitCheckUnavailable  = "limit_check_unavailable"
+)
+
+// TransactionLimit is a set of limits for one scope. Nil fields are not limited
+// at this scope and fall back to the less specific scope.
+type TransactionLimit struct {
+	Scope                string    `json:"scope" gorm:"type:varchar(20);primaryKey"`
+	ScopeID              string    `json:"scopeId" gorm:"type:varchar(64);primaryKey"`
+	SingleTransactionMax *float64  `json:"singleTransactionMax" gorm:"type:decimal(19,4)"`
+	DailyDebitMax        *float64  `json:"dailyDebitMax" gorm:"type:decimal(19,4)"`
+	MonthlyDebitMax      *float64  `json:"monthlyDebitMax" gorm:"type:decimal(19,4)"`
+	HourlyCountMax       *int64    `json:"hourlyCountMax" gorm:"type:bigint"`
+	UpdatedAt            time.Time `json:"updatedAt" gorm:"type:timestamp with time zone"`
+}
+
+// Validate checks that every limit set is positive; a zero limit would block the scope outright.
+func (l *TransactionLimit) Validate() error {
+	amounts := []struct {
+		name  string
+		value *float64
+	}{
+		{"singleTransactionMax", l.SingleTransactionMax},
+		{"dailyDebitMax", l.DailyDebitMax},
+		{"monthlyDebitMax", l.MonthlyDebitMax},
+	}
+	for _, amount := range amounts {
+		if amount.value != nil && *amount.value <= 0 {
+			return fmt.Errorf("%s must be positive", amount.name)
+		}
+	}
+	if l.HourlyCountMax != nil && *l.HourlyCountMax <= 0 {
+		return fmt.Errorf("hourlyCountMax must be positive")
+	}
+	return nil
+}
+
+// Merge overlays the limits set on override.
+func (l TransactionLimit) Merge(override TransactionLimit) TransactionLimit {
+	if override.SingleTransactionMax != nil {
+		l.SingleTransactionMax = override.SingleTransactionMax
+	}
+	if override.DailyDebitMax != nil {
+		l.DailyDebitMax = override.DailyDebitMax
+	}
+	if override.MonthlyDebitMax != nil {
+		l.MonthlyDebitMax = override.MonthlyDebitMax
+	}
+	if override.HourlyCountMax != nil {
+		l.HourlyCountMax = override.HourlyCountMax
+	}
+	return l
+}
+
+// LimitUsage is the running debit total and transaction count of an account for one period.
+type LimitUsage struct {
+	AccountID        string    `gorm:"type:varchar(64);primaryKey"`
+	Period           string    `gorm:"type:varchar(10);primaryKey"`
+	PeriodStart      time.Time `gorm:"type:timestamp with time zone;primaryKey"`
+	DebitTotal       float64   `gorm:"type:decimal(19,4);not null;default:0"`
+	TransactionCount int64     `gorm:"type:bigint;not null;default:0"`
+}
+
+func (LimitUsage) TableName() string {
+	return "limit_usage"
+}
+
+// LimitReservation records that a transaction has been counted against its account's usage,
+// so redelivered messages are not counted twice and failed ones can be released.
+type LimitReservation struct {
+	TransactionID   string    `gorm:"type:varchar(64);primaryKey"`
+	AccountID       string    `gorm:"type:varchar(64);not null"`
+	Amount          float64   `gorm:"type:decimal(19,4);not null"`
+	TransactionType string    `gorm:"type:varchar(20);not null"`
+	AcceptedAt      time.Time `gorm:"type:timestamp with time zone;not null"`
+	CreatedAt       time.Time `gorm:"type:timestamp with time zone"`
+}
+
+// PeriodStart returns the start of the usage period containing t.
+func PeriodStart(period string, t time.Time) time.Time {
+	t = t.UTC()
+	switch period {
+	case PeriodHour:
+		return t.Truncate(time.Hour)
+	case PeriodDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// LimitStatus reports the effective limits of an account and what is left of them.
+type LimitStatus struct {
+	AccountID             string           `json:"accountId"`
+	Limits                TransactionLimit `json:"limits"`
+	DailyDebitRemaining   *float64         `json:"dailyDebitRemaining"`
+	MonthlyDebitRemaining *float64         `json:"monthlyDebitRemaining"`
+	HourlyCountRemaining  *int64           `json:"hourlyCountRemaining"`
+}
+
+// LimitExceededError is returned when a transaction would break a limit.
+type LimitExceededError struct {
+	Reason string
+	Limit  float64
+	Value  float64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: limit %.2f, attempted %.2f", e.Reason, e.Limit, e.Value)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Review statuses.
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Rejection reasons reported on transactions refused by the risk engine.
+const (
+	ReasonRiskDenied           = "risk_denied"
+	ReasonRiskReviewRejected   = "risk_review_rejected"
+	ReasonRiskCheckUnavailable = "risk_check_unavailable"
+)
+
+// RiskReview is a transaction parked by the risk engine until it is approved or rejected.
+// AppliedAt is set once the decision has been forwarded.
+type RiskReview struct {
+	TransactionID string          `json:"transactionId" gorm:"type:varchar(64);primaryKey"`
+	AccountID     string          `json:"accountId" gorm:"type:varchar(64);not null;index"`
+	Amount        float64         `json:"amount" gorm:"type:decimal(19,4);not null"`
+	MessageKey    []byte          `json:"-" gorm:"type:bytea"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb;not null"`
+	Reasons       json.RawMessage `json:"reasons" gorm:"type:jsonb;not null"`
+	Status        string          `json:"status" gorm:"type:varchar(20);not null;index"`
+	DecidedBy     string          `json:"decidedBy,omitempty" gorm:"type:varchar(255)"`
+	DecisionNote  string          `json:"decisionNote,omitempty" gorm:"type:text"`
+	DecidedAt     *time.Time      `json:"decidedAt,omitempty" gorm:"type:timestamp with time zone"`
+	AppliedAt     *time.Time      `json:"appliedAt,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt     time.Time       `json:"createdAt" gorm:"type:timestamp with time zone"`
+}
+
+// RiskActivity is a transaction seen by the risk engine, kept as account history for later rules.
+type RiskActivity struct {
+	TransactionID   string    `gorm:"type:varchar(64);primaryKey"`
+	AccountID       string    `gorm:"type:varchar(64);not null"`
+	Amount          float64   `gorm:"type:decimal(19,4);not null"`
+	TransactionType string    `gorm:"type:varchar(20);not null"`
+	Outcome         string    `gorm:"type:varchar(10);not null"`
+	AcceptedAt      time.Time `gorm:"type:timestamp with time zone;not null"`
+}
+
+func (RiskActivity) TableName() string {
+	return "risk_activity"
+}
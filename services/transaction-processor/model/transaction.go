@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Transaction is the typed view of a transaction message used for rule evaluation.
+// The raw message is still forwarded as-is so fields unknown here are preserved.
+type Transaction struct {
+	ID               string    `json:"id"`
+	AccountID        string    `json:"accountId"`
+	AccountType      string    `json:"accountType"`
+	CustomerID       string    `json:"customerId"`
+	AccountCreatedAt time.Time `json:"accountCreatedAt"`
+	Counterparty     string    `json:"counterparty"`
+	Amount           float64   `json:"amount"`
+	TransactionType  string    `json:"transactionType"`
+	AcceptedAt       time.Time `json:"acceptedAt"`
+}
@@ -3,13 +3,18 @@ package processor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"sync"
 	"time"
 
+	"transaction-processor/model"
+	"transaction-processor/risk"
+	"transaction-processor/service"
+
 	"github.com/segmentio/kafka-go"
 	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
 )
 
 type TransactionProcessor struct {
@@ -19,9 +24,11 @@ type TransactionProcessor struct {
 	producerTopics map[string]string
 	consumerGroup  string
 	workerPoolSize int
+	limitService   service.LimitService
+	riskService    service.RiskService
 }
 
-func NewTransactionProcessor(consumer ckafka.KafkaConsumer, producer ckafka.KafkaProducer, consumerTopics map[string]string, producerTopics map[string]string, consumerGroup string, workerPoolSize int) *TransactionProcessor {
+func NewTransactionProcessor(consumer ckafka.KafkaConsumer, producer ckafka.KafkaProducer, consumerTopics map[string]string, producerTopics map[string]string, consumerGroup string, workerPoolSize int, limitService service.LimitService, riskService service.RiskService) *TransactionProcessor {
 	return &TransactionProcessor{
 		consumer:       consumer,
 		producer:       producer,
@@ -29,112 +36,141 @@ func NewTransactionProcessor(consumer ckafka.KafkaConsumer, producer ckafka.Kafk
 		producerTopics: producerTopics,
 		consumerGroup:  consumerGroup,
 		workerPoolSize: workerPoolSize,
+		limitService:   limitService,
+		riskService:    riskService,
 	}
 }
 
 func (tp *TransactionProcessor) ProcessTransactions(ctx context.Context) error {
-	msgChan := make(chan kafka.Message)
-	var wg sync.WaitGroup
-
-	// Start worker pool
-	for i := 0; i < tp.workerPoolSize; i++ {
-		wg.Add(1)
-		go tp.worker(ctx, msgChan, &wg)
-	}
-
-	// Consume messages and send them to the worker pool
-	go func() {
-		defer close(msgChan)
-		if err := tp.consumer.Consume(ctx, tp.consumerTopics["transactions"], tp.consumerGroup, func(msg kafka.Message) error {
-			msgChan <- msg
-			return nil
-		}); err != nil {
-			log.Fatalf("Failed to consume messages: %v", err)
+	workers := ckafka.Workers{Size: tp.workerPoolSize, Pool: metrics.NewWorkerPool("transactions")}
+	return tp.consumer.ConsumeConcurrently(ctx, tp.consumerTopics["transactions"], tp.consumerGroup, workers, func(msg kafka.Message) error {
+		// Shutdown must not cancel a message halfway through
+		msgCtx, span := ckafka.StartProcessSpan(context.WithoutCancel(ctx), msg, tp.consumerGroup)
+		defer span.End()
+		if err := tp.handleMessage(msgCtx, msg); err != nil {
+			span.RecordError(err)
+			logger.Ctx(msgCtx).Error().Err(err).Msg("Failed to handle message")
 		}
-	}()
-
-	wg.Wait()
-	return nil
+		return nil
+	})
 }
 
 func (tp *TransactionProcessor) ProcessTransactionStatus(ctx context.Context) error {
-	msgChan := make(chan kafka.Message)
-	var wg sync.WaitGroup
-
-	// Start worker pool
-	for i := 0; i < tp.workerPoolSize; i++ {
-		wg.Add(1)
-		go tp.statusWorker(ctx, msgChan, &wg)
-	}
-
-	// Consume messages and send them to the worker pool
-	go func() {
-		defer close(msgChan)
-		if err := tp.consumer.Consume(ctx, tp.consumerTopics["transactions-status"], tp.consumerGroup, func(msg kafka.Message) error {
-			msgChan <- msg
-			return nil
-		}); err != nil {
-			log.Fatalf("Failed to consume messages: %v", err)
+	workers := ckafka.Workers{Size: tp.workerPoolSize, Pool: metrics.NewWorkerPool("transactions-status")}
+	return tp.consumer.ConsumeConcurrently(ctx, tp.consumerTopics["transactions-status"], tp.consumerGroup, workers, func(msg kafka.Message) error {
+		// Shutdown must not cancel a message halfway through
+		msgCtx, span := ckafka.StartProcessSpan(context.WithoutCancel(ctx), msg, tp.consumerGroup)
+		defer span.End()
+		if err := tp.handleStatusMessage(msgCtx, msg); err != nil {
+			span.RecordError(err)
+			logger.Ctx(msgCtx).Error().Err(err).Msg("Failed to handle status message")
 		}
-	}()
-
-	wg.Wait()
-	return nil
+		return nil
+	})
 }
 
-func (tp *TransactionProcessor) worker(ctx context.Context, msgChan <-chan kafka.Message, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for msg := range msgChan {
-		if err := tp.handleMessage(ctx, msg); err != nil {
-			log.Printf("Failed to handle message: %v", err)
-		}
+func (tp *TransactionProcessor) handleMessage(ctx context.Context, msg kafka.Message) error {
+	var transaction map[string]interface{}
+	if err := json.Unmarshal(msg.Value, &transaction); err != nil {
+		return err
 	}
-}
 
-func (tp *TransactionProcessor) statusWorker(ctx context.Context, msgChan <-chan kafka.Message, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for msg := range msgChan {
-		if err := tp.handleStatusMessage(ctx, msg); err != nil {
-			log.Printf("Failed to handle status message: %v", err)
+	// Validate the transaction
+	if err := tp.validateTransaction(transaction); err != nil {
+		return tp.failTransaction(ctx, msg.Key, transaction, err, "")
+	}
+
+	// Run the transaction through the risk rules
+	if tp.riskService != nil {
+		var typed model.Transaction
+		if err := json.Unmarshal(msg.Value, &typed); err != nil {
+			return err
+		}
+
+		result, err := tp.riskService.Assess(ctx, msg.Key, msg.Value, typed)
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Msgf("Failed to assess risk for transaction %s", typed.ID)
+			return tp.failTransaction(ctx, msg.Key, transaction, fmt.Errorf("risk check unavailable"), model.ReasonRiskCheckUnavailable)
+		}
+
+		switch result.Outcome {
+		case risk.Deny:
+			transaction["riskFindings"] = result.Findings
+			return tp.failTransaction(ctx, msg.Key, transaction, fmt.Errorf("transaction denied by risk rules"), model.ReasonRiskDenied)
+		case risk.Review:
+			// Parked until approved or rejected through the reviews API
+			transaction["status"] = "pending_review"
+			transaction["riskFindings"] = result.Findings
+			return tp.publishTransactionStatus(ctx, msg.Key, transaction)
 		}
 	}
+
+	return tp.Forward(ctx, msg.Key, msg.Value)
 }
 
-func (tp *TransactionProcessor) handleMessage(ctx context.Context, msg kafka.Message) error {
+// Forward enforces transaction limits and publishes the transaction to account-service
+// for balance update.
+func (tp *TransactionProcessor) Forward(ctx context.Context, key []byte, value []byte) error {
 	var transaction map[string]interface{}
-	if err := json.Unmarshal(msg.Value, &transaction); err != nil {
+	if err := json.Unmarshal(value, &transaction); err != nil {
 		return err
 	}
 
-	// Validate the transaction
-	if err := tp.validateTransaction(transaction); err != nil {
-		transaction["processedAt"] = time.Now().UTC()
-		transaction["status"] = "failed"
-		transaction["error"] = err.Error()
-		return tp.publishTransactionStatus(ctx, msg.Key, transaction)
+	// Enforce transaction limits and velocity rules
+	if reason, err := tp.checkLimits(ctx, value); err != nil {
+		return tp.failTransaction(ctx, key, transaction, err, reason)
 	}
 
 	// Publish the transaction to account-service for balance update
 	accountMessage := kafka.Message{
-		Key:   msg.Key,
-		Value: msg.Value,
+		Key:   key,
+		Value: value,
 	}
 	if err := tp.producer.Produce(ctx, tp.producerTopics["account-balance-updates"], accountMessage); err != nil {
-		transaction["processedAt"] = time.Now().UTC()
-		transaction["status"] = "failed"
-		transaction["error"] = err.Error()
-		return tp.publishTransactionStatus(ctx, msg.Key, transaction)
+		// The limits reserved the amount for a balance update that will not happen
+		if id, ok := transaction["id"].(string); ok {
+			tp.releaseLimits(ctx, id)
+		}
+		return tp.failTransaction(ctx, key, transaction, err, "")
 	}
 
 	return nil
 }
 
+// Reject fails a transaction that was held back, e.g. after a negative risk review.
+func (tp *TransactionProcessor) Reject(ctx context.Context, key []byte, value []byte, reason string) error {
+	var transaction map[string]interface{}
+	if err := json.Unmarshal(value, &transaction); err != nil {
+		return err
+	}
+	return tp.failTransaction(ctx, key, transaction, fmt.Errorf("transaction rejected: %s", reason), reason)
+}
+
+// failTransaction publishes the transaction as failed; reason is the machine readable
+// rejection reason, if any.
+func (tp *TransactionProcessor) failTransaction(ctx context.Context, key []byte, transaction map[string]interface{}, err error, reason string) error {
+	transaction["processedAt"] = time.Now().UTC()
+	transaction["status"] = "failed"
+	transaction["error"] = err.Error()
+	if reason != "" {
+		transaction["rejectionReason"] = reason
+	}
+	return tp.publishTransactionStatus(ctx, key, transaction)
+}
+
 func (tp *TransactionProcessor) handleStatusMessage(ctx context.Context, msg kafka.Message) error {
 	var transactionStatus map[string]interface{}
 	if err := json.Unmarshal(msg.Value, &transactionStatus); err != nil {
 		return err
 	}
 
+	// A failed balance update must not count against the account's limits
+	if status, _ := transactionStatus["status"].(string); status == "failed" {
+		if id, ok := transactionStatus["id"].(string); ok {
+			tp.releaseLimits(ctx, id)
+		}
+	}
+
 	if err := tp.publishTransactionStatus(ctx, msg.Key, transactionStatus); err != nil {
 		return err
 	}
@@ -142,6 +178,17 @@ func (tp *TransactionProcessor) handleStatusMessage(ctx context.Context, msg kaf
 	return nil
 }
 
+// releaseLimits reverses the usage reserved for the transaction. A failed release is logged; the
+// usage then counts until its window passes.
+func (tp *TransactionProcessor) releaseLimits(ctx context.Context, id string) {
+	if tp.limitService == nil {
+		return
+	}
+	if err := tp.limitService.Release(ctx, id); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msgf("Failed to release limits for transaction %s", id)
+	}
+}
+
 func (tp *TransactionProcessor) validateTransaction(transaction map[string]interface{}) error {
 	// TODO: Basic validation is added here. Additional validations need to be added.
 	amount, ok := transaction["amount"].(float64)
@@ -151,6 +198,31 @@ func (tp *TransactionProcessor) validateTransaction(transaction map[string]inter
 	return nil
 }
 
+// checkLimits returns the rejection reason along with the error when the transaction is refused.
+// Limits fail closed: if usage cannot be checked the transaction is rejected.
+func (tp *TransactionProcessor) checkLimits(ctx context.Context, value []byte) (string, error) {
+	if tp.limitService == nil {
+		return "", nil
+	}
+
+	var transaction model.Transaction
+	if err := json.Unmarshal(value, &transaction); err != nil {
+		return "", err
+	}
+
+	err := tp.limitService.Check(ctx, transaction)
+	if err == nil {
+		return "", nil
+	}
+
+	var limitErr *model.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return limitErr.Reason, err
+	}
+	logger.Ctx(ctx).Error().Err(err).Msgf("Failed to check limits for transaction %s", transaction.ID)
+	return model.ReasonLimitCheckUnavailable, fmt.Errorf("limit check unavailable")
+}
+
 func (tp *TransactionProcessor) publishTransactionStatus(ctx context.Context, key []byte, transaction map[string]interface{}) error {
 	transactionBytes, err := json.Marshal(transaction)
 	if err != nil {
@@ -161,5 +233,22 @@ func (tp *TransactionProcessor) publishTransactionStatus(ctx context.Context, ke
 		Key:   key,
 		Value: transactionBytes,
 	}
-	return tp.producer.Produce(ctx, tp.producerTopics["ledger"], ledgerMessage)
+	if err := tp.producer.Produce(ctx, tp.producerTopics["ledger"], ledgerMessage); err != nil {
+		return err
+	}
+	recordOutcome(transaction)
+	return nil
+}
+
+// recordOutcome counts the status published for the transaction.
+func recordOutcome(transaction map[string]interface{}) {
+	transactionType, _ := transaction["transactionType"].(string)
+	switch status, _ := transaction["status"].(string); status {
+	case "success":
+		metrics.RecordTransaction(metrics.TransactionSucceeded, transactionType)
+	case "failed":
+		metrics.RecordTransaction(metrics.TransactionFailed, transactionType)
+	case "pending_review":
+		metrics.RecordTransaction(metrics.TransactionPendingReview, transactionType)
+	}
 }
@@ -3,9 +3,16 @@ package processor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
+	"transaction-processor/model"
+	"transaction-processor/risk"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/segmentio/kafka-go"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,6 +26,15 @@ func (m *MockKafkaConsumer) Consume(ctx context.Context, topic, groupID string,
 	return args.Error(0)
 }
 
+func (m *MockKafkaConsumer) ConsumeConcurrently(ctx context.Context, topic, groupID string, workers ckafka.Workers, handler func(kafka.Message) error) error {
+	args := m.Called(ctx, topic, groupID, handler)
+	return args.Error(0)
+}
+
+func (m *MockKafkaConsumer) Close() error {
+	return nil
+}
+
 type MockKafkaProducer struct {
 	mock.Mock
 }
@@ -28,14 +44,61 @@ func (m *MockKafkaProducer) Produce(ctx context.Context, topic string, message k
 	return args.Error(0)
 }
 
+func (m *MockKafkaProducer) ProduceBatch(ctx context.Context, topic string, messages []kafka.Message) error {
+	args := m.Called(ctx, topic, messages)
+	return args.Error(0)
+}
+
+func (m *MockKafkaProducer) Close() error {
+	return nil
+}
+
+type MockLimitService struct {
+	mock.Mock
+}
+
+func (m *MockLimitService) Check(ctx context.Context, transaction model.Transaction) error {
+	args := m.Called(ctx, transaction)
+	return args.Error(0)
+}
+
+func (m *MockLimitService) Release(ctx context.Context, transactionID string) error {
+	args := m.Called(ctx, transactionID)
+	return args.Error(0)
+}
+
+func (m *MockLimitService) GetLimitStatus(ctx context.Context, accountID string, accountType string, customerID string) (*model.LimitStatus, error) {
+	args := m.Called(ctx, accountID, accountType, customerID)
+	return args.Get(0).(*model.LimitStatus), args.Error(1)
+}
+
+func (m *MockLimitService) SetLimit(ctx context.Context, limit *model.TransactionLimit) error {
+	args := m.Called(ctx, limit)
+	return args.Error(0)
+}
+
+func (m *MockLimitService) DeleteLimit(ctx context.Context, scope string, scopeID string) error {
+	args := m.Called(ctx, scope, scopeID)
+	return args.Error(0)
+}
+
+type MockRiskService struct {
+	mock.Mock
+}
+
+func (m *MockRiskService) Assess(ctx context.Context, key []byte, value []byte, transaction model.Transaction) (risk.Result, error) {
+	args := m.Called(ctx, key, value, transaction)
+	return args.Get(0).(risk.Result), args.Error(1)
+}
+
 func TestProcessTransactions(t *testing.T) {
 	mockConsumer := new(MockKafkaConsumer)
 	mockProducer := new(MockKafkaProducer)
 	consumerTopics := map[string]string{"transactions": "transactions-topic"}
 	producerTopics := map[string]string{"account-balance-updates": "account-balance-updates-topic", "ledger": "ledger-topic"}
-	processor := NewTransactionProcessor(mockConsumer, mockProducer, consumerTopics, producerTopics, "consumer-group", 1)
+	processor := NewTransactionProcessor(mockConsumer, mockProducer, consumerTopics, producerTopics, "consumer-group", 1, nil, nil)
 
-	mockConsumer.On("Consume", mock.Anything, "transactions-topic", "consumer-group", mock.Anything).Return(nil)
+	mockConsumer.On("ConsumeConcurrently", mock.Anything, "transactions-topic", "consumer-group", mock.Anything).Return(nil)
 
 	ctx := context.Background()
 	err := processor.ProcessTransactions(ctx)
@@ -48,9 +111,9 @@ func TestProcessTransactionStatus(t *testing.T) {
 	mockProducer := new(MockKafkaProducer)
 	consumerTopics := map[string]string{"transactions-status": "transactions-status-topic"}
 	producerTopics := map[string]string{"ledger": "ledger-topic"}
-	processor := NewTransactionProcessor(mockConsumer, mockProducer, consumerTopics, producerTopics, "consumer-group", 1)
+	processor := NewTransactionProcessor(mockConsumer, mockProducer, consumerTopics, producerTopics, "consumer-group", 1, nil, nil)
 
-	mockConsumer.On("Consume", mock.Anything, "transactions-status-topic", "consumer-group", mock.Anything).Return(nil)
+	mockConsumer.On("ConsumeConcurrently", mock.Anything, "transactions-status-topic", "consumer-group", mock.Anything).Return(nil)
 
 	ctx := context.Background()
 	err := processor.ProcessTransactionStatus(ctx)
@@ -170,3 +233,189 @@ func TestPublishTransactionStatus(t *testing.T) {
 	assert.NoError(t, err)
 	mockProducer.AssertExpectations(t)
 }
+
+func TestHandleMessage_LimitExceeded(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	mockLimitService := new(MockLimitService)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"account-balance-updates": "account-balance-updates-topic", "ledger": "ledger-topic"},
+		limitService:   mockLimitService,
+	}
+
+	msg := kafka.Message{
+		Key:   []byte("key"),
+		Value: []byte(`{"id":"t1","accountId":"acc-1","amount":5000.0,"transactionType":"debit"}`),
+	}
+
+	mockLimitService.On("Check", mock.Anything, mock.MatchedBy(func(transaction model.Transaction) bool {
+		return transaction.ID == "t1" && transaction.Amount == 5000
+	})).Return(&model.LimitExceededError{Reason: model.ReasonDailyDebitLimit, Limit: 1000, Value: 5000})
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction map[string]interface{}
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction["status"] == "failed" && transaction["rejectionReason"] == model.ReasonDailyDebitLimit
+	})).Return(nil)
+
+	err := processor.handleMessage(context.Background(), msg)
+	assert.NoError(t, err)
+	mockLimitService.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, "account-balance-updates-topic", mock.Anything)
+}
+
+func TestHandleMessage_LimitCheckUnavailable(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	mockLimitService := new(MockLimitService)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"account-balance-updates": "account-balance-updates-topic", "ledger": "ledger-topic"},
+		limitService:   mockLimitService,
+	}
+
+	msg := kafka.Message{Key: []byte("key"), Value: []byte(`{"id":"t1","amount":10.0}`)}
+
+	mockLimitService.On("Check", mock.Anything, mock.Anything).Return(errors.New("db error"))
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction map[string]interface{}
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction["rejectionReason"] == model.ReasonLimitCheckUnavailable
+	})).Return(nil)
+
+	err := processor.handleMessage(context.Background(), msg)
+	assert.NoError(t, err)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestHandleStatusMessage_ReleasesLimitsOnFailure(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	mockLimitService := new(MockLimitService)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"ledger": "ledger-topic"},
+		limitService:   mockLimitService,
+	}
+
+	msg := kafka.Message{Key: []byte("key"), Value: []byte(`{"id":"t1","status":"failed"}`)}
+
+	mockLimitService.On("Release", mock.Anything, "t1").Return(nil)
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.Anything).Return(nil)
+
+	err := processor.handleStatusMessage(context.Background(), msg)
+	assert.NoError(t, err)
+	mockLimitService.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestForward_ReleasesLimitsWhenBalanceUpdateIsNotPublished(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	mockLimitService := new(MockLimitService)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"account-balance-updates": "account-balance-updates-topic", "ledger": "ledger-topic"},
+		limitService:   mockLimitService,
+	}
+
+	msg := kafka.Message{Key: []byte("key"), Value: []byte(`{"id":"t1","accountId":"acc-1","amount":10.0}`)}
+
+	mockLimitService.On("Check", mock.Anything, mock.Anything).Return(nil)
+	mockLimitService.On("Release", mock.Anything, "t1").Return(nil)
+	mockProducer.On("Produce", mock.Anything, "account-balance-updates-topic", mock.Anything).Return(errors.New("broker unavailable"))
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction map[string]interface{}
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction["status"] == "failed"
+	})).Return(nil)
+
+	err := processor.Forward(context.Background(), msg.Key, msg.Value)
+	assert.NoError(t, err)
+	mockLimitService.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestHandleMessage_RiskDenied(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	mockRiskService := new(MockRiskService)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"account-balance-updates": "account-balance-updates-topic", "ledger": "ledger-topic"},
+		riskService:    mockRiskService,
+	}
+
+	msg := kafka.Message{Key: []byte("key"), Value: []byte(`{"id":"t1","amount":10.0,"counterparty":"BAD"}`)}
+
+	mockRiskService.On("Assess", mock.Anything, msg.Key, msg.Value, mock.Anything).Return(risk.Result{
+		Outcome:  risk.Deny,
+		Findings: []risk.Finding{{Rule: "blocklist", Outcome: risk.Deny}},
+	}, nil)
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction map[string]interface{}
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction["status"] == "failed" && transaction["rejectionReason"] == model.ReasonRiskDenied
+	})).Return(nil)
+
+	err := processor.handleMessage(context.Background(), msg)
+	assert.NoError(t, err)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestHandleMessage_RiskReview(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	mockRiskService := new(MockRiskService)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"account-balance-updates": "account-balance-updates-topic", "ledger": "ledger-topic"},
+		riskService:    mockRiskService,
+	}
+
+	msg := kafka.Message{Key: []byte("key"), Value: []byte(`{"id":"t1","amount":10.0}`)}
+
+	mockRiskService.On("Assess", mock.Anything, msg.Key, msg.Value, mock.Anything).Return(risk.Result{Outcome: risk.Review}, nil)
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction map[string]interface{}
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction["status"] == "pending_review"
+	})).Return(nil)
+
+	err := processor.handleMessage(context.Background(), msg)
+	assert.NoError(t, err)
+	mockProducer.AssertExpectations(t)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, "account-balance-updates-topic", mock.Anything)
+}
+
+func TestReject(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"ledger": "ledger-topic"},
+	}
+
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction map[string]interface{}
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction["status"] == "failed" && transaction["rejectionReason"] == model.ReasonRiskReviewRejected
+	})).Return(nil)
+
+	err := processor.Reject(context.Background(), []byte("key"), []byte(`{"id":"t1"}`), model.ReasonRiskReviewRejected)
+	assert.NoError(t, err)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestHandleStatusMessage_RecordsOutcome(t *testing.T) {
+	mockProducer := new(MockKafkaProducer)
+	processor := &TransactionProcessor{
+		producer:       mockProducer,
+		producerTopics: map[string]string{"ledger": "ledger-topic"},
+	}
+	mockProducer.On("Produce", mock.Anything, "ledger-topic", mock.Anything).Return(nil)
+
+	succeeded := metrics.TransactionsTotal.WithLabelValues(metrics.TransactionSucceeded, "credit")
+	before := testutil.ToFloat64(succeeded)
+
+	err := processor.handleStatusMessage(context.Background(), kafka.Message{
+		Key:   []byte("key"),
+		Value: []byte(`{"id":"t1","status":"success","transactionType":"credit"}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(succeeded))
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transaction-processor/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var usagePeriods = []string{model.PeriodHour, model.PeriodDay, model.PeriodMonth}
+
+type LimitRepository interface {
+	GetLimit(ctx context.Context, scope string, scopeID string) (*model.TransactionLimit, error)
+	UpsertLimit(ctx context.Context, limit *model.TransactionLimit) error
+	DeleteLimit(ctx context.Context, scope string, scopeID string) error
+	GetUsage(ctx context.Context, accountID string, at time.Time) (map[string]model.LimitUsage, error)
+	Reserve(ctx context.Context, reservation *model.LimitReservation, check func(usage map[string]model.LimitUsage) error) error
+	Release(ctx context.Context, transactionID string) error
+}
+
+type limitRepository struct {
+	db *gorm.DB
+}
+
+func NewLimitRepository(db *gorm.DB) LimitRepository {
+	return &limitRepository{db: db}
+}
+
+// GetLimit returns nil without error when no limit is configured for the scope.
+func (r *limitRepository) GetLimit(ctx context.Context, scope string, scopeID string) (*model.TransactionLimit, error) {
+	var limit model.TransactionLimit
+	err := r.db.WithContext(ctx).First(&limit, "scope = ? AND scope_id = ?", scope, scopeID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &limit, err
+}
+
+func (r *limitRepository) UpsertLimit(ctx context.Context, limit *model.TransactionLimit) error {
+	return r.db.WithContext(ctx).Save(limit).Error
+}
+
+func (r *limitRepository) DeleteLimit(ctx context.Context, scope string, scopeID string) error {
+	return r.db.WithContext(ctx).Delete(&model.TransactionLimit{}, "scope = ? AND scope_id = ?", scope, scopeID).Error
+}
+
+func (r *limitRepository) GetUsage(ctx context.Context, accountID string, at time.Time) (map[string]model.LimitUsage, error) {
+	usage := make(map[string]model.LimitUsage, len(usagePeriods))
+	for _, period := range usagePeriods {
+		row := model.LimitUsage{AccountID: accountID, Period: period, PeriodStart: model.PeriodStart(period, at)}
+		err := r.db.WithContext(ctx).Where(&row).First(&row).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		usage[period] = row
+	}
+	return usage, nil
+}
+
+// Reserve counts the transaction against its account's usage once. The usage rows are locked
+// while check runs, so concurrent transactions on the same account are evaluated one at a time.
+// A transaction that was already reserved is not counted or checked again.
+func (r *limitRepository) Reserve(ctx context.Context, reservation *model.LimitReservation, check func(usage map[string]model.LimitUsage) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reservation)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		usage, err := lockUsage(tx, reservation.AccountID, reservation.AcceptedAt)
+		if err != nil {
+			return err
+		}
+		if err := check(usage); err != nil {
+			return err
+		}
+
+		return applyUsage(tx, usage, reservation, 1)
+	})
+}
+
+// Release reverses a reservation, e.g. when the balance update of the transaction failed.
+func (r *limitRepository) Release(ctx context.Context, transactionID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reservation model.LimitReservation
+		result := tx.Clauses(clause.Returning{}).Where("transaction_id = ?", transactionID).Delete(&reservation)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		usage, err := lockUsage(tx, reservation.AccountID, reservation.AcceptedAt)
+		if err != nil {
+			return err
+		}
+		return applyUsage(tx, usage, &reservation, -1)
+	})
+}
+
+func lockUsage(tx *gorm.DB, accountID string, at time.Time) (map[string]model.LimitUsage, error) {
+	usage := make(map[string]model.LimitUsage, len(usagePeriods))
+	for _, period := range usagePeriods {
+		row := model.LimitUsage{AccountID: accountID, Period: period, PeriodStart: model.PeriodStart(period, at)}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&model.LimitUsage{
+			AccountID: row.AccountID, Period: row.Period, PeriodStart: row.PeriodStart,
+		}).First(&row).Error; err != nil {
+			return nil, err
+		}
+		usage[period] = row
+	}
+	return usage, nil
+}
+
+func applyUsage(tx *gorm.DB, usage map[string]model.LimitUsage, reservation *model.LimitReservation, sign int64) error {
+	debit := 0.0
+	if reservation.TransactionType == "debit" {
+		debit = reservation.Amount * float64(sign)
+	}
+	for _, row := range usage {
+		if err := tx.Model(&model.LimitUsage{}).
+			Where("account_id = ? AND period = ? AND period_start = ?", row.AccountID, row.Period, row.PeriodStart).
+			UpdateColumns(map[string]interface{}{
+				"debit_total":       gorm.Expr("debit_total + ?", debit),
+				"transaction_count": gorm.Expr("transaction_count + ?", sign),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
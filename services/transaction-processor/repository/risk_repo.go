@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transaction-processor/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrReviewNotPending is returned when deciding a review that is missing or already decided.
+var ErrReviewNotPending = errors.New("review not found or already decided")
+
+type RiskRepository interface {
+	AverageAmount(ctx context.Context, accountID string, lookback int) (float64, int64, error)
+	CountDebitsSince(ctx context.Context, accountID string, since time.Time) (int64, error)
+	RecordActivity(ctx context.Context, activity *model.RiskActivity) error
+	CreateReview(ctx context.Context, review *model.RiskReview) error
+	GetReview(ctx context.Context, transactionID string) (*model.RiskReview, error)
+	ListReviews(ctx context.Context, status string) ([]model.RiskReview, error)
+	DecideReview(ctx context.Context, transactionID string, status string, decidedBy string, note string) (*model.RiskReview, error)
+	MarkReviewApplied(ctx context.Context, transactionID string) error
+}
+
+type riskRepository struct {
+	db *gorm.DB
+}
+
+func NewRiskRepository(db *gorm.DB) RiskRepository {
+	return &riskRepository{db: db}
+}
+
+func (r *riskRepository) AverageAmount(ctx context.Context, accountID string, lookback int) (float64, int64, error) {
+	var result struct {
+		Average float64
+		Count   int64
+	}
+	recent := r.db.WithContext(ctx).Model(&model.RiskActivity{}).Select("amount").
+		Where("account_id = ? AND outcome = ?", accountID, "allow").
+		Order("accepted_at DESC").Limit(lookback)
+	err := r.db.WithContext(ctx).Table("(?) AS recent", recent).
+		Select("COALESCE(AVG(amount), 0) AS average, COUNT(*) AS count").
+		Scan(&result).Error
+	return result.Average, result.Count, err
+}
+
+func (r *riskRepository) CountDebitsSince(ctx context.Context, accountID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RiskActivity{}).
+		Where("account_id = ? AND transaction_type = ? AND accepted_at >= ?", accountID, "debit", since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *riskRepository) RecordActivity(ctx context.Context, activity *model.RiskActivity) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(activity).Error
+}
+
+// CreateReview parks a transaction; a redelivered transaction keeps its existing review.
+func (r *riskRepository) CreateReview(ctx context.Context, review *model.RiskReview) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(review).Error
+}
+
+func (r *riskRepository) GetReview(ctx context.Context, transactionID string) (*model.RiskReview, error) {
+	var review model.RiskReview
+	err := r.db.WithContext(ctx).First(&review, "transaction_id = ?", transactionID).Error
+	return &review, err
+}
+
+func (r *riskRepository) ListReviews(ctx context.Context, status string) ([]model.RiskReview, error) {
+	var reviews []model.RiskReview
+	query := r.db.WithContext(ctx).Order("created_at")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&reviews).Error
+	return reviews, err
+}
+
+// DecideReview moves a pending review to status. A review already decided with status whose
+// decision was not applied is returned as is, so applying the decision can be retried.
+func (r *riskRepository) DecideReview(ctx context.Context, transactionID string, status string, decidedBy string, note string) (*model.RiskReview, error) {
+	var review model.RiskReview
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&review, "transaction_id = ? AND (status = ? OR (status = ? AND applied_at IS NULL))",
+				transactionID, model.ReviewPending, status).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReviewNotPending
+		}
+		if err != nil || review.Status != model.ReviewPending {
+			return err
+		}
+
+		now := time.Now().UTC()
+		review.Status = status
+		review.DecidedBy = decidedBy
+		review.DecisionNote = note
+		review.DecidedAt = &now
+		return tx.Save(&review).Error
+	})
+	return &review, err
+}
+
+func (r *riskRepository) MarkReviewApplied(ctx context.Context, transactionID string) error {
+	return r.db.WithContext(ctx).Model(&model.RiskReview{}).
+		Where("transaction_id = ?", transactionID).
+		UpdateColumn("applied_at", time.Now().UTC()).Error
+}
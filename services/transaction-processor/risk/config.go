@@ -0,0 +1,148 @@
+package risk
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+//go:embed risk_rules.json
+var defaultRules []byte
+
+// Config is the rule chain definition read from the rules file.
+type Config struct {
+	Rules []RuleConfig `json:"rules"`
+}
+
+// RuleConfig configures one rule; Params are specific to the rule type.
+type RuleConfig struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Outcome Outcome         `json:"outcome"`
+	Enabled *bool           `json:"enabled"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// Factory builds a rule of one type from its params.
+type Factory func(params json.RawMessage, history History) (Rule, error)
+
+var factories = map[string]Factory{
+	"amount_anomaly":         newAmountAnomalyRule,
+	"rapid_debits":           newRapidDebitRule,
+	"new_account_withdrawal": newNewAccountWithdrawalRule,
+	"blocklist":              newBlocklistRule,
+}
+
+// RegisterFactory makes a custom rule type available to the rules file.
+func RegisterFactory(ruleType string, factory Factory) {
+	factories[ruleType] = factory
+}
+
+// LoadConfig reads the rules file, falling back to the embedded defaults when path is empty.
+func LoadConfig(path string) (*Config, error) {
+	data := defaultRules
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// NewEngineFromConfig builds the rule chain in the order the rules are listed.
+func NewEngineFromConfig(config *Config, history History) (*Engine, error) {
+	engine := NewEngine()
+	for _, rc := range config.Rules {
+		if rc.Enabled != nil && !*rc.Enabled {
+			continue
+		}
+		if rc.Outcome != Review && rc.Outcome != Deny {
+			return nil, fmt.Errorf("rule %s: outcome must be %q or %q", rc.Name, Review, Deny)
+		}
+		factory, ok := factories[rc.Type]
+		if !ok {
+			return nil, fmt.Errorf("rule %s: unknown rule type %q", rc.Name, rc.Type)
+		}
+		rule, err := factory(rc.Params, history)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rc.Name, err)
+		}
+		engine.Add(rc.Name, rc.Outcome, rule)
+	}
+	return engine, nil
+}
+
+// duration accepts Go duration strings such as "10m" in the rules file.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+func newAmountAnomalyRule(params json.RawMessage, history History) (Rule, error) {
+	p := struct {
+		Multiplier float64 `json:"multiplier"`
+		MinHistory int64   `json:"minHistory"`
+		Lookback   int     `json:"lookback"`
+	}{Multiplier: 5, MinHistory: 5, Lookback: 50}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	return &AmountAnomalyRule{History: history, Multiplier: p.Multiplier, MinHistory: p.MinHistory, Lookback: p.Lookback}, nil
+}
+
+func newRapidDebitRule(params json.RawMessage, history History) (Rule, error) {
+	p := struct {
+		MaxDebits int64    `json:"maxDebits"`
+		Window    duration `json:"window"`
+	}{MaxDebits: 5, Window: duration(10 * time.Minute)}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	return &RapidDebitRule{History: history, MaxDebits: p.MaxDebits, Window: time.Duration(p.Window)}, nil
+}
+
+func newNewAccountWithdrawalRule(params json.RawMessage, history History) (Rule, error) {
+	p := struct {
+		MaxAccountAge duration `json:"maxAccountAge"`
+		Amount        float64  `json:"amount"`
+	}{MaxAccountAge: duration(30 * 24 * time.Hour), Amount: 1000}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	return &NewAccountWithdrawalRule{MaxAccountAge: time.Duration(p.MaxAccountAge), Amount: p.Amount}, nil
+}
+
+func newBlocklistRule(params json.RawMessage, history History) (Rule, error) {
+	p := struct {
+		Counterparties []string `json:"counterparties"`
+	}{}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	return NewBlocklistRule(p.Counterparties), nil
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return json.Unmarshal(params, v)
+}
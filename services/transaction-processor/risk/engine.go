@@ -0,0 +1,98 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"transaction-processor/model"
+)
+
+// Outcome of a risk evaluation, ordered by severity.
+type Outcome string
+
+const (
+	Allow  Outcome = "allow"
+	Review Outcome = "review"
+	Deny   Outcome = "deny"
+)
+
+func (o Outcome) severity() int {
+	switch o {
+	case Deny:
+		return 2
+	case Review:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Rule checks a transaction and reports whether it triggers, with a human readable reason.
+type Rule interface {
+	Evaluate(ctx context.Context, transaction model.Transaction) (bool, string, error)
+}
+
+// History gives rules access to an account's previous transactions.
+type History interface {
+	AverageAmount(ctx context.Context, accountID string, lookback int) (float64, int64, error)
+	CountDebitsSince(ctx context.Context, accountID string, since time.Time) (int64, error)
+}
+
+// Finding is a rule that triggered on a transaction.
+type Finding struct {
+	Rule    string  `json:"rule"`
+	Outcome Outcome `json:"outcome"`
+	Reason  string  `json:"reason"`
+}
+
+// Result is the overall outcome of a transaction and the findings that led to it.
+type Result struct {
+	Outcome  Outcome   `json:"outcome"`
+	Findings []Finding `json:"findings"`
+}
+
+type configuredRule struct {
+	name    string
+	outcome Outcome
+	rule    Rule
+}
+
+// Engine runs a transaction through its rule chain; the most severe triggered outcome wins.
+type Engine struct {
+	rules []configuredRule
+}
+
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+// Add appends a rule to the chain.
+func (e *Engine) Add(name string, outcome Outcome, rule Rule) {
+	e.rules = append(e.rules, configuredRule{name: name, outcome: outcome, rule: rule})
+}
+
+// Evaluate runs every rule. A rule that fails to evaluate sends the transaction to review
+// rather than letting it through unchecked.
+func (e *Engine) Evaluate(ctx context.Context, transaction model.Transaction) Result {
+	result := Result{Outcome: Allow}
+	for _, r := range e.rules {
+		finding := Finding{Rule: r.name, Outcome: r.outcome}
+
+		triggered, reason, err := r.rule.Evaluate(ctx, transaction)
+		if err != nil {
+			triggered = true
+			finding.Outcome = Review
+			reason = "rule evaluation failed: " + err.Error()
+		}
+		if !triggered {
+			continue
+		}
+
+		finding.Reason = reason
+		result.Findings = append(result.Findings, finding)
+		if finding.Outcome.severity() > result.Outcome.severity() {
+			result.Outcome = finding.Outcome
+		}
+	}
+	return result
+}
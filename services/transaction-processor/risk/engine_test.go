@@ -0,0 +1,114 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-processor/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHistory struct {
+	mock.Mock
+}
+
+func (m *MockHistory) AverageAmount(ctx context.Context, accountID string, lookback int) (float64, int64, error) {
+	args := m.Called(ctx, accountID, lookback)
+	return args.Get(0).(float64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockHistory) CountDebitsSince(ctx context.Context, accountID string, since time.Time) (int64, error) {
+	args := m.Called(ctx, accountID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestLoadConfig_Default(t *testing.T) {
+	config, err := LoadConfig("")
+	assert.NoError(t, err)
+
+	engine, err := NewEngineFromConfig(config, new(MockHistory))
+	assert.NoError(t, err)
+	assert.Len(t, engine.rules, 4)
+}
+
+func TestNewEngineFromConfig_Invalid(t *testing.T) {
+	_, err := NewEngineFromConfig(&Config{Rules: []RuleConfig{{Name: "x", Type: "unknown", Outcome: Review}}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewEngineFromConfig(&Config{Rules: []RuleConfig{{Name: "x", Type: "blocklist", Outcome: Allow}}}, nil)
+	assert.Error(t, err)
+}
+
+func TestEngine_MostSevereOutcomeWins(t *testing.T) {
+	engine := NewEngine()
+	engine.Add("large-withdrawal", Review, &NewAccountWithdrawalRule{MaxAccountAge: 24 * time.Hour, Amount: 100})
+	engine.Add("blocklist", Deny, NewBlocklistRule([]string{"DE89 3704 0044 0532 0130 00"}))
+
+	now := time.Now().UTC()
+	transaction := model.Transaction{
+		Amount:           500,
+		TransactionType:  "debit",
+		AcceptedAt:       now,
+		AccountCreatedAt: now.Add(-time.Hour),
+		Counterparty:     "de89370400440532013000",
+	}
+
+	result := engine.Evaluate(context.Background(), transaction)
+	assert.Equal(t, Deny, result.Outcome)
+	assert.Len(t, result.Findings, 2)
+}
+
+func TestEngine_Allow(t *testing.T) {
+	engine := NewEngine()
+	engine.Add("blocklist", Deny, NewBlocklistRule([]string{"BAD"}))
+
+	result := engine.Evaluate(context.Background(), model.Transaction{Amount: 10, Counterparty: "GOOD"})
+	assert.Equal(t, Allow, result.Outcome)
+	assert.Empty(t, result.Findings)
+}
+
+func TestEngine_RuleErrorSendsToReview(t *testing.T) {
+	history := new(MockHistory)
+	history.On("AverageAmount", mock.Anything, "acc-1", 50).Return(0.0, int64(0), errors.New("db error"))
+
+	engine := NewEngine()
+	engine.Add("amount-anomaly", Deny, &AmountAnomalyRule{History: history, Multiplier: 5, MinHistory: 5, Lookback: 50})
+
+	result := engine.Evaluate(context.Background(), model.Transaction{AccountID: "acc-1", Amount: 10})
+	assert.Equal(t, Review, result.Outcome)
+}
+
+func TestAmountAnomalyRule(t *testing.T) {
+	history := new(MockHistory)
+	history.On("AverageAmount", mock.Anything, "acc-1", 50).Return(100.0, int64(10), nil)
+	history.On("AverageAmount", mock.Anything, "acc-2", 50).Return(100.0, int64(2), nil)
+	rule := &AmountAnomalyRule{History: history, Multiplier: 5, MinHistory: 5, Lookback: 50}
+
+	triggered, _, err := rule.Evaluate(context.Background(), model.Transaction{AccountID: "acc-1", Amount: 600})
+	assert.NoError(t, err)
+	assert.True(t, triggered)
+
+	triggered, _, _ = rule.Evaluate(context.Background(), model.Transaction{AccountID: "acc-1", Amount: 400})
+	assert.False(t, triggered)
+
+	triggered, _, _ = rule.Evaluate(context.Background(), model.Transaction{AccountID: "acc-2", Amount: 600})
+	assert.False(t, triggered, "not enough history")
+}
+
+func TestRapidDebitRule(t *testing.T) {
+	history := new(MockHistory)
+	now := time.Now().UTC()
+	history.On("CountDebitsSince", mock.Anything, "acc-1", now.Add(-10*time.Minute)).Return(int64(5), nil)
+	rule := &RapidDebitRule{History: history, MaxDebits: 5, Window: 10 * time.Minute}
+
+	triggered, _, err := rule.Evaluate(context.Background(), model.Transaction{AccountID: "acc-1", TransactionType: "debit", AcceptedAt: now})
+	assert.NoError(t, err)
+	assert.True(t, triggered)
+
+	triggered, _, _ = rule.Evaluate(context.Background(), model.Transaction{AccountID: "acc-1", TransactionType: "credit", AcceptedAt: now})
+	assert.False(t, triggered)
+}
@@ -0,0 +1,105 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"transaction-processor/model"
+)
+
+// AmountAnomalyRule triggers when a transaction is Multiplier times larger than the
+// account's average amount over its last Lookback transactions.
+type AmountAnomalyRule struct {
+	History    History
+	Multiplier float64
+	MinHistory int64
+	Lookback   int
+}
+
+func (r *AmountAnomalyRule) Evaluate(ctx context.Context, transaction model.Transaction) (bool, string, error) {
+	average, count, err := r.History.AverageAmount(ctx, transaction.AccountID, r.Lookback)
+	if err != nil {
+		return false, "", err
+	}
+	if count < r.MinHistory || average <= 0 {
+		return false, "", nil
+	}
+	if transaction.Amount > average*r.Multiplier {
+		return true, fmt.Sprintf("amount %.2f is more than %.1fx the account average %.2f", transaction.Amount, r.Multiplier, average), nil
+	}
+	return false, "", nil
+}
+
+// RapidDebitRule triggers when an account has already made MaxDebits debits within Window.
+type RapidDebitRule struct {
+	History   History
+	MaxDebits int64
+	Window    time.Duration
+}
+
+func (r *RapidDebitRule) Evaluate(ctx context.Context, transaction model.Transaction) (bool, string, error) {
+	if transaction.TransactionType != "debit" {
+		return false, "", nil
+	}
+	count, err := r.History.CountDebitsSince(ctx, transaction.AccountID, acceptedAt(transaction).Add(-r.Window))
+	if err != nil {
+		return false, "", err
+	}
+	if count >= r.MaxDebits {
+		return true, fmt.Sprintf("%d debits within %s", count+1, r.Window), nil
+	}
+	return false, "", nil
+}
+
+// NewAccountWithdrawalRule triggers on debits above Amount from accounts younger than MaxAccountAge.
+type NewAccountWithdrawalRule struct {
+	MaxAccountAge time.Duration
+	Amount        float64
+}
+
+func (r *NewAccountWithdrawalRule) Evaluate(ctx context.Context, transaction model.Transaction) (bool, string, error) {
+	if transaction.TransactionType != "debit" || transaction.AccountCreatedAt.IsZero() {
+		return false, "", nil
+	}
+	age := acceptedAt(transaction).Sub(transaction.AccountCreatedAt)
+	if age < r.MaxAccountAge && transaction.Amount > r.Amount {
+		return true, fmt.Sprintf("withdrawal of %.2f from an account opened %s ago", transaction.Amount, age.Round(time.Minute)), nil
+	}
+	return false, "", nil
+}
+
+// BlocklistRule triggers when the transaction's counterparty is blocklisted.
+type BlocklistRule struct {
+	Counterparties map[string]struct{}
+}
+
+func NewBlocklistRule(counterparties []string) *BlocklistRule {
+	rule := &BlocklistRule{Counterparties: make(map[string]struct{}, len(counterparties))}
+	for _, counterparty := range counterparties {
+		rule.Counterparties[normalizeCounterparty(counterparty)] = struct{}{}
+	}
+	return rule
+}
+
+func (r *BlocklistRule) Evaluate(ctx context.Context, transaction model.Transaction) (bool, string, error) {
+	if transaction.Counterparty == "" {
+		return false, "", nil
+	}
+	if _, ok := r.Counterparties[normalizeCounterparty(transaction.Counterparty)]; ok {
+		return true, fmt.Sprintf("counterparty %s is blocklisted", transaction.Counterparty), nil
+	}
+	return false, "", nil
+}
+
+func normalizeCounterparty(counterparty string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(counterparty), " ", ""))
+}
+
+func acceptedAt(transaction model.Transaction) time.Time {
+	if transaction.AcceptedAt.IsZero() {
+		return time.Now().UTC()
+	}
+	return transaction.AcceptedAt
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"transaction-processor/model"
+	"transaction-processor/repository"
+)
+
+type LimitService interface {
+	Check(ctx context.Context, transaction model.Transaction) error
+	Release(ctx context.Context, transactionID string) error
+	GetLimitStatus(ctx context.Context, accountID string, accountType string, customerID string) (*model.LimitStatus, error)
+	SetLimit(ctx context.Context, limit *model.TransactionLimit) error
+	DeleteLimit(ctx context.Context, scope string, scopeID string) error
+}
+
+type limitService struct {
+	repo repository.LimitRepository
+}
+
+func NewLimitService(repo repository.LimitRepository) LimitService {
+	return &limitService{repo: repo}
+}
+
+// Check enforces the effective limits of the transaction's account and records its usage.
+// A broken limit is reported as *model.LimitExceededError.
+func (s *limitService) Check(ctx context.Context, transaction model.Transaction) error {
+	limits, err := s.effectiveLimits(ctx, transaction.AccountID, transaction.AccountType, transaction.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if limits.SingleTransactionMax != nil && transaction.Amount > *limits.SingleTransactionMax {
+		return &model.LimitExceededError{Reason: model.ReasonSingleTransactionLimit, Limit: *limits.SingleTransactionMax, Value: transaction.Amount}
+	}
+
+	acceptedAt := transaction.AcceptedAt
+	if acceptedAt.IsZero() {
+		acceptedAt = time.Now().UTC()
+	}
+
+	reservation := &model.LimitReservation{
+		TransactionID:   transaction.ID,
+		AccountID:       transaction.AccountID,
+		Amount:          transaction.Amount,
+		TransactionType: transaction.TransactionType,
+		AcceptedAt:      acceptedAt,
+	}
+	return s.repo.Reserve(ctx, reservation, func(usage map[string]model.LimitUsage) error {
+		return checkUsage(limits, usage, transaction)
+	})
+}
+
+func (s *limitService) Release(ctx context.Context, transactionID string) error {
+	return s.repo.Release(ctx, transactionID)
+}
+
+func (s *limitService) GetLimitStatus(ctx context.Context, accountID string, accountType string, customerID string) (*model.LimitStatus, error) {
+	limits, err := s.effectiveLimits(ctx, accountID, accountType, customerID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.repo.GetUsage(ctx, accountID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.LimitStatus{AccountID: accountID, Limits: limits}
+	if limits.DailyDebitMax != nil {
+		remaining := max(*limits.DailyDebitMax-usage[model.PeriodDay].DebitTotal, 0)
+		status.DailyDebitRemaining = &remaining
+	}
+	if limits.MonthlyDebitMax != nil {
+		remaining := max(*limits.MonthlyDebitMax-usage[model.PeriodMonth].DebitTotal, 0)
+		status.MonthlyDebitRemaining = &remaining
+	}
+	if limits.HourlyCountMax != nil {
+		remaining := max(*limits.HourlyCountMax-usage[model.PeriodHour].TransactionCount, 0)
+		status.HourlyCountRemaining = &remaining
+	}
+	return status, nil
+}
+
+func (s *limitService) SetLimit(ctx context.Context, limit *model.TransactionLimit) error {
+	limit.UpdatedAt = time.Now().UTC()
+	return s.repo.UpsertLimit(ctx, limit)
+}
+
+func (s *limitService) DeleteLimit(ctx context.Context, scope string, scopeID string) error {
+	return s.repo.DeleteLimit(ctx, scope, scopeID)
+}
+
+// effectiveLimits layers account-type defaults, customer overrides and account overrides.
+func (s *limitService) effectiveLimits(ctx context.Context, accountID string, accountType string, customerID string) (model.TransactionLimit, error) {
+	var limits model.TransactionLimit
+	scopes := []struct{ scope, id string }{
+		{model.LimitScopeAccountType, accountType},
+		{model.LimitScopeCustomer, customerID},
+		{model.LimitScopeAccount, accountID},
+	}
+	for _, scope := range scopes {
+		if scope.id == "" {
+			continue
+		}
+		limit, err := s.repo.GetLimit(ctx, scope.scope, scope.id)
+		if err != nil {
+			return limits, err
+		}
+		if limit != nil {
+			limits = limits.Merge(*limit)
+		}
+	}
+	return limits, nil
+}
+
+func checkUsage(limits model.TransactionLimit, usage map[string]model.LimitUsage, transaction model.Transaction) error {
+	if limits.HourlyCountMax != nil {
+		count := usage[model.PeriodHour].TransactionCount + 1
+		if count > *limits.HourlyCountMax {
+			return &model.LimitExceededError{Reason: model.ReasonHourlyCountLimit, Limit: float64(*limits.HourlyCountMax), Value: float64(count)}
+		}
+	}
+
+	if transaction.TransactionType != "debit" {
+		return nil
+	}
+	if limits.DailyDebitMax != nil {
+		total := usage[model.PeriodDay].DebitTotal + transaction.Amount
+		if total > *limits.DailyDebitMax {
+			return &model.LimitExceededError{Reason: model.ReasonDailyDebitLimit, Limit: *limits.DailyDebitMax, Value: total}
+		}
+	}
+	if limits.MonthlyDebitMax != nil {
+		total := usage[model.PeriodMonth].DebitTotal + transaction.Amount
+		if total > *limits.MonthlyDebitMax {
+			return &model.LimitExceededError{Reason: model.ReasonMonthlyDebitLimit, Limit: *limits.MonthlyDebitMax, Value: total}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-processor/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockLimitRepository) GetLimit(ctx context.Context, scope string, scopeID string) (*model.TransactionLimit, error) {
+	args := m.Called(ctx, scope, scopeID)
+	limit, _ := args.Get(0).(*model.TransactionLimit)
+	return limit, args.Error(1)
+}
+
+func (m *MockLimitRepository) UpsertLimit(ctx context.Context, limit *model.TransactionLimit) error {
+	args := m.Called(ctx, limit)
+	return args.Error(0)
+}
+
+func (m *MockLimitRepository) DeleteLimit(ctx context.Context, scope string, scopeID string) error {
+	args := m.Called(ctx, scope, scopeID)
+	return args.Error(0)
+}
+
+func (m *MockLimitRepository) GetUsage(ctx context.Context, accountID string, at time.Time) (map[string]model.LimitUsage, error) {
+	args := m.Called(ctx, accountID, at)
+	return args.Get(0).(map[string]model.LimitUsage), args.Error(1)
+}
+
+func (m *MockLimitRepository) Reserve(ctx context.Context, reservation *model.LimitReservation, check func(usage map[string]model.LimitUsage) error) error {
+	args := m.Called(ctx, reservation)
+	if err := args.Error(1); err != nil {
+		return err
+	}
+	return check(args.Get(0).(map[string]model.LimitUsage))
+}
+
+func (m *MockLimitRepository) Release(ctx context.Context, transactionID string) error {
+	args := m.Called(ctx, transactionID)
+	return args.Error(0)
+}
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int64) *int64       { return &v }
+
+func mockLimits(repo *MockLimitRepository, ctx context.Context) {
+	repo.On("GetLimit", ctx, model.LimitScopeAccountType, "savings").Return(&model.TransactionLimit{
+		SingleTransactionMax: floatPtr(1000),
+		DailyDebitMax:        floatPtr(2000),
+		HourlyCountMax:       intPtr(5),
+	}, nil)
+	repo.On("GetLimit", ctx, model.LimitScopeCustomer, "cust-1").Return(&model.TransactionLimit{
+		DailyDebitMax: floatPtr(500),
+	}, nil)
+	repo.On("GetLimit", ctx, model.LimitScopeAccount, "acc-1").Return(nil, nil)
+}
+
+func TestCheck_SingleTransactionLimit(t *testing.T) {
+	mockRepo := new(MockLimitRepository)
+	service := NewLimitService(mockRepo)
+	ctx := context.Background()
+	mockLimits(mockRepo, ctx)
+
+	err := service.Check(ctx, model.Transaction{ID: "t1", AccountID: "acc-1", AccountType: "savings", CustomerID: "cust-1", Amount: 1500, TransactionType: "credit"})
+
+	var limitErr *model.LimitExceededError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, model.ReasonSingleTransactionLimit, limitErr.Reason)
+	mockRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything)
+}
+
+func TestCheck_CustomerOverrideDailyDebit(t *testing.T) {
+	mockRepo := new(MockLimitRepository)
+	service := NewLimitService(mockRepo)
+	ctx := context.Background()
+	mockLimits(mockRepo, ctx)
+
+	usage := map[string]model.LimitUsage{
+		model.PeriodHour:  {TransactionCount: 1},
+		model.PeriodDay:   {DebitTotal: 400},
+		model.PeriodMonth: {DebitTotal: 400},
+	}
+	mockRepo.On("Reserve", ctx, mock.MatchedBy(func(r *model.LimitReservation) bool {
+		return r.TransactionID == "t1" && r.AccountID == "acc-1" && !r.AcceptedAt.IsZero()
+	})).Return(usage, nil)
+
+	err := service.Check(ctx, model.Transaction{ID: "t1", AccountID: "acc-1", AccountType: "savings", CustomerID: "cust-1", Amount: 200, TransactionType: "debit"})
+
+	var limitErr *model.LimitExceededError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, model.ReasonDailyDebitLimit, limitErr.Reason)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheck_HourlyCount(t *testing.T) {
+	mockRepo := new(MockLimitRepository)
+	service := NewLimitService(mockRepo)
+	ctx := context.Background()
+	mockLimits(mockRepo, ctx)
+
+	usage := map[string]model.LimitUsage{model.PeriodHour: {TransactionCount: 5}}
+	mockRepo.On("Reserve", ctx, mock.Anything).Return(usage, nil)
+
+	err := service.Check(ctx, model.Transaction{ID: "t1", AccountID: "acc-1", AccountType: "savings", CustomerID: "cust-1", Amount: 10, TransactionType: "credit"})
+
+	var limitErr *model.LimitExceededError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, model.ReasonHourlyCountLimit, limitErr.Reason)
+}
+
+func TestCheck_WithinLimits(t *testing.T) {
+	mockRepo := new(MockLimitRepository)
+	service := NewLimitService(mockRepo)
+	ctx := context.Background()
+	mockLimits(mockRepo, ctx)
+
+	usage := map[string]model.LimitUsage{model.PeriodDay: {DebitTotal: 100}}
+	mockRepo.On("Reserve", ctx, mock.Anything).Return(usage, nil)
+
+	err := service.Check(ctx, model.Transaction{ID: "t1", AccountID: "acc-1", AccountType: "savings", CustomerID: "cust-1", Amount: 300, TransactionType: "debit"})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheck_RepositoryError(t *testing.T) {
+	mockRepo := new(MockLimitRepository)
+	service := NewLimitService(mockRepo)
+	ctx := context.Background()
+	mockRepo.On("GetLimit", ctx, model.LimitScopeAccountType, "savings").Return(nil, errors.New("db error"))
+
+	err := service.Check(ctx, model.Transaction{AccountID: "acc-1", AccountType: "savings", Amount: 10})
+	assert.EqualError(t, err, "db error")
+}
+
+func TestGetLimitStatus(t *testing.T) {
+	mockRepo := new(MockLimitRepository)
+	service := NewLimitService(mockRepo)
+	ctx := context.Background()
+	mockLimits(mockRepo, ctx)
+
+	usage := map[string]model.LimitUsage{
+		model.PeriodHour: {TransactionCount: 2},
+		model.PeriodDay:  {DebitTotal: 600},
+	}
+	mockRepo.On("GetUsage", ctx, "acc-1", mock.Anything).Return(usage, nil)
+
+	status, err := service.GetLimitStatus(ctx, "acc-1", "savings", "cust-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, *status.DailyDebitRemaining)
+	assert.Equal(t, int64(3), *status.HourlyCountRemaining)
+	assert.Nil(t, status.MonthlyDebitRemaining)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"transaction-processor/model"
+	"transaction-processor/repository"
+	"transaction-processor/risk"
+)
+
+type RiskService interface {
+	Assess(ctx context.Context, key []byte, value []byte, transaction model.Transaction) (risk.Result, error)
+}
+
+// TransactionForwarder resumes the processing of a transaction once its review is decided.
+type TransactionForwarder interface {
+	Forward(ctx context.Context, key []byte, value []byte) error
+	Reject(ctx context.Context, key []byte, value []byte, reason string) error
+}
+
+type ReviewService interface {
+	ListReviews(ctx context.Context, status string) ([]model.RiskReview, error)
+	GetReview(ctx context.Context, transactionID string) (*model.RiskReview, error)
+	Approve(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error)
+	Reject(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error)
+}
+
+type riskService struct {
+	engine *risk.Engine
+	repo   repository.RiskRepository
+}
+
+func NewRiskService(engine *risk.Engine, repo repository.RiskRepository) RiskService {
+	return &riskService{engine: engine, repo: repo}
+}
+
+// Assess evaluates the transaction, records it in the account's risk history and parks it
+// for manual review when the outcome is review.
+func (s *riskService) Assess(ctx context.Context, key []byte, value []byte, transaction model.Transaction) (risk.Result, error) {
+	result := s.engine.Evaluate(ctx, transaction)
+
+	acceptedAt := transaction.AcceptedAt
+	if acceptedAt.IsZero() {
+		acceptedAt = time.Now().UTC()
+	}
+	if err := s.repo.RecordActivity(ctx, &model.RiskActivity{
+		TransactionID:   transaction.ID,
+		AccountID:       transaction.AccountID,
+		Amount:          transaction.Amount,
+		TransactionType: transaction.TransactionType,
+		Outcome:         string(result.Outcome),
+		AcceptedAt:      acceptedAt,
+	}); err != nil {
+		return result, err
+	}
+
+	if result.Outcome != risk.Review {
+		return result, nil
+	}
+
+	reasons, err := json.Marshal(result.Findings)
+	if err != nil {
+		return result, err
+	}
+	return result, s.repo.CreateReview(ctx, &model.RiskReview{
+		TransactionID: transaction.ID,
+		AccountID:     transaction.AccountID,
+		Amount:        transaction.Amount,
+		MessageKey:    key,
+		Payload:       value,
+		Reasons:       reasons,
+		Status:        model.ReviewPending,
+	})
+}
+
+type reviewService struct {
+	repo      repository.RiskRepository
+	forwarder TransactionForwarder
+}
+
+func NewReviewService(repo repository.RiskRepository, forwarder TransactionForwarder) ReviewService {
+	return &reviewService{repo: repo, forwarder: forwarder}
+}
+
+func (s *reviewService) ListReviews(ctx context.Context, status string) ([]model.RiskReview, error) {
+	return s.repo.ListReviews(ctx, status)
+}
+
+func (s *reviewService) GetReview(ctx context.Context, transactionID string) (*model.RiskReview, error) {
+	return s.repo.GetReview(ctx, transactionID)
+}
+
+// Approve forwards the parked transaction for balance update.
+func (s *reviewService) Approve(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error) {
+	return s.decide(ctx, transactionID, model.ReviewApproved, reviewer, note, func(review *model.RiskReview) error {
+		return s.forwarder.Forward(ctx, review.MessageKey, review.Payload)
+	})
+}
+
+// Reject fails the parked transaction.
+func (s *reviewService) Reject(ctx context.Context, transactionID string, reviewer string, note string) (*model.RiskReview, error) {
+	return s.decide(ctx, transactionID, model.ReviewRejected, reviewer, note, func(review *model.RiskReview) error {
+		return s.forwarder.Reject(ctx, review.MessageKey, review.Payload, model.ReasonRiskReviewRejected)
+	})
+}
+
+// decide records the decision, then applies it. Nothing is published for a decision that was not
+// recorded; a decision that failed to apply is applied again when it is repeated.
+func (s *reviewService) decide(ctx context.Context, transactionID, status, reviewer, note string, apply func(review *model.RiskReview) error) (*model.RiskReview, error) {
+	review, err := s.repo.DecideReview(ctx, transactionID, status, reviewer, note)
+	if err != nil {
+		return review, err
+	}
+	if err := apply(review); err != nil {
+		return review, err
+	}
+	if err := s.repo.MarkReviewApplied(ctx, transactionID); err != nil {
+		return review, err
+	}
+	now := time.Now().UTC()
+	review.AppliedAt = &now
+	return review, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-processor/model"
+	"transaction-processor/risk"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRiskRepository struct {
+	mock.Mock
+}
+
+func (m *MockRiskRepository) AverageAmount(ctx context.Context, accountID string, lookback int) (float64, int64, error) {
+	args := m.Called(ctx, accountID, lookback)
+	return args.Get(0).(float64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRiskRepository) CountDebitsSince(ctx context.Context, accountID string, since time.Time) (int64, error) {
+	args := m.Called(ctx, accountID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRiskRepository) RecordActivity(ctx context.Context, activity *model.RiskActivity) error {
+	args := m.Called(ctx, activity)
+	return args.Error(0)
+}
+
+func (m *MockRiskRepository) CreateReview(ctx context.Context, review *model.RiskReview) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+func (m *MockRiskRepository) GetReview(ctx context.Context, transactionID string) (*model.RiskReview, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).(*model.RiskReview), args.Error(1)
+}
+
+func (m *MockRiskRepository) ListReviews(ctx context.Context, status string) ([]model.RiskReview, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]model.RiskReview), args.Error(1)
+}
+
+func (m *MockRiskRepository) DecideReview(ctx context.Context, transactionID string, status string, decidedBy string, note string) (*model.RiskReview, error) {
+	args := m.Called(ctx, transactionID, status, decidedBy, note)
+	return args.Get(0).(*model.RiskReview), args.Error(1)
+}
+
+func (m *MockRiskRepository) MarkReviewApplied(ctx context.Context, transactionID string) error {
+	args := m.Called(ctx, transactionID)
+	return args.Error(0)
+}
+
+type MockForwarder struct {
+	mock.Mock
+}
+
+func (m *MockForwarder) Forward(ctx context.Context, key []byte, value []byte) error {
+	args := m.Called(ctx, key, value)
+	return args.Error(0)
+}
+
+func (m *MockForwarder) Reject(ctx context.Context, key []byte, value []byte, reason string) error {
+	args := m.Called(ctx, key, value, reason)
+	return args.Error(0)
+}
+
+func TestAssess_ParksReview(t *testing.T) {
+	mockRepo := new(MockRiskRepository)
+	engine := risk.NewEngine()
+	engine.Add("large-withdrawal", risk.Review, &risk.NewAccountWithdrawalRule{MaxAccountAge: time.Hour, Amount: 100})
+	service := NewRiskService(engine, mockRepo)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	transaction := model.Transaction{ID: "t1", AccountID: "acc-1", Amount: 500, TransactionType: "debit", AcceptedAt: now, AccountCreatedAt: now}
+	value := []byte(`{"id":"t1"}`)
+
+	mockRepo.On("RecordActivity", ctx, mock.MatchedBy(func(activity *model.RiskActivity) bool {
+		return activity.TransactionID == "t1" && activity.Outcome == "review"
+	})).Return(nil)
+	mockRepo.On("CreateReview", ctx, mock.MatchedBy(func(review *model.RiskReview) bool {
+		return review.TransactionID == "t1" && review.Status == model.ReviewPending && string(review.Payload) == string(value)
+	})).Return(nil)
+
+	result, err := service.Assess(ctx, []byte("key"), value, transaction)
+	assert.NoError(t, err)
+	assert.Equal(t, risk.Review, result.Outcome)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAssess_Allow(t *testing.T) {
+	mockRepo := new(MockRiskRepository)
+	service := NewRiskService(risk.NewEngine(), mockRepo)
+
+	ctx := context.Background()
+	mockRepo.On("RecordActivity", ctx, mock.Anything).Return(nil)
+
+	result, err := service.Assess(ctx, nil, nil, model.Transaction{ID: "t1", Amount: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, risk.Allow, result.Outcome)
+	mockRepo.AssertNotCalled(t, "CreateReview", mock.Anything, mock.Anything)
+}
+
+func TestApproveReview(t *testing.T) {
+	mockRepo := new(MockRiskRepository)
+	mockForwarder := new(MockForwarder)
+	service := NewReviewService(mockRepo, mockForwarder)
+
+	ctx := context.Background()
+	review := &model.RiskReview{TransactionID: "t1", MessageKey: []byte("key"), Payload: []byte(`{"id":"t1"}`)}
+	mockRepo.On("DecideReview", ctx, "t1", model.ReviewApproved, "ops", "checked").Return(review, nil)
+	mockForwarder.On("Forward", ctx, review.MessageKey, []byte(review.Payload)).Return(nil)
+	mockRepo.On("MarkReviewApplied", ctx, "t1").Return(nil)
+
+	result, err := service.Approve(ctx, "t1", "ops", "checked")
+	assert.NoError(t, err)
+	assert.Equal(t, review, result)
+	assert.NotNil(t, result.AppliedAt)
+	mockForwarder.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestApproveReview_NotRecorded(t *testing.T) {
+	mockRepo := new(MockRiskRepository)
+	mockForwarder := new(MockForwarder)
+	service := NewReviewService(mockRepo, mockForwarder)
+
+	ctx := context.Background()
+	mockRepo.On("DecideReview", ctx, "t1", model.ReviewApproved, "ops", "").Return(&model.RiskReview{}, errors.New("db error"))
+
+	_, err := service.Approve(ctx, "t1", "ops", "")
+	assert.Error(t, err)
+	// Nothing is forwarded for a decision that was not committed
+	mockForwarder.AssertNotCalled(t, "Forward", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRejectReview(t *testing.T) {
+	mockRepo := new(MockRiskRepository)
+	mockForwarder := new(MockForwarder)
+	service := NewReviewService(mockRepo, mockForwarder)
+
+	ctx := context.Background()
+	review := &model.RiskReview{TransactionID: "t1", MessageKey: []byte("key"), Payload: []byte(`{"id":"t1"}`)}
+	mockRepo.On("DecideReview", ctx, "t1", model.ReviewRejected, "ops", "").Return(review, nil)
+	mockForwarder.On("Reject", ctx, review.MessageKey, []byte(review.Payload), model.ReasonRiskReviewRejected).Return(errors.New("kafka error"))
+
+	_, err := service.Reject(ctx, "t1", "ops", "")
+	assert.Error(t, err)
+	mockForwarder.AssertExpectations(t)
+	// Left unapplied, so rejecting again retries the publish
+	mockRepo.AssertNotCalled(t, "MarkReviewApplied", mock.Anything, mock.Anything)
+}
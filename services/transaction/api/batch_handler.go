@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"transaction/model"
+	"transaction/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
+)
+
+// maxBatchItems caps the number of lines accepted in one batch.
+const maxBatchItems = 10000
+
+var (
+	errBatchTooLarge     = fmt.Errorf("batch exceeds %d lines", maxBatchItems)
+	errAccountNotAllowed = errors.New("account is not allowed for this API key")
+)
+
+type batchHandler struct {
+	batchService service.BatchService
+}
+
+type BatchHandler interface {
+	SubmitBatch(c *gin.Context)
+	GetBatch(c *gin.Context)
+	ListBatchItems(c *gin.Context)
+}
+
+func NewBatchHandler(batchService service.BatchService) BatchHandler {
+	return &batchHandler{batchService: batchService}
+}
+
+// SubmitBatch accepts a JSON array or newline-delimited JSON of transactions. The mode
+// query parameter selects "partial" (default) or "all_or_nothing".
+func (h *batchHandler) SubmitBatch(c *gin.Context) {
+	mode := c.DefaultQuery("mode", model.BatchModePartial)
+	if mode != model.BatchModePartial && mode != model.BatchModeAllOrNothing {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch mode"})
+		return
+	}
+
+	lines, err := parseBatch(c.Request.Body)
+	if errors.Is(err, errBatchTooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(lines) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Batch is empty"})
+		return
+	}
+	for i := range lines {
+		if lines[i].Err == nil && !server.AccountAllowed(c, lines[i].Transaction.AccountID.String()) {
+			lines[i].Err = errAccountNotAllowed
+		}
+	}
+
+	batch, items, err := h.batchService.SubmitBatch(c.Request.Context(), mode, lines)
+	if errors.Is(err, service.ErrBatchRejected) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "batch": batch, "rejected": rejectedItems(items)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"batch": batch, "rejected": rejectedItems(items)})
+}
+
+func (h *batchHandler) GetBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	summary, err := h.batchService.GetBatchSummary(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *batchHandler) ListBatchItems(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	items, err := h.batchService.ListItems(c.Request.Context(), id, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if _, restricted := server.AccountScope(c); restricted {
+		allowed := make([]model.BatchItem, 0, len(items))
+		for _, item := range items {
+			if item.AccountID != nil && server.AccountAllowed(c, item.AccountID.String()) {
+				allowed = append(allowed, item)
+			}
+		}
+		items = allowed
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// parseBatch reads the batch body. A line that is not a valid transaction is kept with its
+// error so it can be reported against its line number.
+func parseBatch(body io.Reader) ([]service.BatchLine, error) {
+	reader := bufio.NewReader(body)
+	first, err := peekNonSpace(reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []service.BatchLine
+	add := func(raw []byte) error {
+		if len(lines) == maxBatchItems {
+			return errBatchTooLarge
+		}
+		var line service.BatchLine
+		line.Err = json.Unmarshal(raw, &line.Transaction)
+		lines = append(lines, line)
+		return nil
+	}
+
+	if first == '[' {
+		decoder := json.NewDecoder(reader)
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, err
+			}
+			if err := add(raw); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return lines, nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if err := add(raw); err != nil {
+			return nil, err
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, reader.UnreadByte()
+		}
+	}
+}
+
+func rejectedItems(items []model.BatchItem) []model.BatchItem {
+	rejected := []model.BatchItem{}
+	for _, item := range items {
+		if (item.Status == model.ItemRejected && item.Reason != model.ReasonBatchRejected) || item.Status == model.ItemFailed {
+			rejected = append(rejected, item)
+		}
+	}
+	return rejected
+}
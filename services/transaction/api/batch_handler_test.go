@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"transaction/model"
+	"transaction/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBatchService struct {
+	mock.Mock
+}
+
+func (m *MockBatchService) SubmitBatch(ctx context.Context, mode string, lines []service.BatchLine) (*model.Batch, []model.BatchItem, error) {
+	args := m.Called(ctx, mode, lines)
+	return args.Get(0).(*model.Batch), args.Get(1).([]model.BatchItem), args.Error(2)
+}
+
+func (m *MockBatchService) GetBatchSummary(ctx context.Context, id uuid.UUID) (*model.BatchSummary, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.BatchSummary), args.Error(1)
+}
+
+func (m *MockBatchService) ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error) {
+	args := m.Called(ctx, batchID, status)
+	return args.Get(0).([]model.BatchItem), args.Error(1)
+}
+
+func (m *MockBatchService) RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return m.Called(ctx, transactionID, status, reason).Error(0)
+}
+
+func TestParseBatch(t *testing.T) {
+	accountID := uuid.New().String()
+
+	t.Run("JSON array", func(t *testing.T) {
+		lines, err := parseBatch(strings.NewReader(` [{"accountId":"` + accountID + `","amount":10,"transactionType":"credit"}, {"amount":"x"}]`))
+		assert.NoError(t, err)
+		assert.Len(t, lines, 2)
+		assert.NoError(t, lines[0].Err)
+		assert.Equal(t, 10.0, lines[0].Transaction.Amount)
+		assert.Error(t, lines[1].Err)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		lines, err := parseBatch(strings.NewReader(`{"accountId":"` + accountID + `","amount":10,"transactionType":"credit"}` + "\n\n" + `not json` + "\n"))
+		assert.NoError(t, err)
+		assert.Len(t, lines, 2)
+		assert.NoError(t, lines[0].Err)
+		assert.Error(t, lines[1].Err)
+	})
+
+	t.Run("too many lines", func(t *testing.T) {
+		body := strings.Repeat(`{"amount":1}`+"\n", maxBatchItems+1)
+		_, err := parseBatch(strings.NewReader(body))
+		assert.ErrorIs(t, err, errBatchTooLarge)
+	})
+}
+
+func TestSubmitBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockBatchService)
+	handler := NewBatchHandler(mockService)
+
+	router := gin.Default()
+	router.POST("/transactions/batch", handler.SubmitBatch)
+
+	t.Run("should return 202 with the rejected lines", func(t *testing.T) {
+		items := []model.BatchItem{{Line: 1, Status: model.ItemSubmitted}, {Line: 2, Status: model.ItemRejected, Reason: "invalid transaction amount"}}
+		mockService.On("SubmitBatch", mock.Anything, model.BatchModePartial, mock.Anything).Return(&model.Batch{ID: uuid.New()}, items, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/batch", bytes.NewBufferString(`[{"amount":1},{"amount":-1}]`))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Contains(t, resp.Body.String(), "invalid transaction amount")
+	})
+
+	t.Run("should return 422 if an all-or-nothing batch is rejected", func(t *testing.T) {
+		mockService.On("SubmitBatch", mock.Anything, model.BatchModeAllOrNothing, mock.Anything).Return(&model.Batch{ID: uuid.New()}, []model.BatchItem{}, service.ErrBatchRejected).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/batch?mode=all_or_nothing", bytes.NewBufferString(`{"amount":1}`))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	})
+
+	t.Run("should return 400 for an unknown mode or empty batch", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/batch?mode=some", bytes.NewBufferString(`{"amount":1}`))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		req, _ = http.NewRequest(http.MethodPost, "/transactions/batch", bytes.NewBufferString(` `))
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"transaction/bankimport"
+	"transaction/model"
+	"transaction/repository"
+	"transaction/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxImportSize caps the size of an uploaded bank file.
+	maxImportSize = 32 << 20
+	// maxImportLines caps the number of payments in one bank file.
+	maxImportLines = 50000
+)
+
+type importHandler struct {
+	importService service.ImportService
+	csvMapping    *bankimport.CSVMapping
+}
+
+type ImportHandler interface {
+	ImportFile(c *gin.Context)
+	GetImport(c *gin.Context)
+}
+
+func NewImportHandler(importService service.ImportService, csvMapping *bankimport.CSVMapping) ImportHandler {
+	return &importHandler{importService: importService, csvMapping: csvMapping}
+}
+
+// ImportFile imports a bank file uploaded as the file field of a multipart form or as the
+// request body. The format query parameter is camt054 or csv, and otherwise follows the file
+// name extension.
+func (h *importHandler) ImportFile(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	fileName := c.Query("fileName")
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file field"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+		body, fileName = file, header.Filename
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = formatOf(fileName)
+	}
+	var lines []bankimport.Line
+	var err error
+	switch format {
+	case model.ImportCAMT054:
+		lines, err = bankimport.ParseCAMT054(body)
+	case model.ImportCSV:
+		lines, err = bankimport.ParseCSV(body, h.csvMapping)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be camt054 or csv"})
+		return
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(lines) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File has no payments"})
+		return
+	}
+	if len(lines) > maxImportLines {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file exceeds %d payments", maxImportLines)})
+		return
+	}
+
+	var importedBy string
+	if claims, ok := server.ClaimsFromContext(c); ok {
+		importedBy = claims.Principal()
+	}
+	if fileName != "" {
+		fileName = path.Base(fileName)
+	}
+	report, err := h.importService.Import(c.Request.Context(), format, fileName, importedBy, lines)
+	if errors.Is(err, repository.ErrImportConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *importHandler) GetImport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	report, err := h.importService.GetReport(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func formatOf(fileName string) string {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".xml":
+		return model.ImportCAMT054
+	case ".csv":
+		return model.ImportCSV
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"transaction/bankimport"
+	"transaction/model"
+	"transaction/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) Import(ctx context.Context, format, fileName, importedBy string, lines []bankimport.Line) (*model.ImportReport, error) {
+	args := m.Called(ctx, format, fileName, importedBy, lines)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImportReport), args.Error(1)
+}
+
+func (m *MockImportService) GetReport(ctx context.Context, id uuid.UUID) (*model.ImportReport, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImportReport), args.Error(1)
+}
+
+func TestImportFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockImportService)
+	mapping, _ := bankimport.LoadCSVMapping("")
+	handler := NewImportHandler(mockService, mapping)
+
+	router := gin.Default()
+	router.POST("/transactions/imports", handler.ImportFile)
+	csvFile := mapping.Columns.Account + "," + mapping.Columns.Amount + "\nACC-1,10\n"
+
+	t.Run("should return 201 with the report of a multipart upload", func(t *testing.T) {
+		report := model.NewImportReport(model.BankImport{ID: uuid.New(), MatchedCount: 1}, nil)
+		mockService.On("Import", mock.Anything, model.ImportCSV, "incoming.csv", "", mock.MatchedBy(func(lines []bankimport.Line) bool {
+			return len(lines) == 1 && lines[0].AccountIdentifier == "ACC-1"
+		})).Return(report, nil).Once()
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "../incoming.csv")
+		part.Write([]byte(csvFile))
+		form.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/imports", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"matched":[]`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return 409 if the file is imported concurrently", func(t *testing.T) {
+		mockService.On("Import", mock.Anything, model.ImportCSV, "", "", mock.Anything).Return(nil, repository.ErrImportConflict).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/imports?format=csv", bytes.NewBufferString(csvFile))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("should return 400 for an unknown format or a file without payments", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/imports", bytes.NewBufferString(csvFile))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		req, _ = http.NewRequest(http.MethodPost, "/transactions/imports?format=csv", bytes.NewBufferString(mapping.Columns.Account+","+mapping.Columns.Amount+"\n"))
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestGetImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockImportService)
+	handler := NewImportHandler(mockService, nil)
+
+	router := gin.Default()
+	router.GET("/transactions/imports/:id", handler.GetImport)
+
+	id := uuid.New()
+	mockService.On("GetReport", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	req, _ := http.NewRequest(http.MethodGet, "/transactions/imports/"+id.String(), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"transaction/model"
+	"transaction/repository"
+	"transaction/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"gorm.io/gorm"
+)
+
+type scheduleHandler struct {
+	scheduleService service.ScheduleService
+	accountService  service.AccountService
+}
+
+type ScheduleHandler interface {
+	CreateSchedule(c *gin.Context)
+	ListSchedules(c *gin.Context)
+	GetSchedule(c *gin.Context)
+	CancelSchedule(c *gin.Context)
+	ListExecutions(c *gin.Context)
+}
+
+type scheduleRequest struct {
+	AccountID       uuid.UUID  `json:"accountId" binding:"required"`
+	Amount          float64    `json:"amount" binding:"required"`
+	TransactionType string     `json:"transactionType" binding:"required"`
+	Details         string     `json:"details"`
+	Counterparty    string     `json:"counterparty"`
+	Frequency       string     `json:"frequency" binding:"required"`
+	StartAt         time.Time  `json:"startAt" binding:"required"`
+	EndAt           *time.Time `json:"endAt"`
+	MaxRuns         *int       `json:"maxRuns"`
+}
+
+func NewScheduleHandler(scheduleService service.ScheduleService, accountService service.AccountService) ScheduleHandler {
+	return &scheduleHandler{
+		scheduleService: scheduleService,
+		accountService:  accountService,
+	}
+}
+
+func (h *scheduleHandler) CreateSchedule(c *gin.Context) {
+	var request scheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.accountService.GetAccountByID(c.Request.Context(), request.AccountID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	customerID := stringValue(account["CustomerID"])
+	if !server.CheckCustomerAccess(c, customerID) || !server.CheckAccountAllowed(c, request.AccountID.String()) {
+		return
+	}
+	if err := service.ApplyAccount(&model.Transaction{}, account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account is not active"})
+		return
+	}
+
+	schedule := model.Schedule{
+		AccountID:       request.AccountID,
+		CustomerID:      customerID,
+		Amount:          request.Amount,
+		TransactionType: request.TransactionType,
+		Details:         request.Details,
+		Counterparty:    request.Counterparty,
+		Frequency:       request.Frequency,
+		StartAt:         request.StartAt,
+		EndAt:           request.EndAt,
+		MaxRuns:         request.MaxRuns,
+	}
+	if schedule.StartAt.Before(time.Now().UTC().Add(-time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "startAt must not be in the past"})
+		return
+	}
+	if err := schedule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.scheduleService.CreateSchedule(c.Request.Context(), &schedule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *scheduleHandler) ListSchedules(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Query("accountId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+	if !server.CheckAccountAllowed(c, accountID.String()) {
+		return
+	}
+	if _, restricted := server.CustomerScope(c); restricted {
+		account, err := h.accountService.GetAccountByID(c.Request.Context(), accountID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		if !server.CheckCustomerAccess(c, stringValue(account["CustomerID"])) {
+			return
+		}
+	}
+
+	schedules, err := h.scheduleService.ListSchedules(c.Request.Context(), accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (h *scheduleHandler) GetSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !server.CheckCustomerAccess(c, schedule.CustomerID) || !server.CheckAccountAllowed(c, schedule.AccountID.String()) {
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *scheduleHandler) CancelSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+	if !h.checkScheduleAccess(c, id) {
+		return
+	}
+
+	schedule, err := h.scheduleService.CancelSchedule(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrScheduleNotActive) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *scheduleHandler) ListExecutions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+	if !h.checkScheduleAccess(c, id) {
+		return
+	}
+
+	executions, err := h.scheduleService.ListExecutions(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, executions)
+}
+
+// checkScheduleAccess restricts customers and account-scoped API keys to the schedules of
+// their own accounts.
+func (h *scheduleHandler) checkScheduleAccess(c *gin.Context, id uuid.UUID) bool {
+	_, customerRestricted := server.CustomerScope(c)
+	_, accountRestricted := server.AccountScope(c)
+	if !customerRestricted && !accountRestricted {
+		return true
+	}
+	schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return false
+	}
+	return server.CheckCustomerAccess(c, schedule.CustomerID) && server.CheckAccountAllowed(c, schedule.AccountID.String())
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"transaction/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) CreateSchedule(ctx context.Context, schedule *model.Schedule) error {
+	return m.Called(ctx, schedule).Error(0)
+}
+
+func (m *MockScheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error) {
+	args := m.Called(ctx, scheduleID)
+	return args.Get(0).([]model.ScheduleExecution), args.Error(1)
+}
+
+func (m *MockScheduleService) RunDue(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockScheduleService) RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return m.Called(ctx, transactionID, status, reason).Error(0)
+}
+
+func asCustomer(customerID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		server.SetClaims(c, &server.Claims{Roles: []string{server.RoleCustomer}, CustomerID: customerID})
+		c.Next()
+	}
+}
+
+func TestCreateSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockScheduleService := new(MockScheduleService)
+	mockAccountService := new(MockAccountService)
+	handler := NewScheduleHandler(mockScheduleService, mockAccountService)
+
+	router := gin.Default()
+	router.Use(asCustomer("customer-1"))
+	router.POST("/transactions/schedules", handler.CreateSchedule)
+
+	accountID := uuid.New()
+	startAt := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	body := `{"accountId":"` + accountID.String() + `","amount":25,"transactionType":"debit","frequency":"monthly","startAt":"` + startAt + `","maxRuns":12}`
+
+	t.Run("should return 201 for the customer's own account", func(t *testing.T) {
+		mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(map[string]interface{}{"Status": "active", "CustomerID": "customer-1"}, nil).Once()
+		mockScheduleService.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedule *model.Schedule) bool {
+			return schedule.CustomerID == "customer-1" && *schedule.MaxRuns == 12
+		})).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/schedules", bytes.NewBufferString(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		mockScheduleService.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 for another customer's account", func(t *testing.T) {
+		mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(map[string]interface{}{"Status": "active", "CustomerID": "customer-2"}, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/transactions/schedules", bytes.NewBufferString(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+}
+
+func TestCancelSchedule_OtherCustomer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockScheduleService := new(MockScheduleService)
+	handler := NewScheduleHandler(mockScheduleService, nil)
+
+	router := gin.Default()
+	router.Use(asCustomer("customer-1"))
+	router.DELETE("/transactions/schedules/:id", handler.CancelSchedule)
+
+	id := uuid.New()
+	mockScheduleService.On("GetSchedule", mock.Anything, id).Return(&model.Schedule{ID: id, CustomerID: "customer-2"}, nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/transactions/schedules/"+id.String(), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	mockScheduleService.AssertNotCalled(t, "CancelSchedule", mock.Anything, id)
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/segmentio/kafka-go"
 	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+	"github.com/shrishyam02/banking-ledger/common/server"
 )
 
 type transactionHandler struct {
@@ -47,7 +49,14 @@ func (h *transactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	if status, ok := account["Status"].(string); !ok || status != "active" {
+	if !server.CheckCustomerAccess(c, stringValue(account["CustomerID"])) || !server.CheckAccountAllowed(c, transaction.AccountID.String()) {
+		return
+	}
+
+	// Only batches and schedules tag their transactions; a single payment must not pass for one
+	transaction.BatchID = nil
+	transaction.ScheduleID = nil
+	if err := service.ApplyAccount(&transaction, account); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Account is not active"})
 		return
 	}
@@ -57,7 +66,7 @@ func (h *transactionHandler) CreateTransaction(c *gin.Context) {
 
 	transactionBytes, err := json.Marshal(transaction)
 	if err != nil {
-		logger.Log.Error().Msgf("Failed to marshal json message. err: %v", err)
+		logger.Ctx(c.Request.Context()).Error().Msgf("Failed to marshal json message. err: %v", err)
 		return
 	}
 
@@ -65,14 +74,21 @@ func (h *transactionHandler) CreateTransaction(c *gin.Context) {
 		Key:   []byte(transaction.ID.String()),
 		Value: transactionBytes,
 	}
-	ctx := context.Background()
+	// Keep the request's trace but finish publishing even if the client goes away
+	ctx := context.WithoutCancel(c.Request.Context())
 	kerr := h.kafkaProducer.Produce(ctx, h.producerTopics[0], message)
 
 	if kerr != nil {
-		logger.Log.Error().Msgf("Failed to publish message to kafka. err: %v", kerr)
+		logger.Ctx(c.Request.Context()).Error().Msgf("Failed to publish message to kafka. err: %v", kerr)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process transaction"})
 		return
 	}
+	metrics.RecordTransaction(metrics.TransactionAccepted, transaction.TransactionType)
 
 	c.JSON(http.StatusCreated, transaction)
 }
+
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
@@ -32,6 +32,15 @@ func (m *MockKafkaWriter) Produce(ctx context.Context, topic string, msg kafka.M
 	return args.Error(0)
 }
 
+func (m *MockKafkaWriter) ProduceBatch(ctx context.Context, topic string, msgs []kafka.Message) error {
+	args := m.Called(ctx, topic, msgs)
+	return args.Error(0)
+}
+
+func (m *MockKafkaWriter) Close() error {
+	return nil
+}
+
 type MockAccountService struct {
 	mock.Mock
 }
@@ -41,6 +50,14 @@ func (m *MockAccountService) GetAccountByID(ctx context.Context, accountID uuid.
 	return args.Get(0).(map[string]interface{}), args.Error(1)
 }
 
+func (m *MockAccountService) FindAccountByNumber(ctx context.Context, number string) (map[string]interface{}, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
 func TestCreateTransaction(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -114,3 +131,35 @@ func TestCreateTransaction(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, resp.Code)
 	})
 }
+
+func TestCreateTransaction_IgnoresBatchAndScheduleTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockKafkaWriter := new(MockKafkaWriter)
+	mockAccountService := new(MockAccountService)
+	handler := NewTransactionHandler(mockKafkaWriter, []string{"topic1"}, mockAccountService)
+
+	router := gin.Default()
+	router.POST("/transactions", handler.CreateTransaction)
+
+	accountID := uuid.New()
+	body := `{"accountId":"` + accountID.String() + `","amount":25,"transactionType":"debit",` +
+		`"batchId":"` + uuid.NewString() + `","scheduleId":"` + uuid.NewString() + `","accountCreatedAt":"2000-01-01T00:00:00Z"}`
+
+	mockAccountService.On("GetAccountByID", mock.Anything, accountID).Return(map[string]interface{}{"Status": "active"}, nil)
+	mockKafkaWriter.On("Produce", mock.Anything, "topic1", mock.MatchedBy(func(message kafka.Message) bool {
+		var published map[string]interface{}
+		_ = json.Unmarshal(message.Value, &published)
+		_, batch := published["batchId"]
+		_, schedule := published["scheduleId"]
+		_, createdAt := published["accountCreatedAt"]
+		return !batch && !schedule && !createdAt
+	})).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(body))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	mockKafkaWriter.AssertExpectations(t)
+}
@@ -0,0 +1,177 @@
+package bankimport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"transaction/model"
+)
+
+type camtDocument struct {
+	Notifications []camtNotification `xml:"BkToCstmrDbtCdtNtfctn>Ntfctn"`
+}
+
+type camtNotification struct {
+	ID      string      `xml:"Id"`
+	Account camtAccount `xml:"Acct"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+func (a camtAccount) identifier() string {
+	if a.IBAN != "" {
+		return a.IBAN
+	}
+	return a.Other
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtEntry struct {
+	Reference     string          `xml:"NtryRef"`
+	Amount        camtAmount      `xml:"Amt"`
+	Indicator     string          `xml:"CdtDbtInd"`
+	Status        camtStatus      `xml:"Sts"`
+	BookingDate   camtDate        `xml:"BookgDt"`
+	ServicerRef   string          `xml:"AcctSvcrRef"`
+	Transactions  []camtTxDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalInf string          `xml:"AddtlNtryInf"`
+}
+
+// camtStatus is a code element from version 8 on and plain text before.
+type camtStatus struct {
+	Code string `xml:"Cd"`
+	Text string `xml:",chardata"`
+}
+
+func (s camtStatus) String() string {
+	return firstOf(s.Code, s.Text)
+}
+
+type camtTxDetails struct {
+	ServicerRef     string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID      string      `xml:"Refs>EndToEndId"`
+	TransactionID   string      `xml:"Refs>TxId"`
+	Amount          *camtAmount `xml:"Amt"`
+	Indicator       string      `xml:"CdtDbtInd"`
+	DebtorName      string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPartyName string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	CreditorIBAN    string      `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+	CreditorOther   string      `xml:"RltdPties>CdtrAcct>Id>Othr>Id"`
+	Unstructured    []string    `xml:"RmtInf>Ustrd"`
+	AdditionalInfo  string      `xml:"AddtlTxInf"`
+}
+
+// ParseCAMT054 reads an ISO 20022 BankToCustomerDebitCreditNotification. Every transaction
+// detail of an entry is a line, numbered in file order; entries without details are a line of
+// their own. Payments are credited to the creditor account of the transaction, or else to the
+// account of the notification.
+func ParseCAMT054(r io.Reader) ([]Line, error) {
+	var document camtDocument
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid camt.054 document: %w", err)
+	}
+
+	var lines []Line
+	for _, notification := range document.Notifications {
+		for _, entry := range notification.Entries {
+			details := entry.Transactions
+			if len(details) == 0 {
+				details = []camtTxDetails{{}}
+			}
+			for i, detail := range details {
+				line := entryLine(notification, entry, detail, i, len(details))
+				line.Number = len(lines) + 1
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines, nil
+}
+
+func entryLine(notification camtNotification, entry camtEntry, detail camtTxDetails, index, count int) Line {
+	line := Line{
+		AccountIdentifier: notification.Account.identifier(),
+		Counterparty:      firstOf(detail.DebtorName, detail.DebtorPartyName),
+		Details:           firstOf(strings.Join(detail.Unstructured, " "), detail.AdditionalInfo, entry.AdditionalInf),
+	}
+	if creditor := firstOf(detail.CreditorIBAN, detail.CreditorOther); creditor != "" {
+		line.AccountIdentifier = creditor
+	}
+
+	// The bank's reference of a batch booked entry covers all its transactions
+	entryRef := entry.ServicerRef
+	if entryRef != "" && count > 1 {
+		entryRef += "/" + strconv.Itoa(index+1)
+	}
+	line.Reference = firstOf(detail.ServicerRef, entryRef, detail.EndToEndID, detail.TransactionID)
+	if line.Reference == "" && count == 1 {
+		line.Reference = entry.Reference
+	}
+
+	amount := entry.Amount
+	if detail.Amount != nil {
+		amount = *detail.Amount
+	}
+	line.Currency = firstOf(amount.Currency, notification.Account.Currency)
+	indicator := firstOf(detail.Indicator, entry.Indicator)
+	line.Key = idempotencyKey(model.ImportCAMT054, line.Reference,
+		notification.ID, entry.Reference, strconv.Itoa(index), line.AccountIdentifier, amount.Value, indicator, line.Details)
+
+	if bookedAt, ok := entry.BookingDate.parse(); ok {
+		line.BookedAt = &bookedAt
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 64)
+	switch {
+	case err != nil:
+		line.Err = fmt.Errorf("invalid amount %q", amount.Value)
+	case indicator != "CRDT":
+		line.Err = ErrNotCredit
+	case entry.Status.String() != "BOOK":
+		line.Err = ErrNotBooked
+	}
+	line.Amount = value
+	return line
+}
+
+func (d camtDate) parse() (time.Time, bool) {
+	if d.DateTime != "" {
+		if t, err := time.Parse(time.RFC3339, d.DateTime); err == nil {
+			return t.UTC(), true
+		}
+		if t, err := time.Parse("2006-01-02T15:04:05", d.DateTime); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.Parse(time.DateOnly, d.Date); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package bankimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const camt054Sample = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.054.001.08">
+  <BkToCstmrDbtCdtNtfctn>
+    <Ntfctn>
+      <Id>NTF-1</Id>
+      <Acct><Id><IBAN>DE44500105175407324931</IBAN></Id><Ccy>USD</Ccy></Acct>
+      <Ntry>
+        <NtryRef>1</NtryRef>
+        <Amt Ccy="USD">120.50</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2026-10-01</Dt></BookgDt>
+        <AcctSvcrRef>BANK-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>E2E-1</EndToEndId></Refs>
+          <RltdPties><Dbtr><Nm>ACME Corp</Nm></Dbtr></RltdPties>
+          <RmtInf><Ustrd>Invoice 42</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <NtryRef>2</NtryRef>
+        <Amt Ccy="USD">30.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2026-10-01</Dt></BookgDt>
+        <AcctSvcrRef>BANK-2</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Amt Ccy="USD">10.00</Amt>
+            <RltdPties><CdtrAcct><Id><Othr><Id>ACC-1</Id></Othr></Id></CdtrAcct></RltdPties>
+          </TxDtls>
+          <TxDtls>
+            <Amt Ccy="USD">20.00</Amt>
+            <RltdPties><CdtrAcct><Id><Othr><Id>ACC-2</Id></Othr></Id></CdtrAcct></RltdPties>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <NtryRef>3</NtryRef>
+        <Amt Ccy="USD">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+      </Ntry>
+      <Ntry>
+        <NtryRef>4</NtryRef>
+        <Amt Ccy="USD">7.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+      </Ntry>
+    </Ntfctn>
+  </BkToCstmrDbtCdtNtfctn>
+</Document>`
+
+func TestParseCAMT054(t *testing.T) {
+	lines, err := ParseCAMT054(strings.NewReader(camt054Sample))
+	assert.NoError(t, err)
+	assert.Len(t, lines, 5)
+
+	assert.Equal(t, 1, lines[0].Number)
+	assert.Equal(t, "camt054:BANK-1", lines[0].Key)
+	assert.Equal(t, "DE44500105175407324931", lines[0].AccountIdentifier)
+	assert.Equal(t, 120.50, lines[0].Amount)
+	assert.Equal(t, "USD", lines[0].Currency)
+	assert.Equal(t, "ACME Corp", lines[0].Counterparty)
+	assert.Equal(t, "Invoice 42", lines[0].Details)
+	assert.Equal(t, "2026-10-01", lines[0].BookedAt.Format("2006-01-02"))
+	assert.NoError(t, lines[0].Err)
+
+	assert.Equal(t, "BANK-2/1", lines[1].Reference)
+	assert.Equal(t, "ACC-1", lines[1].AccountIdentifier)
+	assert.Equal(t, 10.0, lines[1].Amount)
+	assert.Equal(t, "BANK-2/2", lines[2].Reference)
+	assert.Equal(t, 20.0, lines[2].Amount)
+
+	assert.ErrorIs(t, lines[3].Err, ErrNotCredit)
+	assert.ErrorIs(t, lines[4].Err, ErrNotBooked)
+	assert.Equal(t, "camt054:4", lines[4].Key)
+}
+
+func TestParseCAMT054_Invalid(t *testing.T) {
+	_, err := ParseCAMT054(strings.NewReader("<Document><BkToCstmrDbtCdtNtfctn>"))
+	assert.Error(t, err)
+}
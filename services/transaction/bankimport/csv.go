@@ -0,0 +1,145 @@
+package bankimport
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"transaction/model"
+)
+
+//go:embed csv_mapping.json
+var defaultCSVMapping []byte
+
+// CSVMapping names the columns of the clearing partner's CSV files. The first row of a file is
+// its header; only the account and amount columns are required.
+type CSVMapping struct {
+	Delimiter string     `json:"delimiter"`
+	Columns   CSVColumns `json:"columns"`
+	// DateFormat is a Go time layout
+	DateFormat string `json:"dateFormat"`
+	// DecimalComma reads amounts written as 1.234,56
+	DecimalComma bool `json:"decimalComma"`
+}
+
+// CSVColumns maps line fields to header names.
+type CSVColumns struct {
+	Account      string `json:"account"`
+	Amount       string `json:"amount"`
+	Currency     string `json:"currency"`
+	Date         string `json:"date"`
+	Reference    string `json:"reference"`
+	Counterparty string `json:"counterparty"`
+	Details      string `json:"details"`
+}
+
+// LoadCSVMapping reads the mapping file, falling back to the embedded default when path is empty.
+func LoadCSVMapping(path string) (*CSVMapping, error) {
+	data := defaultCSVMapping
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var mapping CSVMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, err
+	}
+	if mapping.Columns.Account == "" || mapping.Columns.Amount == "" {
+		return nil, errors.New("csv mapping must name the account and amount columns")
+	}
+	if utf8.RuneCountInString(mapping.Delimiter) > 1 {
+		return nil, fmt.Errorf("csv mapping delimiter %q is not a single character", mapping.Delimiter)
+	}
+	return &mapping, nil
+}
+
+// ParseCSV reads a CSV file with mapping. Lines are numbered as in the file, header included.
+// Negative amounts are debits.
+func ParseCSV(r io.Reader, mapping *CSVMapping) ([]Line, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv file: %w", err)
+	}
+	// Spreadsheet exports may start with a byte order mark
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{mapping.Columns.Account, mapping.Columns.Amount} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv file has no %q column", required)
+		}
+	}
+
+	var lines []Line
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv file: %w", err)
+		}
+		number, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && name != "" && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		line := Line{
+			Number:            number,
+			Reference:         field(mapping.Columns.Reference),
+			AccountIdentifier: field(mapping.Columns.Account),
+			Currency:          field(mapping.Columns.Currency),
+			Counterparty:      field(mapping.Columns.Counterparty),
+			Details:           field(mapping.Columns.Details),
+		}
+		// Without a reference, identical payments in one file are told apart by their position
+		line.Key = idempotencyKey(model.ImportCSV, line.Reference, append([]string{strconv.Itoa(number)}, record...)...)
+
+		if value := field(mapping.Columns.Date); value != "" {
+			if bookedAt, err := time.Parse(mapping.DateFormat, value); err == nil {
+				line.BookedAt = &bookedAt
+			} else {
+				line.Err = fmt.Errorf("invalid date %q", value)
+			}
+		}
+
+		value := field(mapping.Columns.Amount)
+		if mapping.DecimalComma {
+			value = strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		switch {
+		case err != nil:
+			line.Err = fmt.Errorf("invalid amount %q", field(mapping.Columns.Amount))
+		case amount < 0:
+			line.Err = ErrNotCredit
+		}
+		line.Amount = amount
+		lines = append(lines, line)
+	}
+}
@@ -0,0 +1,67 @@
+package bankimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	mapping, err := LoadCSVMapping("")
+	assert.NoError(t, err)
+
+	file := "\ufeff" + strings.Join([]string{
+		strings.Join([]string{mapping.Columns.Account, mapping.Columns.Amount, mapping.Columns.Reference}, ","),
+		"DE44 5001 0517 5407 3249 31,100.25,R1",
+		"ACC-2,-5,R2",
+		"ACC-3,abc,",
+	}, "\n")
+	lines, err := ParseCSV(strings.NewReader(file), mapping)
+	assert.NoError(t, err)
+	assert.Len(t, lines, 3)
+
+	assert.Equal(t, 2, lines[0].Number)
+	assert.Equal(t, "csv:R1", lines[0].Key)
+	assert.Equal(t, 100.25, lines[0].Amount)
+	assert.Equal(t, "DE44500105175407324931", NormalizeAccount(lines[0].AccountIdentifier))
+	assert.ErrorIs(t, lines[1].Err, ErrNotCredit)
+	assert.EqualError(t, lines[2].Err, `invalid amount "abc"`)
+	assert.True(t, strings.HasPrefix(lines[2].Key, "csv:sha256:"))
+}
+
+func TestParseCSV_IdenticalLinesWithoutReference(t *testing.T) {
+	mapping, _ := LoadCSVMapping("")
+	header := strings.Join([]string{mapping.Columns.Account, mapping.Columns.Amount}, ",")
+	file := header + "\nACC-1,10\nACC-1,10\n"
+
+	lines, err := ParseCSV(strings.NewReader(file), mapping)
+	assert.NoError(t, err)
+	assert.Len(t, lines, 2)
+	assert.NotEqual(t, lines[0].Key, lines[1].Key)
+
+	// The same file imported again yields the same keys
+	again, _ := ParseCSV(strings.NewReader(file), mapping)
+	assert.Equal(t, lines[0].Key, again[0].Key)
+	assert.Equal(t, lines[1].Key, again[1].Key)
+}
+
+func TestParseCSV_DecimalComma(t *testing.T) {
+	mapping := &CSVMapping{
+		Delimiter:    ";",
+		Columns:      CSVColumns{Account: "IBAN", Amount: "Betrag", Date: "Buchungstag"},
+		DateFormat:   "02.01.2006",
+		DecimalComma: true,
+	}
+	file := "IBAN;Betrag;Buchungstag\nDE44500105175407324931;1.234,56;01.10.2026\n"
+	lines, err := ParseCSV(strings.NewReader(file), mapping)
+	assert.NoError(t, err)
+	assert.Equal(t, 1234.56, lines[0].Amount)
+	assert.Equal(t, "2026-10-01", lines[0].BookedAt.Format("2006-01-02"))
+}
+
+func TestParseCSV_MissingColumn(t *testing.T) {
+	mapping, _ := LoadCSVMapping("")
+	_, err := ParseCSV(strings.NewReader("foo,bar\n1,2\n"), mapping)
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+// Package bankimport parses the incoming payment files received from the clearing partner into
+// payment lines.
+package bankimport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotCredit = errors.New("only credits are imported")
+	ErrNotBooked = errors.New("entry is not booked")
+)
+
+// Line is one payment of a file. Err is set when the payment cannot be imported; the line is
+// still returned so it appears in the import report.
+type Line struct {
+	Number int
+	// Key identifies the payment across imports of the same file
+	Key               string
+	Reference         string
+	AccountIdentifier string
+	Amount            float64
+	Currency          string
+	BookedAt          *time.Time
+	Counterparty      string
+	Details           string
+	Err               error
+}
+
+// idempotencyKey uses the bank's reference for the payment when the file carries one, and the
+// content of the line otherwise.
+func idempotencyKey(format, reference string, content ...string) string {
+	if reference != "" {
+		return format + ":" + reference
+	}
+	sum := sha256.Sum256([]byte(strings.Join(content, "\x1f")))
+	return format + ":sha256:" + hex.EncodeToString(sum[:])
+}
+
+// NormalizeAccount strips the spaces IBANs are printed with.
+func NormalizeAccount(identifier string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(identifier), " ", ""))
+}
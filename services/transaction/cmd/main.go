@@ -5,27 +5,49 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"transaction/api"
+	"transaction/bankimport"
+	"transaction/processor"
+	"transaction/repository"
+	"transaction/scheduler"
 	"transaction/service"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/shrishyam02/banking-ledger/common/apikey"
+	"github.com/shrishyam02/banking-ledger/common/app"
+	"github.com/shrishyam02/banking-ledger/common/audit"
+	"github.com/shrishyam02/banking-ledger/common/auth"
 	"github.com/shrishyam02/banking-ledger/common/config"
+	"github.com/shrishyam02/banking-ledger/common/db"
 	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
 	"github.com/shrishyam02/banking-ledger/common/logger"
 	"github.com/shrishyam02/banking-ledger/common/server"
+	"github.com/shrishyam02/banking-ledger/common/tracing"
 )
 
 func main() {
-	logger.InitLogger()
-
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	logger.InitLogger(logger.Options{
+		Service: config.TransactionService,
+		Version: cfg.Logging.Version,
+		Level:   cfg.Services[config.TransactionService].LogLevel,
+		Format:  cfg.Logging.Format,
+	})
 	logger.Log.Info().Msg("Intialized logger for service: " + config.TransactionService)
 
+	shutdownTracing, err := tracing.Init(context.Background(), config.TransactionService, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	lifecycle := app.NewLifecycle(config.TransactionService, cfg.Lifecycle.ShutdownTimeout)
+	lifecycle.OnStop("tracing", shutdownTracing)
+
 	// Create Kafka topic
 	brokers := strings.Split(cfg.Kafka.Brokers, ",")
 	producerTopics := []string{"transactions-topic"}
@@ -39,28 +61,133 @@ func main() {
 		logger.Log.Info().Msgf("kafka broker string slice %s", brokers)
 	}
 	producer := ckafka.NewKafkaProducer(brokers)
+	lifecycle.OnStop("kafka producer", func(context.Context) error { return producer.Close() })
+
+	// Transaction statuses are followed to record the outcome of scheduled runs
+	statusTopic := "ledger-topic"
+	consumerGroup := "transaction-service-group"
+	if kerr := ckafka.CreateKafkaTopic(brokers[0], statusTopic); kerr != nil {
+		logger.Log.Fatal().AnErr("Failed to create Kafka topic", kerr).Msg(statusTopic)
+	}
+	health := server.NewHealth()
+	consumer := ckafka.NewKafkaConsumer(brokers, consumerGroup, []string{statusTopic},
+		ckafka.WithHeartbeat(health.Heartbeat("status-consumer", time.Minute)))
+	lifecycle.OnStop("kafka consumer", func(context.Context) error { return consumer.Close() })
+
+	pgDb, err := db.ConnectPostgres(cfg.Database.PostgresConnectionString)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	logger.Log.Info().Msg("Connected to postgres: " + config.TransactionService)
+	if err := db.CheckSchemaVersion(context.Background(), pgDb); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Database schema is older than this build; run `account migrate up`")
+	}
+	lifecycle.OnStop("postgres", func(context.Context) error { return db.ClosePostgres(pgDb) })
+	health.AddReadinessCheck("postgres", db.PostgresHealthCheck(pgDb))
+	health.AddReadinessCheck("kafka", ckafka.BrokerHealthCheck(brokers))
 
 	accountServiceURL := os.Getenv("ACCOUNT_SERVICE_URL")
 	if accountServiceURL == "" {
 		log.Fatal("ACCOUNT_SERVICE_URL environment variable is required")
 	}
-	accountService := service.NewAccountService(accountServiceURL)
+	accountService := service.NewAccountService(accountServiceURL, auth.NewClient(context.Background(), cfg.Auth, cfg.ApiAuth))
 	transactionHandler := api.NewTransactionHandler(producer, producerTopics, accountService)
 
+	schedulePollInterval, err := time.ParseDuration(getEnv("SCHEDULE_POLL_INTERVAL", "30s"))
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Invalid SCHEDULE_POLL_INTERVAL")
+	}
+	scheduleService := service.NewScheduleService(repository.NewScheduleRepository(pgDb), accountService, producer, producerTopics[0])
+	scheduleHandler := api.NewScheduleHandler(scheduleService, accountService)
+	batchService := service.NewBatchService(repository.NewBatchRepository(pgDb), accountService, producer, producerTopics[0])
+	batchHandler := api.NewBatchHandler(batchService)
+	// Incoming payment files from the clearing partner are credited in the ledger currency
+	csvMapping, err := bankimport.LoadCSVMapping(os.Getenv("BANK_IMPORT_CSV_MAPPING_FILE"))
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to load bank import CSV mapping")
+	}
+	importService := service.NewImportService(repository.NewImportRepository(pgDb), accountService, producer, producerTopics[0], getEnv("BANK_IMPORT_CURRENCY", "USD"))
+	importHandler := api.NewImportHandler(importService, csvMapping)
+	statusProcessor := processor.NewStatusProcessor(consumer, statusTopic, consumerGroup, scheduleService, batchService)
+
+	scheduleScheduler := scheduler.NewScheduleScheduler(scheduleService, schedulePollInterval)
+	lifecycle.Go("schedule scheduler", func(ctx context.Context) error {
+		scheduleScheduler.Run(ctx)
+		return nil
+	})
+	lifecycle.Go("status consumer", statusProcessor.ProcessStatuses)
+
 	registerHandlers := func(apiGroup *gin.RouterGroup) {
 		accounts := apiGroup.Group("/transactions")
 		{
 			accounts.POST("", transactionHandler.CreateTransaction)
 		}
+		schedules := apiGroup.Group("/transactions/schedules")
+		{
+			schedules.POST("", scheduleHandler.CreateSchedule)
+			schedules.GET("", scheduleHandler.ListSchedules)
+			schedules.GET("/:id", scheduleHandler.GetSchedule)
+			schedules.DELETE("/:id", scheduleHandler.CancelSchedule)
+			schedules.GET("/:id/executions", scheduleHandler.ListExecutions)
+		}
+		batches := apiGroup.Group("/transactions/batch")
+		{
+			batches.POST("", batchHandler.SubmitBatch)
+			batches.GET("/:id", batchHandler.GetBatch)
+			batches.GET("/:id/items", batchHandler.ListBatchItems)
+		}
+		imports := apiGroup.Group("/transactions/imports")
+		{
+			imports.POST("", importHandler.ImportFile)
+			imports.GET("/:id", importHandler.GetImport)
+		}
 	}
 	logger.Log.Info().Msg("Handlers for: " + config.TransactionService)
 
 	serverConfig := server.Config{
-		Port:        cfg.Services[config.TransactionService].Port,
-		ServiceName: config.TransactionService,
-		ApiAuth:     cfg.ApiAuth,
+		Port:            cfg.Services[config.TransactionService].Port,
+		ServiceName:     config.TransactionService,
+		ApiAuth:         cfg.ApiAuth,
+		Auth:            cfg.Auth,
+		RateLimit:       cfg.RateLimit,
+		Health:          health,
+		ShutdownTimeout: cfg.Lifecycle.ShutdownTimeout,
+		Permissions: server.RoutePermissions{
+			"POST /api/v1/transactions":                         server.PermTransactionsCreate,
+			"POST /api/v1/transactions/schedules":               server.PermTransactionsCreate,
+			"GET /api/v1/transactions/schedules":                server.PermTransactionsRead,
+			"GET /api/v1/transactions/schedules/:id":            server.PermTransactionsRead,
+			"DELETE /api/v1/transactions/schedules/:id":         server.PermTransactionsCreate,
+			"GET /api/v1/transactions/schedules/:id/executions": server.PermTransactionsRead,
+			"POST /api/v1/transactions/batch":                   server.PermTransactionsBatch,
+			"GET /api/v1/transactions/batch/:id":                server.PermTransactionsBatch,
+			"GET /api/v1/transactions/batch/:id/items":          server.PermTransactionsBatch,
+			"POST /api/v1/transactions/imports":                 server.PermTransactionsImport,
+			"GET /api/v1/transactions/imports/:id":              server.PermTransactionsImport,
+		},
+		// Submissions feed transactions-topic and get tighter limits than reads
+		RouteRateLimits: server.RouteRateLimits{
+			"POST /api/v1/transactions":           {Rate: 20, Burst: 40},
+			"POST /api/v1/transactions/schedules": {Rate: 1, Burst: 10},
+			"POST /api/v1/transactions/batch":     {Rate: 0.1, Burst: 3},
+			"POST /api/v1/transactions/imports":   {Rate: 0.1, Burst: 3},
+		},
+		APIKeys: apikey.NewService(apikey.NewRepository(pgDb)),
+		Audit:   audit.NewLog(pgDb, config.TransactionService),
+	}
+
+	lifecycle.Go("http server", func(ctx context.Context) error {
+		server.RunServer(ctx, serverConfig, registerHandlers)
+		return nil
+	})
+	if err := lifecycle.Wait(); err != nil {
+		logger.Log.Fatal().Err(err).Msg("Unclean shutdown")
 	}
+}
 
-	ctx := context.Background()
-	server.RunServer(ctx, serverConfig, registerHandlers)
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
 }
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bank file formats.
+const (
+	ImportCAMT054 = "camt054"
+	ImportCSV     = "csv"
+)
+
+// Import line statuses. Matched lines are submitted as credits; failed lines were matched but
+// could not be published and are submitted again when the file is imported again.
+const (
+	LineMatched   = "matched"
+	LineUnmatched = "unmatched"
+	LineDuplicate = "duplicate"
+	LineRejected  = "rejected"
+	LineFailed    = "failed"
+)
+
+// BankImport is an incoming payment file received from the clearing partner.
+type BankImport struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	FileName       string    `json:"fileName,omitempty" gorm:"type:varchar(255)"`
+	Format         string    `json:"format" gorm:"type:varchar(20);not null"`
+	LineCount      int       `json:"lineCount" gorm:"not null"`
+	MatchedCount   int       `json:"matchedCount" gorm:"not null"`
+	UnmatchedCount int       `json:"unmatchedCount" gorm:"not null"`
+	DuplicateCount int       `json:"duplicateCount" gorm:"not null"`
+	RejectedCount  int       `json:"rejectedCount" gorm:"not null"`
+	ImportedBy     string    `json:"importedBy,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"type:timestamp with time zone"`
+}
+
+// BankImportLine is one payment of an imported file. IdempotencyKey identifies the payment
+// across imports of the same file, and so does TransactionID, which only the matched line of a
+// payment holds uniquely: a line whose publish failed keeps it when the file is imported again.
+type BankImportLine struct {
+	ImportID          uuid.UUID  `json:"importId" gorm:"type:uuid;primaryKey"`
+	Line              int        `json:"line" gorm:"primaryKey"`
+	IdempotencyKey    string     `json:"idempotencyKey" gorm:"type:varchar(255);not null"`
+	Reference         string     `json:"reference,omitempty" gorm:"type:varchar(255)"`
+	AccountIdentifier string     `json:"accountIdentifier,omitempty" gorm:"type:varchar(64)"`
+	AccountID         *uuid.UUID `json:"accountId,omitempty" gorm:"type:uuid"`
+	Amount            float64    `json:"amount" gorm:"type:decimal(19,4)"`
+	Currency          string     `json:"currency,omitempty" gorm:"type:varchar(3)"`
+	BookedAt          *time.Time `json:"bookedAt,omitempty" gorm:"type:timestamp with time zone"`
+	TransactionID     *uuid.UUID `json:"transactionId,omitempty" gorm:"type:uuid;uniqueIndex:idx_bank_import_lines_matched_transaction,where:status = 'matched'"`
+	Status            string     `json:"status" gorm:"type:varchar(20);not null"`
+	Reason            string     `json:"reason,omitempty" gorm:"type:text"`
+}
+
+// ImportReport is an import with its lines grouped by status.
+type ImportReport struct {
+	BankImport
+	Matched    []BankImportLine `json:"matched"`
+	Unmatched  []BankImportLine `json:"unmatched"`
+	Duplicates []BankImportLine `json:"duplicates"`
+	Rejected   []BankImportLine `json:"rejected"`
+	Failed     []BankImportLine `json:"failed"`
+}
+
+// NewImportReport groups the lines of an import by status.
+func NewImportReport(bankImport BankImport, lines []BankImportLine) *ImportReport {
+	report := &ImportReport{
+		BankImport: bankImport,
+		Matched:    []BankImportLine{},
+		Unmatched:  []BankImportLine{},
+		Duplicates: []BankImportLine{},
+		Rejected:   []BankImportLine{},
+		Failed:     []BankImportLine{},
+	}
+	for _, line := range lines {
+		switch line.Status {
+		case LineMatched:
+			report.Matched = append(report.Matched, line)
+		case LineUnmatched:
+			report.Unmatched = append(report.Unmatched, line)
+		case LineDuplicate:
+			report.Duplicates = append(report.Duplicates, line)
+		case LineRejected:
+			report.Rejected = append(report.Rejected, line)
+		case LineFailed:
+			report.Failed = append(report.Failed, line)
+		}
+	}
+	return report
+}
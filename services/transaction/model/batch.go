@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Batch modes. In all-or-nothing mode the batch is only published when every line is valid.
+const (
+	BatchModePartial      = "partial"
+	BatchModeAllOrNothing = "all_or_nothing"
+)
+
+// Batch statuses.
+const (
+	BatchAccepted = "accepted"
+	BatchRejected = "rejected"
+)
+
+// Batch item statuses. Submitted items take the status the transaction reaches in the
+// processing pipeline.
+const (
+	ItemRejected      = "rejected"
+	ItemSubmitted     = "submitted"
+	ItemPendingReview = "pending_review"
+	ItemSuccess       = "success"
+	ItemFailed        = "failed"
+)
+
+// ReasonBatchRejected is recorded on valid lines of a rejected all-or-nothing batch.
+const ReasonBatchRejected = "another line of the batch is invalid"
+
+// Batch is a set of transactions submitted together.
+type Batch struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Mode          string    `json:"mode" gorm:"type:varchar(20);not null"`
+	Status        string    `json:"status" gorm:"type:varchar(20);not null"`
+	ItemCount     int       `json:"itemCount" gorm:"not null"`
+	AcceptedCount int       `json:"acceptedCount" gorm:"not null"`
+	RejectedCount int       `json:"rejectedCount" gorm:"not null"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"type:timestamp with time zone"`
+}
+
+// BatchItem is one line of a batch.
+type BatchItem struct {
+	BatchID       uuid.UUID  `json:"batchId" gorm:"type:uuid;primaryKey"`
+	Line          int        `json:"line" gorm:"primaryKey"`
+	TransactionID *uuid.UUID `json:"transactionId,omitempty" gorm:"type:uuid;uniqueIndex"`
+	AccountID     *uuid.UUID `json:"accountId,omitempty" gorm:"type:uuid"`
+	Amount        float64    `json:"amount" gorm:"type:decimal(19,4)"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null"`
+	Reason        string     `json:"reason,omitempty" gorm:"type:text"`
+	UpdatedAt     time.Time  `json:"updatedAt" gorm:"type:timestamp with time zone"`
+}
+
+// BatchSummary is a batch with its items counted by status.
+type BatchSummary struct {
+	Batch
+	Counts     map[string]int `json:"counts"`
+	Processing bool           `json:"processing"`
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schedule frequencies.
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Schedule statuses.
+const (
+	ScheduleActive    = "active"
+	ScheduleCompleted = "completed"
+	ScheduleCancelled = "cancelled"
+)
+
+// Execution statuses. Submitted executions take the status the transaction reaches in
+// the processing pipeline; skipped runs were missed while a later run was already due.
+const (
+	ExecutionSubmitted     = "submitted"
+	ExecutionPendingReview = "pending_review"
+	ExecutionSuccess       = "success"
+	ExecutionFailed        = "failed"
+	ExecutionSkipped       = "skipped"
+)
+
+// Schedule is a future-dated or recurring transaction.
+type Schedule struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AccountID       uuid.UUID  `json:"accountId" gorm:"type:uuid;not null;index"`
+	CustomerID      string     `json:"customerId" gorm:"type:varchar(64)"`
+	Amount          float64    `json:"amount" gorm:"type:decimal(19,4);not null"`
+	TransactionType string     `json:"transactionType" gorm:"type:varchar(20);not null"`
+	Details         string     `json:"details" gorm:"type:text"`
+	Counterparty    string     `json:"counterparty,omitempty" gorm:"type:varchar(64)"`
+	Frequency       string     `json:"frequency" gorm:"type:varchar(10);not null"`
+	StartAt         time.Time  `json:"startAt" gorm:"type:timestamp with time zone;not null"`
+	EndAt           *time.Time `json:"endAt,omitempty" gorm:"type:timestamp with time zone"`
+	MaxRuns         *int       `json:"maxRuns,omitempty"`
+	RunCount        int        `json:"runCount" gorm:"not null;default:0"`
+	NextRunAt       *time.Time `json:"nextRunAt,omitempty" gorm:"type:timestamp with time zone"`
+	Status          string     `json:"status" gorm:"type:varchar(20);not null"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"type:timestamp with time zone"`
+	UpdatedAt       time.Time  `json:"updatedAt" gorm:"type:timestamp with time zone"`
+}
+
+// ScheduleExecution is one run of a schedule. Its ID is the ID of the submitted transaction.
+type ScheduleExecution struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	ScheduleID   uuid.UUID  `json:"scheduleId" gorm:"type:uuid;not null;uniqueIndex:idx_schedule_executions_run"`
+	RunNumber    int        `json:"runNumber" gorm:"not null;uniqueIndex:idx_schedule_executions_run"`
+	ScheduledFor time.Time  `json:"scheduledFor" gorm:"type:timestamp with time zone;not null"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null"`
+	Reason       string     `json:"reason,omitempty" gorm:"type:text"`
+	SubmittedAt  time.Time  `json:"submittedAt" gorm:"type:timestamp with time zone"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty" gorm:"type:timestamp with time zone"`
+}
+
+// Validate checks the schedule definition before it is stored.
+func (s *Schedule) Validate() error {
+	switch s.Frequency {
+	case FrequencyOnce, FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+	default:
+		return fmt.Errorf("invalid frequency %q", s.Frequency)
+	}
+	if s.Amount <= 0 {
+		return fmt.Errorf("invalid transaction amount")
+	}
+	if s.TransactionType != "credit" && s.TransactionType != "debit" {
+		return fmt.Errorf("invalid transaction type")
+	}
+	if s.StartAt.IsZero() {
+		return fmt.Errorf("startAt is required")
+	}
+	if s.EndAt != nil && s.EndAt.Before(s.StartAt) {
+		return fmt.Errorf("endAt must not be before startAt")
+	}
+	if s.MaxRuns != nil && *s.MaxRuns <= 0 {
+		return fmt.Errorf("maxRuns must be positive")
+	}
+	return nil
+}
+
+// RunAt returns when the run with the given zero-based index is due, or nil when the
+// schedule has no such run. Monthly runs on days the month lacks fall on its last day.
+func (s *Schedule) RunAt(run int) *time.Time {
+	if s.Frequency == FrequencyOnce && run > 0 {
+		return nil
+	}
+	if s.MaxRuns != nil && run >= *s.MaxRuns {
+		return nil
+	}
+
+	var at time.Time
+	switch s.Frequency {
+	case FrequencyDaily:
+		at = s.StartAt.AddDate(0, 0, run)
+	case FrequencyWeekly:
+		at = s.StartAt.AddDate(0, 0, 7*run)
+	case FrequencyMonthly:
+		at = addMonths(s.StartAt, run)
+	default:
+		at = s.StartAt
+	}
+
+	if s.EndAt != nil && at.After(*s.EndAt) {
+		return nil
+	}
+	return &at
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
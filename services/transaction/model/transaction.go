@@ -9,8 +9,15 @@ import (
 type Transaction struct {
 	ID              uuid.UUID `json:"id"`
 	AccountID       uuid.UUID `json:"accountId"`
+	AccountType     string    `json:"accountType"`
+	CustomerID      string    `json:"customerId"`
+	Counterparty    string    `json:"counterparty,omitempty"`
 	Amount          float64   `json:"amount"`
 	TransactionType string    `json:"transactionType"` // e.g., "credit", "debit"
 	Details         string    `json:"details"`
 	AcceptedAt      time.Time `json:"acceptedAt"`
+
+	AccountCreatedAt *time.Time `json:"accountCreatedAt,omitempty"`
+	ScheduleID       *uuid.UUID `json:"scheduleId,omitempty"`
+	BatchID          *uuid.UUID `json:"batchId,omitempty"`
 }
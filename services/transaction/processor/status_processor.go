@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+
+	"transaction/model"
+	"transaction/service"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+// StatusProcessor follows transaction statuses on the ledger topic and records the
+// outcome of scheduled runs and batch items.
+type StatusProcessor struct {
+	consumer        ckafka.KafkaConsumer
+	topic           string
+	consumerGroup   string
+	scheduleService service.ScheduleService
+	batchService    service.BatchService
+}
+
+func NewStatusProcessor(consumer ckafka.KafkaConsumer, topic string, consumerGroup string, scheduleService service.ScheduleService, batchService service.BatchService) *StatusProcessor {
+	return &StatusProcessor{
+		consumer:        consumer,
+		topic:           topic,
+		consumerGroup:   consumerGroup,
+		scheduleService: scheduleService,
+		batchService:    batchService,
+	}
+}
+
+func (sp *StatusProcessor) ProcessStatuses(ctx context.Context) error {
+	return sp.consumer.Consume(ctx, sp.topic, sp.consumerGroup, func(msg kafka.Message) error {
+		// Shutdown must not cancel a message halfway through
+		msgCtx, span := ckafka.StartProcessSpan(context.WithoutCancel(ctx), msg, sp.consumerGroup)
+		defer span.End()
+		return sp.handleStatusMessage(msgCtx, msg)
+	})
+}
+
+func (sp *StatusProcessor) handleStatusMessage(ctx context.Context, msg kafka.Message) error {
+	var transaction map[string]interface{}
+	if err := json.Unmarshal(msg.Value, &transaction); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Skipping malformed status message")
+		return nil
+	}
+
+	// Only transactions submitted by a schedule or in a batch are tracked here
+	_, scheduled := transaction["scheduleId"].(string)
+	_, batched := transaction["batchId"].(string)
+	if !scheduled && !batched {
+		return nil
+	}
+	id, err := uuid.Parse(stringField(transaction, "id"))
+	if err != nil {
+		return nil
+	}
+
+	status := stringField(transaction, "status")
+	switch status {
+	case model.ExecutionSuccess, model.ExecutionFailed, model.ExecutionPendingReview:
+	default:
+		return nil
+	}
+
+	reason := stringField(transaction, "rejectionReason")
+	if reason == "" {
+		reason = stringField(transaction, "error")
+	}
+	if scheduled {
+		return sp.scheduleService.RecordOutcome(ctx, id, status, reason)
+	}
+	return sp.batchService.RecordOutcome(ctx, id, status, reason)
+}
+
+func stringField(transaction map[string]interface{}, key string) string {
+	value, _ := transaction[key].(string)
+	return value
+}
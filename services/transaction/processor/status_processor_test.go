@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transaction/model"
+	"transaction/service"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) CreateSchedule(ctx context.Context, schedule *model.Schedule) error {
+	return m.Called(ctx, schedule).Error(0)
+}
+
+func (m *MockScheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error) {
+	args := m.Called(ctx, scheduleID)
+	return args.Get(0).([]model.ScheduleExecution), args.Error(1)
+}
+
+func (m *MockScheduleService) RunDue(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockScheduleService) RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return m.Called(ctx, transactionID, status, reason).Error(0)
+}
+
+type MockBatchService struct {
+	mock.Mock
+}
+
+func (m *MockBatchService) SubmitBatch(ctx context.Context, mode string, lines []service.BatchLine) (*model.Batch, []model.BatchItem, error) {
+	args := m.Called(ctx, mode, lines)
+	return args.Get(0).(*model.Batch), args.Get(1).([]model.BatchItem), args.Error(2)
+}
+
+func (m *MockBatchService) GetBatchSummary(ctx context.Context, id uuid.UUID) (*model.BatchSummary, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.BatchSummary), args.Error(1)
+}
+
+func (m *MockBatchService) ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error) {
+	args := m.Called(ctx, batchID, status)
+	return args.Get(0).([]model.BatchItem), args.Error(1)
+}
+
+func (m *MockBatchService) RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return m.Called(ctx, transactionID, status, reason).Error(0)
+}
+
+func TestHandleStatusMessage(t *testing.T) {
+	mockScheduleService := new(MockScheduleService)
+	mockBatchService := new(MockBatchService)
+	processor := NewStatusProcessor(nil, "ledger-topic", "transaction-service-group", mockScheduleService, mockBatchService)
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("records the outcome of a scheduled run", func(t *testing.T) {
+		mockScheduleService.On("RecordOutcome", ctx, id, model.ExecutionFailed, "daily_debit_limit_exceeded").Return(nil).Once()
+
+		msg := kafka.Message{Value: []byte(`{"id":"` + id.String() + `","scheduleId":"s1","status":"failed","rejectionReason":"daily_debit_limit_exceeded"}`)}
+		_ = processor.handleStatusMessage(ctx, msg)
+		mockScheduleService.AssertExpectations(t)
+	})
+
+	t.Run("records the outcome of a batch item", func(t *testing.T) {
+		mockBatchService.On("RecordOutcome", ctx, id, model.ItemSuccess, "").Return(nil).Once()
+
+		msg := kafka.Message{Value: []byte(`{"id":"` + id.String() + `","batchId":"b1","status":"success","error":""}`)}
+		_ = processor.handleStatusMessage(ctx, msg)
+		mockBatchService.AssertExpectations(t)
+	})
+
+	t.Run("ignores transactions without a schedule or batch", func(t *testing.T) {
+		msg := kafka.Message{Value: []byte(`{"id":"` + id.String() + `","status":"success"}`)}
+		_ = processor.handleStatusMessage(ctx, msg)
+		mockScheduleService.AssertNumberOfCalls(t, "RecordOutcome", 1)
+		mockBatchService.AssertNumberOfCalls(t, "RecordOutcome", 1)
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"transaction/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BatchRepository interface {
+	CreateBatch(ctx context.Context, batch *model.Batch, items []model.BatchItem) error
+	GetBatch(ctx context.Context, id uuid.UUID) (*model.Batch, error)
+	CountItemsByStatus(ctx context.Context, batchID uuid.UUID) (map[string]int, error)
+	ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error)
+	UpdateItemStatus(ctx context.Context, batchID uuid.UUID, line int, status string, reason string) error
+	UpdateItemOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error
+}
+
+type batchRepository struct {
+	db *gorm.DB
+}
+
+func NewBatchRepository(db *gorm.DB) BatchRepository {
+	return &batchRepository{db: db}
+}
+
+func (r *batchRepository) CreateBatch(ctx context.Context, batch *model.Batch, items []model.BatchItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(items, 500).Error
+	})
+}
+
+func (r *batchRepository) GetBatch(ctx context.Context, id uuid.UUID) (*model.Batch, error) {
+	var batch model.Batch
+	err := r.db.WithContext(ctx).First(&batch, "id = ?", id).Error
+	return &batch, err
+}
+
+func (r *batchRepository) CountItemsByStatus(ctx context.Context, batchID uuid.UUID) (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	err := r.db.WithContext(ctx).Model(&model.BatchItem{}).
+		Select("status, COUNT(*) AS count").
+		Where("batch_id = ?", batchID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *batchRepository) ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error) {
+	var items []model.BatchItem
+	query := r.db.WithContext(ctx).Where("batch_id = ?", batchID).Order("line")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&items).Error
+	return items, err
+}
+
+func (r *batchRepository) UpdateItemStatus(ctx context.Context, batchID uuid.UUID, line int, status string, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.BatchItem{}).
+		Where("batch_id = ? AND line = ?", batchID, line).
+		Updates(map[string]interface{}{"status": status, "reason": reason, "updated_at": time.Now().UTC()}).Error
+}
+
+// UpdateItemOutcome records the status an item's transaction reached. Items that already
+// completed and transactions not submitted in a batch are left untouched.
+func (r *batchRepository) UpdateItemOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.BatchItem{}).
+		Where("transaction_id = ? AND status IN ?", transactionID, []string{model.ItemSubmitted, model.ItemPendingReview}).
+		Updates(map[string]interface{}{"status": status, "reason": reason, "updated_at": time.Now().UTC()}).Error
+}
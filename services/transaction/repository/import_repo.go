@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"transaction/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrImportConflict is returned when a line of the file was matched by a concurrent import.
+var ErrImportConflict = errors.New("lines of this file are being imported concurrently")
+
+type ImportRepository interface {
+	MatchedKeys(ctx context.Context, keys []string) (map[string]bool, error)
+	CreateImport(ctx context.Context, bankImport *model.BankImport, lines []model.BankImportLine) error
+	GetImport(ctx context.Context, id uuid.UUID) (*model.BankImport, error)
+	ListLines(ctx context.Context, importID uuid.UUID) ([]model.BankImportLine, error)
+	UpdateLineStatus(ctx context.Context, importID uuid.UUID, line int, status string, reason string) error
+}
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+// MatchedKeys returns the keys among keys that an earlier import submitted.
+func (r *importRepository) MatchedKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	matched := make(map[string]bool)
+	for start := 0; start < len(keys); start += 1000 {
+		end := min(start+1000, len(keys))
+		var found []string
+		err := r.db.WithContext(ctx).Model(&model.BankImportLine{}).
+			Where("idempotency_key IN ? AND status = ?", keys[start:end], model.LineMatched).
+			Pluck("idempotency_key", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range found {
+			matched[key] = true
+		}
+	}
+	return matched, nil
+}
+
+func (r *importRepository) CreateImport(ctx context.Context, bankImport *model.BankImport, lines []model.BankImportLine) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(bankImport).Error; err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(lines, 500).Error
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_bank_import_lines_matched_key" {
+		return ErrImportConflict
+	}
+	return err
+}
+
+func (r *importRepository) GetImport(ctx context.Context, id uuid.UUID) (*model.BankImport, error) {
+	var bankImport model.BankImport
+	err := r.db.WithContext(ctx).First(&bankImport, "id = ?", id).Error
+	return &bankImport, err
+}
+
+func (r *importRepository) ListLines(ctx context.Context, importID uuid.UUID) ([]model.BankImportLine, error) {
+	var lines []model.BankImportLine
+	err := r.db.WithContext(ctx).Where("import_id = ?", importID).Order("line").Find(&lines).Error
+	return lines, err
+}
+
+func (r *importRepository) UpdateLineStatus(ctx context.Context, importID uuid.UUID, line int, status string, reason string) error {
+	return r.db.WithContext(ctx).Model(&model.BankImportLine{}).
+		Where("import_id = ? AND line = ?", importID, line).
+		Updates(map[string]interface{}{"status": status, "reason": reason}).Error
+}
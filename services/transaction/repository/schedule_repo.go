@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transaction/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrScheduleNotActive is returned when cancelling a schedule that is missing or no longer active.
+var ErrScheduleNotActive = errors.New("schedule not found or no longer active")
+
+type ScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule *model.Schedule) error
+	GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error)
+	ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error)
+	CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error)
+	ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error)
+	ClaimDue(ctx context.Context, now time.Time, submit func(schedule *model.Schedule, execution *model.ScheduleExecution) error) (bool, error)
+	UpdateExecutionOutcome(ctx context.Context, id uuid.UUID, status string, reason string) error
+}
+
+type scheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (r *scheduleRepository) CreateSchedule(ctx context.Context, schedule *model.Schedule) error {
+	return r.db.WithContext(ctx).Create(schedule).Error
+}
+
+func (r *scheduleRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	var schedule model.Schedule
+	err := r.db.WithContext(ctx).First(&schedule, "id = ?", id).Error
+	return &schedule, err
+}
+
+func (r *scheduleRepository) ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *scheduleRepository) CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	var schedule model.Schedule
+	result := r.db.WithContext(ctx).Model(&schedule).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, model.ScheduleActive).
+		Updates(map[string]interface{}{"status": model.ScheduleCancelled, "next_run_at": nil, "updated_at": time.Now().UTC()})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrScheduleNotActive
+	}
+	return &schedule, nil
+}
+
+func (r *scheduleRepository) ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error) {
+	var executions []model.ScheduleExecution
+	err := r.db.WithContext(ctx).Where("schedule_id = ?", scheduleID).Order("run_number").Find(&executions).Error
+	return executions, err
+}
+
+// ClaimDue locks one due schedule, skipping schedules locked by other replicas, and calls
+// submit for its next run. The execution is recorded and the schedule advanced within the
+// same database transaction, so a run is only consumed if submit succeeds. It reports
+// whether a due schedule was found.
+func (r *scheduleRepository) ClaimDue(ctx context.Context, now time.Time, submit func(schedule *model.Schedule, execution *model.ScheduleExecution) error) (bool, error) {
+	claimed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var schedule model.Schedule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", model.ScheduleActive, now).
+			Order("next_run_at").
+			First(&schedule).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		claimed = true
+
+		execution := model.ScheduleExecution{
+			ScheduleID:   schedule.ID,
+			RunNumber:    schedule.RunCount + 1,
+			ScheduledFor: *schedule.NextRunAt,
+			Status:       model.ExecutionSubmitted,
+			SubmittedAt:  now,
+		}
+		if err := submit(&schedule, &execution); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&execution).Error; err != nil {
+			return err
+		}
+
+		schedule.RunCount++
+		schedule.NextRunAt = schedule.RunAt(schedule.RunCount)
+		if schedule.NextRunAt == nil {
+			schedule.Status = model.ScheduleCompleted
+		}
+		return tx.Save(&schedule).Error
+	})
+	return claimed, err
+}
+
+// UpdateExecutionOutcome records the status an execution's transaction reached. Executions
+// that already completed and transactions not submitted by a schedule are left untouched.
+func (r *scheduleRepository) UpdateExecutionOutcome(ctx context.Context, id uuid.UUID, status string, reason string) error {
+	updates := map[string]interface{}{"status": status, "reason": reason}
+	if status != model.ExecutionPendingReview {
+		updates["completed_at"] = time.Now().UTC()
+	}
+	return r.db.WithContext(ctx).Model(&model.ScheduleExecution{}).
+		Where("id = ? AND status IN ?", id, []string{model.ExecutionSubmitted, model.ExecutionPendingReview}).
+		Updates(updates).Error
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"transaction/service"
+
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/requestid"
+)
+
+type ScheduleScheduler interface {
+	Run(ctx context.Context)
+}
+
+type scheduleScheduler struct {
+	scheduleService service.ScheduleService
+	pollInterval    time.Duration
+}
+
+// NewScheduleScheduler submits due scheduled transactions every pollInterval.
+func NewScheduleScheduler(scheduleService service.ScheduleService, pollInterval time.Duration) ScheduleScheduler {
+	return &scheduleScheduler{
+		scheduleService: scheduleService,
+		pollInterval:    pollInterval,
+	}
+}
+
+func (s *scheduleScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Each poll gets its own request ID so its submissions can be followed downstream
+		runCtx := requestid.NewContext(ctx, requestid.New())
+		runs, err := s.scheduleService.RunDue(runCtx, time.Now().UTC())
+		if err != nil {
+			logger.Ctx(runCtx).Error().Err(err).Msg("Failed to run scheduled transactions")
+		} else if runs > 0 {
+			logger.Ctx(runCtx).Info().Msgf("Submitted %d scheduled transactions", runs)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
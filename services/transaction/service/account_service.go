@@ -2,51 +2,48 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
+	"time"
+
+	"transaction/model"
 
 	"github.com/google/uuid"
-	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/client"
 )
 
-type accountService struct {
-	AccountServiceURL string
-}
+var (
+	// ErrAccountNotActive is returned for transactions on accounts that are not active.
+	ErrAccountNotActive = errors.New("account is not active")
+	// ErrAccountNotFound is returned when no account has the ID or number looked up. Other
+	// lookup errors, such as the account service being unavailable, are not final.
+	ErrAccountNotFound = client.ErrAccountNotFound
+)
 
 type AccountService interface {
 	GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error)
+	FindAccountByNumber(ctx context.Context, number string) (map[string]any, error)
 }
 
-func NewAccountService(accountServiceURL string) AccountService {
-	return &accountService{
-		AccountServiceURL: accountServiceURL,
-	}
+// NewAccountService calls the account service with httpClient, which authenticates the requests.
+func NewAccountService(accountServiceURL string, httpClient *http.Client) AccountService {
+	return client.NewAccounts(accountServiceURL, httpClient)
 }
 
-func (s *accountService) GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error) {
-	url := fmt.Sprintf("%s/api/v1/accounts/%s", s.AccountServiceURL, accountID.String())
-	logger.Log.Info().Msgf("URL: %s", url)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
+// ApplyAccount checks that the account is active and carries its type, owner and age on the
+// transaction so limits and risk rules can be enforced downstream.
+func ApplyAccount(transaction *model.Transaction, account map[string]any) error {
+	if status, ok := account["Status"].(string); !ok || status != "active" {
+		return ErrAccountNotActive
 	}
-	req.SetBasicAuth("test", "test")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	transaction.AccountType, _ = account["AccountType"].(string)
+	transaction.CustomerID, _ = account["CustomerID"].(string)
+	transaction.AccountCreatedAt = nil
+	if createdAt, ok := account["CreatedAt"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339Nano, createdAt); err == nil {
+			transaction.AccountCreatedAt = &parsed
+		}
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("account not found")
-	}
-
-	var account map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
-		return nil, err
-	}
-
-	return account, nil
+	return nil
 }
@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/shrishyam02/banking-ledger/common/client"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,7 +24,7 @@ func TestGetAccountByID_Success(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := NewAccountService(server.URL)
+	service := NewAccountService(server.URL, http.DefaultClient)
 	ctx := context.Background()
 
 	account, err := service.GetAccountByID(ctx, accountID)
@@ -38,17 +40,59 @@ func TestGetAccountByID_NotFound(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := NewAccountService(server.URL)
+	service := NewAccountService(server.URL, http.DefaultClient)
 	ctx := context.Background()
 
 	account, err := service.GetAccountByID(ctx, accountID)
 	assert.Error(t, err)
 	assert.Nil(t, account)
 	assert.Equal(t, "account not found", err.Error())
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestGetAccountByID_Unavailable(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusForbidden} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		service := NewAccountService(server.URL, http.DefaultClient)
+		account, err := service.GetAccountByID(context.Background(), uuid.New())
+		server.Close()
+
+		assert.Nil(t, account)
+		assert.NotErrorIs(t, err, ErrAccountNotFound)
+		var statusErr *client.StatusError
+		if assert.True(t, errors.As(err, &statusErr)) {
+			assert.Equal(t, status, statusErr.StatusCode)
+			assert.Equal(t, status != http.StatusForbidden, statusErr.Retryable())
+		}
+	}
+}
+
+func TestFindAccountByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/accounts", r.URL.Path)
+		if r.URL.Query().Get("number") == "DE89 3704" {
+			json.NewEncoder(w).Encode([]map[string]any{{"ID": "acc-1"}})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{})
+	}))
+	defer server.Close()
+
+	service := NewAccountService(server.URL, http.DefaultClient)
+
+	account, err := service.FindAccountByNumber(context.Background(), "DE89 3704")
+	assert.NoError(t, err)
+	assert.Equal(t, "acc-1", account["ID"])
+
+	_, err = service.FindAccountByNumber(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
 }
 
 func TestGetAccountByID_RequestError(t *testing.T) {
-	service := NewAccountService("http://invalid-url")
+	service := NewAccountService("http://invalid-url", http.DefaultClient)
 	ctx := context.Background()
 	accountID := uuid.New()
 
@@ -66,7 +110,7 @@ func TestGetAccountByID_DecodeError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service := NewAccountService(server.URL)
+	service := NewAccountService(server.URL, http.DefaultClient)
 	ctx := context.Background()
 
 	account, err := service.GetAccountByID(ctx, accountID)
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"transaction/model"
+	"transaction/repository"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+)
+
+// ErrBatchRejected is returned when an all-or-nothing batch has invalid lines.
+var ErrBatchRejected = errors.New("batch rejected: one or more lines are invalid")
+
+// batchNamespace derives each item's transaction ID from its batch and line.
+var batchNamespace = uuid.MustParse("8c4e2a71-0d5b-4f6e-b3a9-1e7f2c9d6a04")
+
+// lookupConcurrency bounds the number of in-flight account lookups per batch.
+const lookupConcurrency = 16
+
+// BatchLine is one parsed line of a batch; Err is set when the line could not be parsed.
+type BatchLine struct {
+	Transaction model.Transaction
+	Err         error
+}
+
+type BatchService interface {
+	SubmitBatch(ctx context.Context, mode string, lines []BatchLine) (*model.Batch, []model.BatchItem, error)
+	GetBatchSummary(ctx context.Context, id uuid.UUID) (*model.BatchSummary, error)
+	ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error)
+	RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error
+}
+
+type batchService struct {
+	repo           repository.BatchRepository
+	accountService AccountService
+	producer       ckafka.KafkaProducer
+	topic          string
+}
+
+func NewBatchService(repo repository.BatchRepository, accountService AccountService, producer ckafka.KafkaProducer, topic string) BatchService {
+	return &batchService{
+		repo:           repo,
+		accountService: accountService,
+		producer:       producer,
+		topic:          topic,
+	}
+}
+
+// SubmitBatch validates every line and publishes the valid ones with a shared batch ID.
+// In all-or-nothing mode nothing is published unless every line is valid; the batch is
+// still recorded so its rejected lines can be inspected.
+func (s *batchService) SubmitBatch(ctx context.Context, mode string, lines []BatchLine) (*model.Batch, []model.BatchItem, error) {
+	now := time.Now().UTC()
+	batch := &model.Batch{
+		ID:        uuid.New(),
+		Mode:      mode,
+		Status:    model.BatchAccepted,
+		ItemCount: len(lines),
+		CreatedAt: now,
+	}
+
+	accounts, err := s.lookupAccounts(ctx, lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]model.BatchItem, len(lines))
+	transactions := make([]*model.Transaction, len(lines))
+	for i := range lines {
+		transaction := lines[i].Transaction
+		items[i] = model.BatchItem{BatchID: batch.ID, Line: i + 1, Amount: transaction.Amount, UpdatedAt: now}
+		if transaction.AccountID != uuid.Nil {
+			accountID := transaction.AccountID
+			items[i].AccountID = &accountID
+		}
+
+		err := lines[i].Err
+		if err == nil {
+			err = validate(&transaction, accounts)
+		}
+		if err != nil {
+			items[i].Status = model.ItemRejected
+			items[i].Reason = err.Error()
+			batch.RejectedCount++
+			continue
+		}
+
+		transactionID := uuid.NewSHA1(batchNamespace, []byte(batch.ID.String()+"/"+strconv.Itoa(i+1)))
+		transaction.ID = transactionID
+		transaction.AcceptedAt = now
+		transaction.BatchID = &batch.ID
+		transaction.ScheduleID = nil
+		transactions[i] = &transaction
+
+		items[i].TransactionID = &transactionID
+		items[i].Status = model.ItemSubmitted
+		batch.AcceptedCount++
+	}
+
+	if mode == model.BatchModeAllOrNothing && batch.RejectedCount > 0 {
+		batch.Status = model.BatchRejected
+		for i := range items {
+			if items[i].Status == model.ItemSubmitted {
+				items[i].Status = model.ItemRejected
+				items[i].Reason = model.ReasonBatchRejected
+				items[i].TransactionID = nil
+			}
+		}
+		batch.RejectedCount, batch.AcceptedCount = batch.ItemCount, 0
+		if err := s.repo.CreateBatch(ctx, batch, items); err != nil {
+			return nil, nil, err
+		}
+		return batch, items, ErrBatchRejected
+	}
+
+	// Items are recorded before publishing so status events always find them
+	if err := s.repo.CreateBatch(ctx, batch, items); err != nil {
+		return nil, nil, err
+	}
+	s.publish(ctx, batch, items, transactions)
+	return batch, items, nil
+}
+
+// lookupAccounts fetches the accounts of the batch concurrently, each once. Unknown accounts
+// map to nil; any other lookup error fails the whole batch, as its lines cannot be judged.
+func (s *batchService) lookupAccounts(ctx context.Context, lines []BatchLine) (map[uuid.UUID]map[string]any, error) {
+	accounts := make(map[uuid.UUID]map[string]any)
+	for _, line := range lines {
+		if line.Err == nil && line.Transaction.AccountID != uuid.Nil {
+			accounts[line.Transaction.AccountID] = nil
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	sem := make(chan struct{}, lookupConcurrency)
+	for accountID := range accounts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(accountID uuid.UUID) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			account, err := s.accountService.GetAccountByID(ctx, accountID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, ErrAccountNotFound):
+			case err != nil:
+				if firstErr == nil {
+					firstErr = fmt.Errorf("looking up account %s: %w", accountID, err)
+				}
+			default:
+				accounts[accountID] = account
+			}
+		}(accountID)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return accounts, nil
+}
+
+func validate(transaction *model.Transaction, accounts map[uuid.UUID]map[string]any) error {
+	if transaction.Amount <= 0 {
+		return fmt.Errorf("invalid transaction amount")
+	}
+	if transaction.TransactionType != "credit" && transaction.TransactionType != "debit" {
+		return fmt.Errorf("invalid transaction type")
+	}
+	if transaction.AccountID == uuid.Nil {
+		return fmt.Errorf("accountId is required")
+	}
+
+	account := accounts[transaction.AccountID]
+	if account == nil {
+		return ErrAccountNotFound
+	}
+	return ApplyAccount(transaction, account)
+}
+
+func (s *batchService) publish(ctx context.Context, batch *model.Batch, items []model.BatchItem, transactions []*model.Transaction) {
+	for i, err := range produceTransactions(ctx, s.producer, s.topic, transactions) {
+		if err == nil {
+			continue
+		}
+		logger.Ctx(ctx).Error().Err(err).Msgf("Failed to publish line %d of batch %s", items[i].Line, batch.ID)
+		items[i].Status = model.ItemFailed
+		items[i].Reason = "publish failed: " + err.Error()
+		if uerr := s.repo.UpdateItemStatus(ctx, batch.ID, items[i].Line, items[i].Status, items[i].Reason); uerr != nil {
+			logger.Ctx(ctx).Error().Err(uerr).Msgf("Failed to record publish failure for line %d of batch %s", items[i].Line, batch.ID)
+		}
+	}
+}
+
+// produceTransactions publishes transactions to topic in a single write, each keyed by its ID,
+// and returns the error each one failed with, in order. Nil transactions are skipped.
+func produceTransactions(ctx context.Context, producer ckafka.KafkaProducer, topic string, transactions []*model.Transaction) []error {
+	errs := make([]error, len(transactions))
+	messages := make([]kafka.Message, 0, len(transactions))
+	indexes := make([]int, 0, len(transactions))
+	for i, transaction := range transactions {
+		if transaction == nil {
+			continue
+		}
+		transactionBytes, err := json.Marshal(transaction)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		messages = append(messages, kafka.Message{
+			Key:   []byte(transaction.ID.String()),
+			Value: transactionBytes,
+		})
+		indexes = append(indexes, i)
+	}
+
+	err := producer.ProduceBatch(ctx, topic, messages)
+	var writeErrors kafka.WriteErrors
+	errors.As(err, &writeErrors)
+	for j, i := range indexes {
+		messageErr := err
+		if writeErrors != nil {
+			messageErr = writeErrors[j]
+		}
+		if messageErr != nil {
+			errs[i] = messageErr
+			continue
+		}
+		metrics.RecordTransaction(metrics.TransactionAccepted, transactions[i].TransactionType)
+	}
+	return errs
+}
+
+func (s *batchService) GetBatchSummary(ctx context.Context, id uuid.UUID) (*model.BatchSummary, error) {
+	batch, err := s.repo.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountItemsByStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.BatchSummary{
+		Batch:      *batch,
+		Counts:     counts,
+		Processing: counts[model.ItemSubmitted]+counts[model.ItemPendingReview] > 0,
+	}, nil
+}
+
+func (s *batchService) ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error) {
+	return s.repo.ListItems(ctx, batchID, status)
+}
+
+func (s *batchService) RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return s.repo.UpdateItemOutcome(ctx, transactionID, status, reason)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transaction/model"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBatchRepository struct {
+	mock.Mock
+}
+
+func (m *MockBatchRepository) CreateBatch(ctx context.Context, batch *model.Batch, items []model.BatchItem) error {
+	args := m.Called(ctx, batch, items)
+	return args.Error(0)
+}
+
+func (m *MockBatchRepository) GetBatch(ctx context.Context, id uuid.UUID) (*model.Batch, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Batch), args.Error(1)
+}
+
+func (m *MockBatchRepository) CountItemsByStatus(ctx context.Context, batchID uuid.UUID) (map[string]int, error) {
+	args := m.Called(ctx, batchID)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockBatchRepository) ListItems(ctx context.Context, batchID uuid.UUID, status string) ([]model.BatchItem, error) {
+	args := m.Called(ctx, batchID, status)
+	return args.Get(0).([]model.BatchItem), args.Error(1)
+}
+
+func (m *MockBatchRepository) UpdateItemStatus(ctx context.Context, batchID uuid.UUID, line int, status string, reason string) error {
+	args := m.Called(ctx, batchID, line, status, reason)
+	return args.Error(0)
+}
+
+func (m *MockBatchRepository) UpdateItemOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	args := m.Called(ctx, transactionID, status, reason)
+	return args.Error(0)
+}
+
+func batchLines(active uuid.UUID, inactive uuid.UUID) []BatchLine {
+	return []BatchLine{
+		{Transaction: model.Transaction{AccountID: active, Amount: 100, TransactionType: "credit"}},
+		{Transaction: model.Transaction{AccountID: active, Amount: -5, TransactionType: "credit"}},
+		{Transaction: model.Transaction{AccountID: inactive, Amount: 10, TransactionType: "debit"}},
+		{Err: errors.New("invalid character")},
+	}
+}
+
+func TestSubmitBatch_Partial(t *testing.T) {
+	mockRepo := new(MockBatchRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewBatchService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	active, inactive := uuid.New(), uuid.New()
+	mockAccountService.On("GetAccountByID", ctx, active).Return(map[string]any{"Status": "active"}, nil).Once()
+	mockAccountService.On("GetAccountByID", ctx, inactive).Return(map[string]any{"Status": "closed"}, nil).Once()
+	mockRepo.On("CreateBatch", ctx, mock.Anything, mock.Anything).Return(nil)
+	mockProducer.On("ProduceBatch", ctx, "transactions-topic", mock.MatchedBy(func(messages []kafka.Message) bool {
+		return len(messages) == 1
+	})).Return(nil).Once()
+
+	batch, items, err := service.SubmitBatch(ctx, model.BatchModePartial, batchLines(active, inactive))
+	assert.NoError(t, err)
+	assert.Equal(t, model.BatchAccepted, batch.Status)
+	assert.Equal(t, 1, batch.AcceptedCount)
+	assert.Equal(t, 3, batch.RejectedCount)
+	assert.Equal(t, model.ItemSubmitted, items[0].Status)
+	assert.Equal(t, uuid.NewSHA1(batchNamespace, []byte(batch.ID.String()+"/1")), *items[0].TransactionID)
+	assert.Equal(t, "invalid transaction amount", items[1].Reason)
+	assert.Equal(t, ErrAccountNotActive.Error(), items[2].Reason)
+	assert.Equal(t, model.ItemRejected, items[3].Status)
+	mockAccountService.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestSubmitBatch_AllOrNothingRejected(t *testing.T) {
+	mockRepo := new(MockBatchRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewBatchService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	active, inactive := uuid.New(), uuid.New()
+	mockAccountService.On("GetAccountByID", ctx, active).Return(map[string]any{"Status": "active"}, nil)
+	mockAccountService.On("GetAccountByID", ctx, inactive).Return(map[string]any{"Status": "closed"}, nil)
+	mockRepo.On("CreateBatch", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	batch, items, err := service.SubmitBatch(ctx, model.BatchModeAllOrNothing, batchLines(active, inactive))
+	assert.ErrorIs(t, err, ErrBatchRejected)
+	assert.Equal(t, model.BatchRejected, batch.Status)
+	assert.Equal(t, 4, batch.RejectedCount)
+	assert.Equal(t, model.ReasonBatchRejected, items[0].Reason)
+	assert.Nil(t, items[0].TransactionID)
+	mockProducer.AssertNotCalled(t, "ProduceBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubmitBatch_PublishFailureMarksItemFailed(t *testing.T) {
+	mockRepo := new(MockBatchRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewBatchService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	lines := []BatchLine{{Transaction: model.Transaction{AccountID: accountID, Amount: 10, TransactionType: "credit"}}}
+	mockAccountService.On("GetAccountByID", ctx, accountID).Return(map[string]any{"Status": "active"}, nil)
+	mockRepo.On("CreateBatch", ctx, mock.Anything, mock.Anything).Return(nil)
+	mockProducer.On("ProduceBatch", ctx, "transactions-topic", mock.Anything).Return(errors.New("kafka error"))
+	mockRepo.On("UpdateItemStatus", ctx, mock.Anything, 1, model.ItemFailed, "publish failed: kafka error").Return(nil)
+
+	_, items, err := service.SubmitBatch(ctx, model.BatchModeAllOrNothing, lines)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ItemFailed, items[0].Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitBatch_PartialPublishFailure(t *testing.T) {
+	mockRepo := new(MockBatchRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewBatchService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	lines := []BatchLine{
+		{Transaction: model.Transaction{AccountID: accountID, Amount: 10, TransactionType: "credit"}},
+		{Transaction: model.Transaction{AccountID: accountID, Amount: 20, TransactionType: "credit"}},
+	}
+	mockAccountService.On("GetAccountByID", ctx, accountID).Return(map[string]any{"Status": "active"}, nil).Once()
+	mockRepo.On("CreateBatch", ctx, mock.Anything, mock.Anything).Return(nil)
+	mockProducer.On("ProduceBatch", ctx, "transactions-topic", mock.Anything).Return(kafka.WriteErrors{nil, errors.New("kafka error")}).Once()
+	mockRepo.On("UpdateItemStatus", ctx, mock.Anything, 2, model.ItemFailed, "publish failed: kafka error").Return(nil)
+
+	_, items, err := service.SubmitBatch(ctx, model.BatchModePartial, lines)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ItemSubmitted, items[0].Status)
+	assert.Equal(t, model.ItemFailed, items[1].Status)
+	mockAccountService.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitBatch_AccountLookupErrorFailsBatch(t *testing.T) {
+	mockRepo := new(MockBatchRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewBatchService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	active, unknown, unavailable := uuid.New(), uuid.New(), uuid.New()
+	lines := []BatchLine{
+		{Transaction: model.Transaction{AccountID: active, Amount: 10, TransactionType: "credit"}},
+		{Transaction: model.Transaction{AccountID: unknown, Amount: 10, TransactionType: "credit"}},
+		{Transaction: model.Transaction{AccountID: unavailable, Amount: 10, TransactionType: "credit"}},
+	}
+	mockAccountService.On("GetAccountByID", ctx, active).Return(map[string]any{"Status": "active"}, nil)
+	mockAccountService.On("GetAccountByID", ctx, unknown).Return(map[string]any(nil), ErrAccountNotFound)
+	mockAccountService.On("GetAccountByID", ctx, unavailable).Return(map[string]any(nil), errors.New("connection refused"))
+
+	batch, _, err := service.SubmitBatch(ctx, model.BatchModePartial, lines)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBatchRejected)
+	assert.Nil(t, batch)
+	// An unavailable account service must not turn lines into rejections
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything, mock.Anything)
+	mockProducer.AssertNotCalled(t, "ProduceBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBatchSummary(t *testing.T) {
+	mockRepo := new(MockBatchRepository)
+	service := NewBatchService(mockRepo, nil, nil, "transactions-topic")
+
+	ctx := context.Background()
+	id := uuid.New()
+	mockRepo.On("GetBatch", ctx, id).Return(&model.Batch{ID: id, ItemCount: 3}, nil)
+	mockRepo.On("CountItemsByStatus", ctx, id).Return(map[string]int{model.ItemSuccess: 2, model.ItemSubmitted: 1}, nil)
+
+	summary, err := service.GetBatchSummary(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, summary.Processing)
+	assert.Equal(t, 2, summary.Counts[model.ItemSuccess])
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"transaction/bankimport"
+	"transaction/model"
+	"transaction/repository"
+
+	"github.com/google/uuid"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+)
+
+// importNamespace derives each payment's transaction ID from its idempotency key, so a payment
+// keeps its ID however often its file is imported.
+var importNamespace = uuid.MustParse("3f9d5b2e-7a41-4c86-9e0b-d1c6a8f42e57")
+
+type ImportService interface {
+	Import(ctx context.Context, format, fileName, importedBy string, lines []bankimport.Line) (*model.ImportReport, error)
+	GetReport(ctx context.Context, id uuid.UUID) (*model.ImportReport, error)
+}
+
+type importService struct {
+	repo           repository.ImportRepository
+	accountService AccountService
+	producer       ckafka.KafkaProducer
+	topic          string
+	currency       string
+}
+
+// NewImportService credits the payments of imported files in currency; payments in other
+// currencies are rejected.
+func NewImportService(repo repository.ImportRepository, accountService AccountService, producer ckafka.KafkaProducer, topic, currency string) ImportService {
+	return &importService{
+		repo:           repo,
+		accountService: accountService,
+		producer:       producer,
+		topic:          topic,
+		currency:       currency,
+	}
+}
+
+// Import matches the payments of a file to accounts and submits the matched ones as credits.
+// Payments submitted by an earlier import, or earlier in the same file, are duplicates.
+func (s *importService) Import(ctx context.Context, format, fileName, importedBy string, lines []bankimport.Line) (*model.ImportReport, error) {
+	now := time.Now().UTC()
+	bankImport := model.BankImport{
+		ID:         uuid.New(),
+		FileName:   fileName,
+		Format:     format,
+		LineCount:  len(lines),
+		ImportedBy: importedBy,
+		CreatedAt:  now,
+	}
+
+	keys := make([]string, len(lines))
+	for i := range lines {
+		keys[i] = lines[i].Key
+	}
+	submitted, err := s.repo.MatchedKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.BankImportLine, len(lines))
+	transactions := make([]*model.Transaction, len(lines))
+	accounts := make(map[string]map[string]any)
+	for i, line := range lines {
+		items[i] = model.BankImportLine{
+			ImportID:          bankImport.ID,
+			Line:              line.Number,
+			IdempotencyKey:    line.Key,
+			Reference:         line.Reference,
+			AccountIdentifier: line.AccountIdentifier,
+			Amount:            line.Amount,
+			Currency:          line.Currency,
+			BookedAt:          line.BookedAt,
+		}
+
+		if submitted[line.Key] {
+			items[i].Status = model.LineDuplicate
+			bankImport.DuplicateCount++
+			continue
+		}
+		transaction, status, err := s.match(ctx, line, accounts)
+		if err != nil && status == "" {
+			return nil, err
+		}
+		if err != nil {
+			items[i].Status = status
+			items[i].Reason = err.Error()
+			if status == model.LineUnmatched {
+				bankImport.UnmatchedCount++
+			} else {
+				bankImport.RejectedCount++
+			}
+			continue
+		}
+
+		transaction.ID = uuid.NewSHA1(importNamespace, []byte(line.Key))
+		transaction.AcceptedAt = now
+		transactions[i] = transaction
+		items[i].AccountID = &transaction.AccountID
+		items[i].TransactionID = &transaction.ID
+		items[i].Status = model.LineMatched
+		bankImport.MatchedCount++
+		submitted[line.Key] = true
+	}
+
+	// Lines are recorded before publishing so a concurrent import of the same file cannot
+	// submit them too
+	if err := s.repo.CreateImport(ctx, &bankImport, items); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, &bankImport, items, transactions)
+	return model.NewImportReport(bankImport, items), nil
+}
+
+// match returns the credit for a line, or the status and reason the line is not submitted. An
+// error without a status means the account could not be looked up, and the file should be
+// imported again later.
+func (s *importService) match(ctx context.Context, line bankimport.Line, accounts map[string]map[string]any) (*model.Transaction, string, error) {
+	if line.Err != nil {
+		return nil, model.LineRejected, line.Err
+	}
+	if line.Amount <= 0 {
+		return nil, model.LineRejected, fmt.Errorf("invalid transaction amount")
+	}
+	if line.Currency != "" && line.Currency != s.currency {
+		return nil, model.LineRejected, fmt.Errorf("currency %s is not supported", line.Currency)
+	}
+	identifier := bankimport.NormalizeAccount(line.AccountIdentifier)
+	if identifier == "" {
+		return nil, model.LineUnmatched, fmt.Errorf("line has no account")
+	}
+
+	account, ok := accounts[identifier]
+	if !ok {
+		var err error
+		account, err = s.accountService.FindAccountByNumber(ctx, identifier)
+		if err != nil && !errors.Is(err, ErrAccountNotFound) {
+			return nil, "", fmt.Errorf("account lookup failed: %w", err)
+		}
+		accounts[identifier] = account
+	}
+	if account == nil {
+		return nil, model.LineUnmatched, ErrAccountNotFound
+	}
+	accountID, err := uuid.Parse(fmt.Sprint(account["ID"]))
+	if err != nil {
+		return nil, model.LineUnmatched, ErrAccountNotFound
+	}
+
+	details := line.Details
+	if details == "" {
+		details = "Incoming payment " + line.Reference
+	}
+	transaction := &model.Transaction{
+		AccountID:       accountID,
+		Counterparty:    line.Counterparty,
+		Amount:          line.Amount,
+		TransactionType: "credit",
+		Details:         details,
+	}
+	if err := ApplyAccount(transaction, account); err != nil {
+		return nil, model.LineRejected, err
+	}
+	return transaction, "", nil
+}
+
+func (s *importService) publish(ctx context.Context, bankImport *model.BankImport, items []model.BankImportLine, transactions []*model.Transaction) {
+	for i, err := range produceTransactions(ctx, s.producer, s.topic, transactions) {
+		if err == nil {
+			continue
+		}
+		logger.Ctx(ctx).Error().Err(err).Msgf("Failed to publish line %d of import %s", items[i].Line, bankImport.ID)
+		items[i].Status = model.LineFailed
+		items[i].Reason = "publish failed: " + err.Error()
+		if uerr := s.repo.UpdateLineStatus(ctx, bankImport.ID, items[i].Line, items[i].Status, items[i].Reason); uerr != nil {
+			logger.Ctx(ctx).Error().Err(uerr).Msgf("Failed to record publish failure for line %d of import %s", items[i].Line, bankImport.ID)
+		}
+	}
+}
+
+func (s *importService) GetReport(ctx context.Context, id uuid.UUID) (*model.ImportReport, error) {
+	bankImport, err := s.repo.GetImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.ListLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewImportReport(*bankImport, lines), nil
+}
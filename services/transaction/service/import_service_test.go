@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transaction/bankimport"
+	"transaction/model"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportRepository) MatchedKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	args := m.Called(ctx, keys)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockImportRepository) CreateImport(ctx context.Context, bankImport *model.BankImport, lines []model.BankImportLine) error {
+	args := m.Called(ctx, bankImport, lines)
+	return args.Error(0)
+}
+
+func (m *MockImportRepository) GetImport(ctx context.Context, id uuid.UUID) (*model.BankImport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.BankImport), args.Error(1)
+}
+
+func (m *MockImportRepository) ListLines(ctx context.Context, importID uuid.UUID) ([]model.BankImportLine, error) {
+	args := m.Called(ctx, importID)
+	return args.Get(0).([]model.BankImportLine), args.Error(1)
+}
+
+func (m *MockImportRepository) UpdateLineStatus(ctx context.Context, importID uuid.UUID, line int, status string, reason string) error {
+	args := m.Called(ctx, importID, line, status, reason)
+	return args.Error(0)
+}
+
+func importLines() []bankimport.Line {
+	return []bankimport.Line{
+		{Number: 1, Key: "csv:R1", Reference: "R1", AccountIdentifier: "de44 5001 0517 5407 3249 31", Amount: 100, Currency: "USD"},
+		{Number: 2, Key: "csv:R2", Reference: "R2", AccountIdentifier: "ACC-2", Amount: 50},
+		{Number: 3, Key: "csv:R1", Reference: "R1", AccountIdentifier: "DE44500105175407324931", Amount: 100},
+		{Number: 4, Key: "csv:R3", Reference: "R3", AccountIdentifier: "UNKNOWN", Amount: 10},
+		{Number: 5, Key: "csv:R4", Reference: "R4", AccountIdentifier: "ACC-2", Amount: 10, Err: bankimport.ErrNotCredit},
+		{Number: 6, Key: "csv:R5", Reference: "R5", AccountIdentifier: "ACC-2", Amount: 10, Currency: "EUR"},
+	}
+}
+
+func TestImport(t *testing.T) {
+	mockRepo := new(MockImportRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewImportService(mockRepo, mockAccountService, mockProducer, "transactions-topic", "USD")
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	mockRepo.On("MatchedKeys", ctx, mock.Anything).Return(map[string]bool{"csv:R2": true}, nil)
+	mockAccountService.On("FindAccountByNumber", ctx, "DE44500105175407324931").
+		Return(map[string]any{"ID": accountID.String(), "Status": "active"}, nil).Once()
+	mockAccountService.On("FindAccountByNumber", ctx, "UNKNOWN").Return(nil, ErrAccountNotFound).Once()
+	mockRepo.On("CreateImport", ctx, mock.Anything, mock.Anything).Return(nil)
+	mockProducer.On("ProduceBatch", ctx, "transactions-topic", mock.MatchedBy(func(messages []kafka.Message) bool {
+		return len(messages) == 1
+	})).Return(nil).Once()
+
+	report, err := service.Import(ctx, model.ImportCSV, "incoming.csv", "ops", importLines())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.MatchedCount)
+	assert.Equal(t, 2, report.DuplicateCount)
+	assert.Equal(t, 1, report.UnmatchedCount)
+	assert.Equal(t, 2, report.RejectedCount)
+	assert.Equal(t, accountID, *report.Matched[0].AccountID)
+	assert.Equal(t, uuid.NewSHA1(importNamespace, []byte("csv:R1")), *report.Matched[0].TransactionID)
+	assert.Equal(t, []int{2, 3}, []int{report.Duplicates[0].Line, report.Duplicates[1].Line})
+	assert.Equal(t, ErrAccountNotFound.Error(), report.Unmatched[0].Reason)
+	assert.Equal(t, "currency EUR is not supported", report.Rejected[1].Reason)
+	mockAccountService.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestImport_AccountLookupFailure(t *testing.T) {
+	mockRepo := new(MockImportRepository)
+	mockAccountService := new(MockAccountService)
+	service := NewImportService(mockRepo, mockAccountService, nil, "transactions-topic", "USD")
+
+	ctx := context.Background()
+	mockRepo.On("MatchedKeys", ctx, mock.Anything).Return(map[string]bool{}, nil)
+	mockAccountService.On("FindAccountByNumber", ctx, mock.Anything).Return(nil, errors.New("account service unavailable"))
+
+	_, err := service.Import(ctx, model.ImportCSV, "incoming.csv", "ops", importLines()[:1])
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "CreateImport", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImport_PublishFailureMarksLineFailed(t *testing.T) {
+	mockRepo := new(MockImportRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewImportService(mockRepo, mockAccountService, mockProducer, "transactions-topic", "USD")
+
+	ctx := context.Background()
+	mockRepo.On("MatchedKeys", ctx, mock.Anything).Return(map[string]bool{}, nil)
+	mockAccountService.On("FindAccountByNumber", ctx, mock.Anything).
+		Return(map[string]any{"ID": uuid.New().String(), "Status": "active"}, nil)
+	mockRepo.On("CreateImport", ctx, mock.Anything, mock.Anything).Return(nil)
+	mockProducer.On("ProduceBatch", ctx, "transactions-topic", mock.Anything).Return(errors.New("kafka error"))
+	mockRepo.On("UpdateLineStatus", ctx, mock.Anything, 1, model.LineFailed, "publish failed: kafka error").Return(nil)
+
+	report, err := service.Import(ctx, model.ImportCSV, "incoming.csv", "ops", importLines()[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, model.LineFailed, report.Failed[0].Status)
+	mockRepo.AssertExpectations(t)
+}
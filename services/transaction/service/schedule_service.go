@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"transaction/model"
+	"transaction/repository"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	ckafka "github.com/shrishyam02/banking-ledger/common/kafka"
+	"github.com/shrishyam02/banking-ledger/common/logger"
+	"github.com/shrishyam02/banking-ledger/common/metrics"
+)
+
+// scheduleNamespace derives the transaction ID of each run from its schedule and run number,
+// so a run that is submitted again after a crash keeps the same transaction ID.
+var scheduleNamespace = uuid.MustParse("5b0d8f0e-3f3c-4c71-9a2f-7d1e6c0b4a53")
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, schedule *model.Schedule) error
+	GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error)
+	ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error)
+	CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error)
+	ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error)
+	RunDue(ctx context.Context, now time.Time) (int, error)
+	RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error
+}
+
+type scheduleService struct {
+	repo           repository.ScheduleRepository
+	accountService AccountService
+	producer       ckafka.KafkaProducer
+	topic          string
+}
+
+func NewScheduleService(repo repository.ScheduleRepository, accountService AccountService, producer ckafka.KafkaProducer, topic string) ScheduleService {
+	return &scheduleService{
+		repo:           repo,
+		accountService: accountService,
+		producer:       producer,
+		topic:          topic,
+	}
+}
+
+func (s *scheduleService) CreateSchedule(ctx context.Context, schedule *model.Schedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	schedule.StartAt = schedule.StartAt.UTC()
+	schedule.RunCount = 0
+	schedule.NextRunAt = schedule.RunAt(0)
+	if schedule.NextRunAt == nil {
+		return fmt.Errorf("schedule has no runs")
+	}
+	schedule.Status = model.ScheduleActive
+	return s.repo.CreateSchedule(ctx, schedule)
+}
+
+func (s *scheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	return s.repo.GetSchedule(ctx, id)
+}
+
+func (s *scheduleService) ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error) {
+	return s.repo.ListSchedules(ctx, accountID)
+}
+
+func (s *scheduleService) CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	return s.repo.CancelSchedule(ctx, id)
+}
+
+func (s *scheduleService) ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error) {
+	return s.repo.ListExecutions(ctx, scheduleID)
+}
+
+// RunDue submits every run that is due at now and returns the number of runs handled.
+// Each run is claimed under a row lock, so replicas running concurrently never submit
+// the same run twice.
+func (s *scheduleService) RunDue(ctx context.Context, now time.Time) (int, error) {
+	runs := 0
+	for {
+		claimed, err := s.repo.ClaimDue(ctx, now, func(schedule *model.Schedule, execution *model.ScheduleExecution) error {
+			return s.submit(ctx, schedule, execution, now)
+		})
+		if err != nil {
+			return runs, err
+		}
+		if !claimed {
+			return runs, nil
+		}
+		runs++
+	}
+}
+
+func (s *scheduleService) submit(ctx context.Context, schedule *model.Schedule, execution *model.ScheduleExecution, now time.Time) error {
+	execution.ID = uuid.NewSHA1(scheduleNamespace, []byte(schedule.ID.String()+"/"+strconv.Itoa(execution.RunNumber)))
+
+	scheduleID := schedule.ID
+	transaction := model.Transaction{
+		ID:              execution.ID,
+		AccountID:       schedule.AccountID,
+		Counterparty:    schedule.Counterparty,
+		Amount:          schedule.Amount,
+		TransactionType: schedule.TransactionType,
+		Details:         schedule.Details,
+		AcceptedAt:      now,
+		ScheduleID:      &scheduleID,
+	}
+
+	// After downtime only the latest missed run is submitted; earlier ones are recorded as
+	// skipped instead of all being submitted at once
+	if next := schedule.RunAt(execution.RunNumber); next != nil && !next.After(now) {
+		execution.Status = model.ExecutionSkipped
+		execution.Reason = "missed; a later run is due"
+		execution.CompletedAt = &now
+		return nil
+	}
+
+	// A run on an unknown or inactive account is recorded as failed rather than retried. Other
+	// lookup errors leave the run due, to be retried on the next poll.
+	account, err := s.accountService.GetAccountByID(ctx, schedule.AccountID)
+	if err == nil {
+		err = ApplyAccount(&transaction, account)
+	}
+	if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrAccountNotActive) {
+		logger.Ctx(ctx).Warn().Err(err).Msgf("Scheduled run %d of %s not submitted", execution.RunNumber, schedule.ID)
+		execution.Status = model.ExecutionFailed
+		execution.Reason = err.Error()
+		execution.CompletedAt = &now
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	transactionBytes, err := json.Marshal(transaction)
+	if err != nil {
+		return err
+	}
+	if err := s.producer.Produce(ctx, s.topic, kafka.Message{
+		Key:   []byte(transaction.ID.String()),
+		Value: transactionBytes,
+	}); err != nil {
+		return err
+	}
+	metrics.RecordTransaction(metrics.TransactionAccepted, transaction.TransactionType)
+	return nil
+}
+
+func (s *scheduleService) RecordOutcome(ctx context.Context, transactionID uuid.UUID, status string, reason string) error {
+	return s.repo.UpdateExecutionOutcome(ctx, transactionID, status, reason)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction/model"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduleRepository struct {
+	mock.Mock
+	due []*model.Schedule
+}
+
+func (m *MockScheduleRepository) CreateSchedule(ctx context.Context, schedule *model.Schedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *MockScheduleRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleRepository) ListSchedules(ctx context.Context, accountID uuid.UUID) ([]model.Schedule, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).([]model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleRepository) CancelSchedule(ctx context.Context, id uuid.UUID) (*model.Schedule, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleRepository) ListExecutions(ctx context.Context, scheduleID uuid.UUID) ([]model.ScheduleExecution, error) {
+	args := m.Called(ctx, scheduleID)
+	return args.Get(0).([]model.ScheduleExecution), args.Error(1)
+}
+
+// ClaimDue hands out the queued due schedules one at a time and records the executions.
+func (m *MockScheduleRepository) ClaimDue(ctx context.Context, now time.Time, submit func(schedule *model.Schedule, execution *model.ScheduleExecution) error) (bool, error) {
+	if len(m.due) == 0 {
+		return false, nil
+	}
+	schedule := m.due[0]
+	execution := &model.ScheduleExecution{
+		ScheduleID:   schedule.ID,
+		RunNumber:    schedule.RunCount + 1,
+		ScheduledFor: *schedule.NextRunAt,
+		Status:       model.ExecutionSubmitted,
+	}
+	if err := submit(schedule, execution); err != nil {
+		return true, err
+	}
+	m.due = m.due[1:]
+	m.Called(execution.ID, execution.Status)
+	return true, nil
+}
+
+func (m *MockScheduleRepository) UpdateExecutionOutcome(ctx context.Context, id uuid.UUID, status string, reason string) error {
+	args := m.Called(ctx, id, status, reason)
+	return args.Error(0)
+}
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) GetAccountByID(ctx context.Context, accountID uuid.UUID) (map[string]any, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func (m *MockAccountService) FindAccountByNumber(ctx context.Context, number string) (map[string]any, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+type MockKafkaProducer struct {
+	mock.Mock
+}
+
+func (m *MockKafkaProducer) Produce(ctx context.Context, topic string, message kafka.Message) error {
+	args := m.Called(ctx, topic, message)
+	return args.Error(0)
+}
+
+func (m *MockKafkaProducer) ProduceBatch(ctx context.Context, topic string, messages []kafka.Message) error {
+	args := m.Called(ctx, topic, messages)
+	return args.Error(0)
+}
+
+func (m *MockKafkaProducer) Close() error {
+	return nil
+}
+
+func dueSchedule(accountID uuid.UUID) *model.Schedule {
+	next := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	return &model.Schedule{
+		ID:              uuid.New(),
+		AccountID:       accountID,
+		Amount:          50,
+		TransactionType: "debit",
+		Frequency:       model.FrequencyMonthly,
+		StartAt:         next,
+		NextRunAt:       &next,
+		Status:          model.ScheduleActive,
+	}
+}
+
+func TestCreateSchedule(t *testing.T) {
+	mockRepo := new(MockScheduleRepository)
+	service := NewScheduleService(mockRepo, nil, nil, "transactions-topic")
+	ctx := context.Background()
+
+	t.Run("sets the first run", func(t *testing.T) {
+		schedule := &model.Schedule{AccountID: uuid.New(), Amount: 10, TransactionType: "credit", Frequency: model.FrequencyWeekly, StartAt: time.Now().Add(time.Hour)}
+		mockRepo.On("CreateSchedule", ctx, schedule).Return(nil).Once()
+
+		err := service.CreateSchedule(ctx, schedule)
+		assert.NoError(t, err)
+		assert.Equal(t, model.ScheduleActive, schedule.Status)
+		assert.Equal(t, schedule.StartAt, *schedule.NextRunAt)
+	})
+
+	t.Run("rejects an invalid frequency", func(t *testing.T) {
+		schedule := &model.Schedule{AccountID: uuid.New(), Amount: 10, TransactionType: "credit", Frequency: "yearly", StartAt: time.Now()}
+		err := service.CreateSchedule(ctx, schedule)
+		assert.Error(t, err)
+	})
+}
+
+func TestScheduleRunAt(t *testing.T) {
+	maxRuns := 3
+	schedule := model.Schedule{Frequency: model.FrequencyMonthly, StartAt: time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC), MaxRuns: &maxRuns}
+
+	assert.Equal(t, time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC), *schedule.RunAt(1))
+	assert.Equal(t, time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC), *schedule.RunAt(2))
+	assert.Nil(t, schedule.RunAt(3))
+
+	endAt := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	daily := model.Schedule{Frequency: model.FrequencyDaily, StartAt: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), EndAt: &endAt}
+	assert.NotNil(t, daily.RunAt(8))
+	assert.Nil(t, daily.RunAt(9))
+
+	once := model.Schedule{Frequency: model.FrequencyOnce, StartAt: endAt}
+	assert.Nil(t, once.RunAt(1))
+}
+
+func TestRunDue_SubmitsWithDeterministicID(t *testing.T) {
+	mockRepo := new(MockScheduleRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewScheduleService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 9, 0, 30, 0, time.UTC)
+	schedule := dueSchedule(uuid.New())
+	mockRepo.due = []*model.Schedule{schedule}
+	expectedID := uuid.NewSHA1(scheduleNamespace, []byte(schedule.ID.String()+"/1"))
+
+	mockAccountService.On("GetAccountByID", ctx, schedule.AccountID).Return(map[string]any{"Status": "active", "AccountType": "checking"}, nil)
+	mockProducer.On("Produce", ctx, "transactions-topic", mock.MatchedBy(func(message kafka.Message) bool {
+		var transaction model.Transaction
+		_ = json.Unmarshal(message.Value, &transaction)
+		return transaction.ID == expectedID && string(message.Key) == expectedID.String() &&
+			*transaction.ScheduleID == schedule.ID && transaction.AccountType == "checking"
+	})).Return(nil)
+	mockRepo.On("ClaimDue", expectedID, model.ExecutionSubmitted).Return()
+
+	runs, err := service.RunDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, runs)
+	mockProducer.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunDue_InactiveAccountFailsRun(t *testing.T) {
+	mockRepo := new(MockScheduleRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewScheduleService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	schedule := dueSchedule(uuid.New())
+	mockRepo.due = []*model.Schedule{schedule}
+
+	mockAccountService.On("GetAccountByID", ctx, schedule.AccountID).Return(map[string]any{"Status": "closed"}, nil)
+	mockRepo.On("ClaimDue", mock.Anything, model.ExecutionFailed).Return()
+
+	runs, err := service.RunDue(ctx, time.Date(2025, 3, 1, 9, 0, 30, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, runs)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunDue_ProduceErrorLeavesRunDue(t *testing.T) {
+	mockRepo := new(MockScheduleRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewScheduleService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	schedule := dueSchedule(uuid.New())
+	mockRepo.due = []*model.Schedule{schedule}
+
+	mockAccountService.On("GetAccountByID", ctx, schedule.AccountID).Return(map[string]any{"Status": "active"}, nil)
+	mockProducer.On("Produce", ctx, "transactions-topic", mock.Anything).Return(errors.New("kafka error"))
+
+	runs, err := service.RunDue(ctx, time.Date(2025, 3, 1, 9, 0, 30, 0, time.UTC))
+	assert.Error(t, err)
+	assert.Equal(t, 0, runs)
+	assert.Len(t, mockRepo.due, 1)
+}
+
+func TestRunDue_AccountLookupErrorLeavesRunDue(t *testing.T) {
+	mockRepo := new(MockScheduleRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewScheduleService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	schedule := dueSchedule(uuid.New())
+	mockRepo.due = []*model.Schedule{schedule}
+
+	mockAccountService.On("GetAccountByID", ctx, schedule.AccountID).Return(map[string]any(nil), errors.New("account service unavailable"))
+
+	runs, err := service.RunDue(ctx, time.Date(2025, 3, 1, 9, 0, 30, 0, time.UTC))
+	assert.Error(t, err)
+	assert.Equal(t, 0, runs)
+	// The run is not recorded as failed; it is retried on the next poll
+	assert.Len(t, mockRepo.due, 1)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRunDue_SkipsMissedRunWhenLaterRunIsDue(t *testing.T) {
+	mockRepo := new(MockScheduleRepository)
+	mockAccountService := new(MockAccountService)
+	mockProducer := new(MockKafkaProducer)
+	service := NewScheduleService(mockRepo, mockAccountService, mockProducer, "transactions-topic")
+
+	ctx := context.Background()
+	schedule := dueSchedule(uuid.New())
+	mockRepo.due = []*model.Schedule{schedule}
+	mockRepo.On("ClaimDue", mock.Anything, model.ExecutionSkipped).Return()
+
+	runs, err := service.RunDue(ctx, time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, runs)
+	mockAccountService.AssertNotCalled(t, "GetAccountByID", mock.Anything, mock.Anything)
+	mockProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}